@@ -0,0 +1,108 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Formatting of line ranges.
+
+package build
+
+import (
+	"bytes"
+)
+
+// stmtRewrites are the rewrites that move or remove top-level statements. They're not applied
+// when formatting a range of lines because they can affect statements outside of the range.
+var stmtRewrites = map[string]bool{
+	"loadTop":            true,
+	"sameOriginLoad":     true,
+	"sortLoadStatements": true,
+}
+
+// FormatRange rewrites and formats the top-level statements of the file that overlap the lines
+// from start to end (1-based, inclusive) and returns data with these statements replaced. The
+// rest of data is returned unchanged. The file must be the result of parsing data.
+func FormatRange(f *File, data []byte, start, end int) []byte {
+	defaultRewriter().rewrite(f, stmtRewrites)
+	return FormatRangeWithoutRewriting(f, data, start, end)
+}
+
+// FormatRangeWithoutRewriting is like FormatRange but doesn't rewrite the statements.
+func FormatRangeWithoutRewriting(f *File, data []byte, start, end int) []byte {
+	first, last := -1, -1
+	var from, to Position
+	for i, stmt := range f.Stmt {
+		if stmt == nil {
+			continue
+		}
+		stmtStart, stmtEnd := stmtLines(stmt)
+		if stmtStart.Line == 0 || stmtStart.Line > end || stmtEnd.Line < start {
+			// Statements without positions have been added by a refactoring and can't be placed
+			continue
+		}
+		if first == -1 {
+			first, from = i, stmtStart
+		}
+		last, to = i, stmtEnd
+	}
+	if first == -1 {
+		return data
+	}
+
+	// Replace entire lines to keep the blank lines and indentation around the statements
+	if to.Byte > len(data) {
+		to.Byte = len(data)
+	}
+	fromByte := bytes.LastIndexByte(data[:from.Byte], '\n') + 1
+	toByte := len(data)
+	if i := bytes.IndexByte(data[to.Byte:], '\n'); i >= 0 {
+		toByte = to.Byte + i + 1
+	}
+
+	pr := &printer{fileType: f.Type, profile: f.Profile}
+	pr.file(&File{Type: f.Type, Stmt: f.Stmt[first : last+1]})
+
+	var b bytes.Buffer
+	b.Write(data[:fromByte])
+	b.Write(pr.Bytes())
+	b.Write(data[toByte:])
+	return b.Bytes()
+}
+
+// stmtLines returns the positions of the first and the last characters of a top-level statement,
+// including the comments attached to it or to its nested nodes.
+func stmtLines(stmt Expr) (start, end Position) {
+	start, end = stmt.Span()
+	Walk(stmt, func(x Expr, stk []Expr) {
+		if _, xEnd := x.Span(); xEnd.Byte > end.Byte {
+			end = xEnd
+		}
+		comments := x.Comment()
+		for _, list := range [][]Comment{comments.Before, comments.Suffix, comments.After} {
+			for _, com := range list {
+				comStart, comEnd := com.Span()
+				if comStart.Line == 0 {
+					continue
+				}
+				if comStart.Byte < start.Byte {
+					start = comStart
+				}
+				if comEnd.Byte > end.Byte {
+					end = comEnd
+				}
+			}
+		}
+	})
+	return start, end
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"testing"
+)
+
+const formatRangeInput = `load("//b:b.bzl",   "b")
+load("//a:a.bzl", "a")
+cc_library(name="first",
+   srcs=["b.cc","a.cc"])   # first
+
+# Comment about the second rule
+cc_library(name="second", deps=[":first"],
+)
+def f():
+  x=1
+  # trailing comment
+cc_library(name="third")`
+
+func TestFormatRange(t *testing.T) {
+	tests := []struct {
+		start, end int
+		want       string
+	}{
+		{
+			// Only the first rule is formatted, the loads are kept unsorted
+			start: 3,
+			end:   3,
+			want: `load("//b:b.bzl",   "b")
+load("//a:a.bzl", "a")
+cc_library(
+    name = "first",
+    srcs = [
+        "a.cc",
+        "b.cc",
+    ],
+)  # first
+
+# Comment about the second rule
+cc_library(name="second", deps=[":first"],
+)
+def f():
+  x=1
+  # trailing comment
+cc_library(name="third")`,
+		},
+		{
+			// The comment before the second rule and the function body with its comment,
+			// the lines around the range are kept as is
+			start: 6,
+			end:   10,
+			want: `load("//b:b.bzl",   "b")
+load("//a:a.bzl", "a")
+cc_library(name="first",
+   srcs=["b.cc","a.cc"])   # first
+
+# Comment about the second rule
+cc_library(
+    name = "second",
+    deps = [":first"],
+)
+
+def f():
+    x = 1
+    # trailing comment
+cc_library(name="third")`,
+		},
+		{
+			// The last line without a trailing newline
+			start: 12,
+			end:   20,
+			want: `load("//b:b.bzl",   "b")
+load("//a:a.bzl", "a")
+cc_library(name="first",
+   srcs=["b.cc","a.cc"])   # first
+
+# Comment about the second rule
+cc_library(name="second", deps=[":first"],
+)
+def f():
+  x=1
+  # trailing comment
+cc_library(name = "third")
+`,
+		},
+		{
+			// The blank line doesn't overlap any statement
+			start: 5,
+			end:   5,
+			want:  formatRangeInput,
+		},
+	}
+
+	for _, tc := range tests {
+		f, err := ParseBuild("BUILD", []byte(formatRangeInput))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(FormatRange(f, []byte(formatRangeInput), tc.start, tc.end)); got != tc.want {
+			t.Errorf("FormatRange(%d, %d) =\n%s\nwant:\n%s", tc.start, tc.end, got, tc.want)
+		}
+	}
+}
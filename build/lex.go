@@ -732,6 +732,7 @@ func (in *input) order(v Expr) {
 	case *Ident:
 		// nothing
 	case *TypedIdent:
+		in.order(v.Ident)
 		in.order(v.Type)
 	case *BranchStmt:
 		// nothing
@@ -811,6 +812,9 @@ func (in *input) order(v Expr) {
 		for _, x := range v.Params {
 			in.order(x)
 		}
+		if v.Type != nil {
+			in.order(v.Type)
+		}
 		for _, x := range v.Body {
 			in.order(x)
 		}
@@ -0,0 +1,517 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Lossless parsing and printing of files.
+
+package build
+
+import (
+	"bytes"
+	"strings"
+)
+
+// fileSource is the source of a file parsed with ParseLossless.
+type fileSource struct {
+	data     []byte
+	fileType FileType // the type of the file at parse time, used to detect modifications
+	stmts    map[Expr]stmtSource
+	exprs    map[Expr]exprSource
+}
+
+// stmtSource is the source of a top-level statement of a file parsed with ParseLossless.
+type stmtSource struct {
+	index      int    // index of the statement in the parsed file
+	start, end int    // byte offsets of the statement, including its comments
+	formatted  string // the formatted statement at parse time, to detect modifications
+}
+
+// exprSource is the source of an expression of a file parsed with ParseLossless.
+type exprSource struct {
+	start, end  int    // byte offsets of the expression, without its comments
+	formatted   string // the formatted expression at parse time, to detect modifications
+	comments    string // the comments attached to the expression at parse time
+	op          string // the operator of assignments and binary expressions
+	parts       []Expr // the operands, the key and the value or the called function at parse time
+	list        []Expr // the arguments of a call or the elements of a list or a dict at parse time
+	open, close int    // byte offsets of the brackets around the list
+	endComments string // the comments before the closing bracket at parse time
+}
+
+// ParseLossless parses a file like Parse and keeps its source. When the file is printed the
+// top-level statements that haven't been modified since parsing, and the blank lines and
+// comments between them, are reproduced byte-for-byte instead of being formatted, so that
+// printing an unmodified file returns its original content.
+//
+// Modified statements are edited in place where possible: in function calls, lists, dicts,
+// assignments and binary expressions only the modified arguments, elements and operands are
+// replaced, new arguments and elements are inserted using the separators of the existing
+// ones, and deleted ones are removed together with their comments. The rest of the statement,
+// including its layout and comments, is kept as it is. Other modified expressions, such as
+// comprehensions or function definitions, are formatted as a whole, as are the statements
+// whose own comments are modified. New statements and the separators around them are
+// formatted.
+//
+// Rewrites (e.g. Format, as opposed to FormatWithoutRewriting) modify the statements they
+// normalize, which are then formatted.
+func ParseLossless(filename string, data []byte) (*File, error) {
+	f, err := Parse(filename, data)
+	if err != nil {
+		return f, err
+	}
+	f.source = newFileSource(f, data)
+	return f, nil
+}
+
+func newFileSource(f *File, data []byte) *fileSource {
+	s := &fileSource{data: data, fileType: f.Type, stmts: make(map[Expr]stmtSource), exprs: make(map[Expr]exprSource)}
+	for i, stmt := range f.Stmt {
+		s.record(stmt)
+		start, end := stmtLines(stmt)
+		s.stmts[stmt] = stmtSource{
+			index:     i,
+			start:     start.Byte,
+			end:       minInt(end.Byte, len(data)),
+			formatted: formatStmt(f.Type, nil, stmt),
+		}
+	}
+	return s
+}
+
+// formatStmt formats a top-level statement without the trailing newline.
+func formatStmt(fileType FileType, profile *FormattingProfile, stmt Expr) string {
+	pr := &printer{fileType: fileType, profile: profile}
+	pr.file(&File{Type: fileType, Stmt: []Expr{stmt}})
+	return string(bytes.TrimSuffix(pr.Bytes(), []byte("\n")))
+}
+
+// unmodified returns the source of a statement if it hasn't been modified since parsing.
+func (s *fileSource) unmodified(stmt Expr) (stmtSource, bool) {
+	src, ok := s.stmts[stmt]
+	if !ok || formatStmt(s.fileType, nil, stmt) != src.formatted {
+		return src, false
+	}
+	return src, true
+}
+
+// format prints the file reusing the source of the unmodified statements.
+func (s *fileSource) format(f *File) []byte {
+	var stmts []Expr
+	for _, stmt := range f.Stmt {
+		if stmt != nil {
+			stmts = append(stmts, stmt)
+		}
+	}
+	if len(stmts) == 0 && len(s.stmts) == 0 {
+		return s.data
+	}
+
+	var b bytes.Buffer
+	pr := &printer{fileType: f.Type, profile: f.Profile}
+	var prev stmtSource
+	prevOriginal := false
+	for i, stmt := range stmts {
+		src, original := s.stmts[stmt]
+
+		// The separator before the statement
+		switch {
+		case i == 0 && original && src.index == 0:
+			b.Write(s.data[:src.start])
+		case i == 0:
+			for _, com := range f.Before {
+				b.WriteString(strings.TrimSpace(com.Token))
+				b.WriteString("\n")
+			}
+		case original && prevOriginal && src.index == prev.index+1:
+			b.Write(s.data[prev.end:src.start])
+		case pr.compactStmt(stmts[i-1], stmt):
+			b.WriteString("\n")
+		default:
+			b.WriteString("\n\n")
+		}
+
+		if _, ok := s.unmodified(stmt); ok {
+			b.Write(s.data[src.start:src.end])
+		} else if text, ok := s.editStmt(stmt, f.Profile); ok {
+			b.WriteString(text)
+		} else {
+			b.WriteString(formatStmt(f.Type, f.Profile, stmt))
+		}
+		prev, prevOriginal = src, original
+	}
+
+	// The rest of the file after the last statement
+	if prevOriginal && prev.index == len(s.stmts)-1 {
+		b.Write(s.data[prev.end:])
+	} else {
+		if len(stmts) > 0 {
+			b.WriteString("\n")
+		}
+		for _, com := range f.After {
+			b.WriteString(strings.TrimSpace(com.Token))
+			b.WriteString("\n")
+		}
+	}
+	return b.Bytes()
+}
+
+// editStmt returns the source of a modified top-level statement edited in place.
+func (s *fileSource) editStmt(stmt Expr, profile *FormattingProfile) (string, bool) {
+	src, original := s.stmts[stmt]
+	x, ok := s.exprs[stmt]
+	if !original || !ok || commentsString(stmt.Comment()) != x.comments {
+		return "", false
+	}
+	text, ok := s.splice(stmt, profile)
+	if !ok {
+		return "", false
+	}
+	return string(s.data[src.start:x.start]) + text + string(s.data[x.end:src.end]), true
+}
+
+// editable describes the subexpressions of an expression whose source can be edited in place.
+type editable struct {
+	op    string    // the operator of assignments and binary expressions
+	parts []Expr    // the operands, the key and the value or the called function
+	precs []int     // the precedence of the context each part is printed in
+	list  []Expr    // the arguments of a call or the elements of a list or a dict
+	open  *Position // the opening bracket of the list
+	end   *End      // the closing bracket of the list
+}
+
+func editableExpr(x Expr) (editable, bool) {
+	switch x := x.(type) {
+	case *AssignExpr:
+		return editable{op: x.Op, parts: []Expr{x.LHS, x.RHS}, precs: []int{precAssign, precAssign + 1}}, true
+	case *BinaryExpr:
+		prec := opPrec[x.Op]
+		return editable{op: x.Op, parts: []Expr{x.X, x.Y}, precs: []int{prec, prec + 1}}, true
+	case *KeyValueExpr:
+		return editable{parts: []Expr{x.Key, x.Value}, precs: []int{precLow, precLow}}, true
+	case *CallExpr:
+		return editable{parts: []Expr{x.X}, precs: []int{precSuffix}, list: nonNil(x.List), open: &x.ListStart, end: &x.End}, true
+	case *ListExpr:
+		return editable{list: nonNil(x.List), open: &x.Start, end: &x.End}, true
+	case *DictExpr:
+		var list []Expr
+		for _, kv := range x.List {
+			if kv != nil {
+				list = append(list, kv)
+			}
+		}
+		return editable{list: list, open: &x.Start, end: &x.End}, true
+	}
+	return editable{}, false
+}
+
+// nonNil returns a copy of the list without the nil expressions.
+func nonNil(list []Expr) []Expr {
+	var result []Expr
+	for _, x := range list {
+		if x != nil {
+			result = append(result, x)
+		}
+	}
+	return result
+}
+
+// record saves the source of an expression and of the subexpressions that can be edited in place.
+func (s *fileSource) record(x Expr) {
+	if x == nil {
+		return
+	}
+	start, end := x.Span()
+	src := exprSource{
+		start:     start.Byte,
+		end:       minInt(end.Byte, len(s.data)),
+		formatted: formatExpr(s.fileType, nil, x, precLow, 0),
+		comments:  commentsString(x.Comment()),
+	}
+	if e, ok := editableExpr(x); ok {
+		src.op, src.parts, src.list = e.op, e.parts, e.list
+		if e.end != nil {
+			src.open, src.close = e.open.Byte, e.end.Pos.Byte
+			src.endComments = commentsString(&e.end.Comments)
+		}
+		for _, y := range append(e.parts, e.list...) {
+			s.record(y)
+		}
+	}
+	s.exprs[x] = src
+}
+
+// formatExpr formats an expression printed in a context of the given precedence. The lines
+// after the first one are indented by margin spaces.
+func formatExpr(fileType FileType, profile *FormattingProfile, x Expr, prec, margin int) string {
+	pr := &printer{fileType: fileType, profile: profile, margin: margin}
+	pr.expr(x, prec)
+	return pr.String()
+}
+
+// commentsString returns a string that identifies the comments attached to an expression.
+func commentsString(c *Comments) string {
+	var b strings.Builder
+	for _, comments := range [][]Comment{c.Before, c.Suffix, c.After} {
+		for _, com := range comments {
+			b.WriteString(com.Token)
+			b.WriteString("\n")
+		}
+		b.WriteString("\x00")
+	}
+	return b.String()
+}
+
+// source returns the new source of an expression of the original file, or false if it
+// can't be printed without its surroundings because its comments have been modified.
+func (s *fileSource) source(x Expr, prec int, profile *FormattingProfile) (string, bool) {
+	src, ok := s.exprs[x]
+	if !ok || commentsString(x.Comment()) != src.comments {
+		return "", false
+	}
+	if formatExpr(s.fileType, nil, x, precLow, 0) == src.formatted {
+		return string(s.data[src.start:src.end]), true
+	}
+	if text, ok := s.splice(x, profile); ok {
+		return text, true
+	}
+	return s.reformat(x, prec, profile, len(lineIndent(s.data, src.start))), true
+}
+
+// newSource formats an expression that isn't at its original place, or false if it has
+// comments, which can't be printed without its surroundings.
+func (s *fileSource) newSource(x Expr, prec int, profile *FormattingProfile, margin int) (string, bool) {
+	c := x.Comment()
+	if len(c.Before) > 0 || len(c.Suffix) > 0 || len(c.After) > 0 {
+		return "", false
+	}
+	return s.reformat(x, prec, profile, margin), true
+}
+
+// reformat formats an expression without its own comments, which are kept in the source
+// around the expression.
+func (s *fileSource) reformat(x Expr, prec int, profile *FormattingProfile, margin int) string {
+	c := x.Comment()
+	com := *c
+	*c = Comments{}
+	text := formatExpr(s.fileType, profile, x, prec, margin)
+	*c = com
+	return text
+}
+
+// splice returns the new source of a modified expression by editing its original source:
+// the modified parts and elements are replaced and the new elements are inserted without
+// changing the rest. It returns false if the expression can't be edited in place.
+func (s *fileSource) splice(x Expr, profile *FormattingProfile) (string, bool) {
+	src, ok := s.exprs[x]
+	e, editable := editableExpr(x)
+	if !ok || !editable || e.op != src.op || len(e.parts) != len(src.parts) {
+		return "", false
+	}
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	for i, part := range e.parts {
+		old := src.parts[i]
+		oldSrc, ok := s.exprs[old]
+		if part == nil || !ok {
+			return "", false
+		}
+		var text string
+		if part == old {
+			text, ok = s.source(part, e.precs[i], profile)
+		} else {
+			text, ok = s.newSource(part, e.precs[i], profile, len(lineIndent(s.data, oldSrc.start)))
+		}
+		if !ok {
+			return "", false
+		}
+		edits = append(edits, edit{oldSrc.start, oldSrc.end, text})
+	}
+	if e.end != nil {
+		if commentsString(&e.end.Comments) != src.endComments {
+			return "", false
+		}
+		text, ok := s.spliceList(src, e.list, profile)
+		if !ok {
+			return "", false
+		}
+		edits = append(edits, edit{src.open + 1, src.close, text})
+	}
+
+	var b strings.Builder
+	pos := src.start
+	for _, edit := range edits {
+		if edit.start < pos || edit.end > src.end {
+			return "", false
+		}
+		b.Write(s.data[pos:edit.start])
+		b.WriteString(edit.text)
+		pos = edit.end
+	}
+	b.Write(s.data[pos:src.end])
+	return b.String(), true
+}
+
+// spliceList returns the new source between the brackets of a list of arguments or
+// elements. Each original element keeps the separator before it (the line break,
+// indentation and comments) and the rest of its line after it (the comma and the suffix
+// comments), new elements get the separator of the last original element.
+func (s *fileSource) spliceList(src exprSource, list []Expr, profile *FormattingProfile) (string, bool) {
+	old := src.list
+	if len(old) == 0 {
+		return "", false
+	}
+	index := make(map[Expr]int)
+	for i, x := range old {
+		index[x] = i
+	}
+	last := -1
+	for _, x := range list {
+		if i, ok := index[x]; ok {
+			if i <= last {
+				return "", false // reordered
+			}
+			last = i
+		}
+	}
+	if last < 0 {
+		return "", false
+	}
+
+	leads := make([]string, len(old))
+	trails := make([]string, len(old))
+	pos := src.open + 1
+	for i, x := range old {
+		xs := s.exprs[x]
+		if xs.start < pos {
+			return "", false
+		}
+		if i == 0 {
+			leads[i] = string(s.data[pos:xs.start])
+		} else {
+			trails[i-1], leads[i] = splitSeparator(string(s.data[pos:xs.start]))
+		}
+		pos = xs.end
+	}
+	if src.close < pos {
+		return "", false
+	}
+	var foot string
+	trails[len(old)-1], foot = splitSeparator(string(s.data[pos:src.close]))
+
+	head := leads[0]
+	firstLead := ""
+	if i := strings.LastIndexByte(head, '\n'); i >= 0 {
+		firstLead = "\n" + head[i+1:]
+	}
+	newLead, margin := " ", len(lineIndent(s.data, src.open))
+	sample := leads[len(old)-1]
+	if len(old) == 1 {
+		sample = head
+	}
+	if i := strings.LastIndexByte(sample, '\n'); i >= 0 {
+		newLead, margin = "\n"+sample[i+1:], len(sample[i+1:])
+	}
+	lastComma := hasComma(trails[len(old)-1])
+
+	var b strings.Builder
+	for pos, x := range list {
+		var lead, text, trail string
+		var ok bool
+		if i, kept := index[x]; kept {
+			if text, ok = s.source(x, precLow, profile); !ok {
+				return "", false
+			}
+			lead, trail = leads[i], trails[i]
+			switch {
+			case pos == 0 && i > 0 && strings.Contains(lead, "\n"):
+				lead = trimBlankLines(lead)
+			case pos == 0 && i > 0:
+				lead = firstLead
+			case pos > 0 && i == 0 && !strings.Contains(lead, "\n"):
+				lead = " "
+			}
+		} else {
+			if text, ok = s.newSource(x, precLow, profile, margin); !ok {
+				return "", false
+			}
+			lead = newLead
+			if pos == 0 {
+				lead = firstLead
+			}
+		}
+		b.WriteString(lead)
+		b.WriteString(text)
+		b.WriteString(withComma(trail, pos < len(list)-1 || lastComma))
+	}
+	b.WriteString(foot)
+	return b.String(), true
+}
+
+// splitSeparator splits the source between two elements of a list into the rest of the line
+// of the first element and the separator before the second one.
+func splitSeparator(sep string) (trail, lead string) {
+	if i := strings.IndexByte(sep, '\n'); i >= 0 {
+		return sep[:i], sep[i:]
+	}
+	trail = strings.TrimRight(sep, " \t")
+	return trail, sep[len(trail):]
+}
+
+// trimBlankLines removes the blank lines at the beginning of a separator that starts with a
+// line break.
+func trimBlankLines(lead string) string {
+	lines := strings.Split(lead, "\n")
+	k := 1
+	for k < len(lines)-1 && strings.TrimSpace(lines[k]) == "" {
+		k++
+	}
+	return "\n" + strings.Join(lines[k:], "\n")
+}
+
+func hasComma(trail string) bool {
+	return strings.HasPrefix(strings.TrimLeft(trail, " \t"), ",")
+}
+
+// withComma adds or removes the comma at the beginning of the rest of the line of an element.
+func withComma(trail string, comma bool) string {
+	switch rest := strings.TrimLeft(trail, " \t"); {
+	case comma && !hasComma(trail):
+		return "," + trail
+	case !comma && hasComma(trail):
+		return rest[1:]
+	}
+	return trail
+}
+
+// lineIndent returns the indentation of the line that contains the given byte offset.
+func lineIndent(data []byte, offset int) string {
+	start := bytes.LastIndexByte(data[:offset], '\n') + 1
+	end := start
+	for end < offset && (data[end] == ' ' || data[end] == '\t') {
+		end++
+	}
+	return string(data[start:end])
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
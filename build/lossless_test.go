@@ -0,0 +1,286 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLosslessRoundTrip(t *testing.T) {
+	ins, chdir := findTests(t, ".in")
+	defer chdir()
+
+	for _, in := range ins {
+		data, err := os.ReadFile(in)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		f, err := ParseLossless(in, data)
+		if err != nil {
+			// Some test files are expected to fail parsing
+			continue
+		}
+		if got := FormatWithoutRewriting(f); string(got) != string(data) {
+			t.Errorf("%s: lossless round trip mismatch:\n%s\nwant:\n%s", in, got, data)
+		}
+	}
+}
+
+const losslessInput = `# Header comment
+
+load(  "//foo:bar.bzl",'baz')
+
+
+cc_library(name='lib',
+  srcs=['b.cc', 'a.cc'],   # unsorted
+)
+cc_binary(name = "bin", deps = [':lib'])  # binary
+
+genrule(name="gen",
+        cmd="echo")
+# trailing comment`
+
+func TestLosslessEdits(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		edit func(f *File)
+		want string
+	}{
+		{
+			name: "unmodified",
+			edit: func(f *File) {},
+			want: losslessInput,
+		},
+		{
+			name: "modified statement",
+			edit: func(f *File) {
+				f.RuleNamed("bin").SetAttr("testonly", &Ident{Name: "True"})
+			},
+			want: `# Header comment
+
+load(  "//foo:bar.bzl",'baz')
+
+
+cc_library(name='lib',
+  srcs=['b.cc', 'a.cc'],   # unsorted
+)
+cc_binary(name = "bin", deps = [':lib'], testonly = True)  # binary
+
+genrule(name="gen",
+        cmd="echo")
+# trailing comment`,
+		},
+		{
+			name: "added list element and argument",
+			edit: func(f *File) {
+				lib := f.RuleNamed("lib")
+				srcs := lib.Attr("srcs").(*ListExpr)
+				srcs.List = append(srcs.List, &StringExpr{Value: "c.cc"})
+				lib.SetAttr("deps", &ListExpr{List: []Expr{&StringExpr{Value: ":x"}, &StringExpr{Value: ":y"}}})
+			},
+			want: `# Header comment
+
+load(  "//foo:bar.bzl",'baz')
+
+
+cc_library(name='lib',
+  srcs=['b.cc', 'a.cc', "c.cc"],   # unsorted
+  deps = [
+      ":x",
+      ":y",
+  ],
+)
+cc_binary(name = "bin", deps = [':lib'])  # binary
+
+genrule(name="gen",
+        cmd="echo")
+# trailing comment`,
+		},
+		{
+			name: "deleted and replaced arguments",
+			edit: func(f *File) {
+				f.RuleNamed("lib").DelAttr("name")
+				f.RuleNamed("gen").DelAttr("cmd")
+				f.RuleNamed("bin").SetAttr("deps", &ListExpr{})
+			},
+			want: `# Header comment
+
+load(  "//foo:bar.bzl",'baz')
+
+
+cc_library(
+  srcs=['b.cc', 'a.cc'],   # unsorted
+)
+cc_binary(name = "bin", deps = [])  # binary
+
+genrule(name="gen")
+# trailing comment`,
+		},
+		{
+			name: "modified comment",
+			edit: func(f *File) {
+				f.RuleNamed("gen").AttrDefn("cmd").Comments.Suffix = []Comment{{Token: "# command"}}
+			},
+			want: `# Header comment
+
+load(  "//foo:bar.bzl",'baz')
+
+
+cc_library(name='lib',
+  srcs=['b.cc', 'a.cc'],   # unsorted
+)
+cc_binary(name = "bin", deps = [':lib'])  # binary
+
+genrule(
+    name = "gen",
+    cmd = "echo",  # command
+)
+# trailing comment`,
+		},
+		{
+			name: "deleted and inserted statements",
+			edit: func(f *File) {
+				call := &CallExpr{X: &Ident{Name: "exports_files"}, List: []Expr{&ListExpr{List: []Expr{&StringExpr{Value: "a.txt"}}}}}
+				f.Stmt = []Expr{f.Stmt[0], f.Stmt[1], call, f.Stmt[3], f.Stmt[4]}
+			},
+			want: `# Header comment
+
+load(  "//foo:bar.bzl",'baz')
+
+exports_files(["a.txt"])
+
+cc_binary(name = "bin", deps = [':lib'])  # binary
+
+genrule(name="gen",
+        cmd="echo")
+# trailing comment`,
+		},
+		{
+			// The trailing comment is attached to the deleted statement
+			name: "deleted last statement",
+			edit: func(f *File) {
+				f.Stmt = f.Stmt[:len(f.Stmt)-1]
+			},
+			want: `# Header comment
+
+load(  "//foo:bar.bzl",'baz')
+
+
+cc_library(name='lib',
+  srcs=['b.cc', 'a.cc'],   # unsorted
+)
+cc_binary(name = "bin", deps = [':lib'])  # binary
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ParseLossless("BUILD", []byte(losslessInput))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.edit(f)
+			if got := string(FormatWithoutRewriting(f)); got != tc.want {
+				t.Errorf("FormatWithoutRewriting() =\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLosslessListEdits(t *testing.T) {
+	input := `cc_library(
+    name = "lib",
+    deps = [
+        # first
+        ":a",  # a
+
+        ":b",
+        ":c",  # c
+    ] + select({"//conditions:default": []}),
+)
+`
+	for _, tc := range []struct {
+		name string
+		edit func(deps *ListExpr, dict *DictExpr)
+		want string
+	}{
+		{
+			name: "deleted elements",
+			edit: func(deps *ListExpr, dict *DictExpr) {
+				deps.List = deps.List[1:2]
+			},
+			want: `cc_library(
+    name = "lib",
+    deps = [
+        ":b",
+    ] + select({"//conditions:default": []}),
+)
+`,
+		},
+		{
+			name: "modified and inserted elements",
+			edit: func(deps *ListExpr, dict *DictExpr) {
+				deps.List[1].(*StringExpr).Value = ":bb"
+				deps.List = append([]Expr{&StringExpr{Value: ":0"}}, deps.List...)
+				deps.List = append(deps.List, &StringExpr{Value: ":d"})
+				dict.List[0].Value = &ListExpr{List: []Expr{&StringExpr{Value: ":e"}}}
+			},
+			want: `cc_library(
+    name = "lib",
+    deps = [
+        ":0",
+        # first
+        ":a",  # a
+
+        ":bb",
+        ":c",  # c
+        ":d",
+    ] + select({"//conditions:default": [":e"]}),
+)
+`,
+		},
+		{
+			name: "reordered elements",
+			edit: func(deps *ListExpr, dict *DictExpr) {
+				deps.List[0], deps.List[2] = deps.List[2], deps.List[0]
+			},
+			want: `cc_library(
+    name = "lib",
+    deps = [
+        ":c",  # c
+        ":b",
+        # first
+        ":a",  # a
+    ] + select({"//conditions:default": []}),
+)
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ParseLossless("BUILD", []byte(input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			deps := f.RuleNamed("lib").Attr("deps").(*BinaryExpr)
+			tc.edit(deps.X.(*ListExpr), deps.Y.(*CallExpr).List[0].(*DictExpr))
+			if got := string(FormatWithoutRewriting(f)); got != tc.want {
+				t.Errorf("FormatWithoutRewriting() =\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
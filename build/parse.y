@@ -479,6 +479,7 @@ small_stmt:
 		}
 	}
 |	expr '=' expr      { $$ = binary($1, $2, $<tok>2, $3) }
+|	ident ':' test           { $$ = typed($1, $3) }
 |	ident ':' test '=' expr  { $$ = binary(typed($1, $3), $4, $<tok>4, $5) }
 |	expr _AUGM expr    { $$ = binary($1, $2, $<tok>2, $3) }
 |	_PASS
@@ -708,6 +709,7 @@ load_argument:
 			from: Ident{
 				Name: $1.Value,
 				NamePos: start,
+				token: $1.Token,
 			},
 			to: Ident{
 				Name: $1.Value,
@@ -725,6 +727,7 @@ load_argument:
 			from: Ident{
 				Name: $3.Value,
 				NamePos: start,
+				token: $3.Token,
 			},
 			to: *$1.(*Ident),
 		}
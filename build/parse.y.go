@@ -144,7 +144,7 @@ const yyEofCode = 1
 const yyErrCode = 2
 const yyInitialStackSize = 16
 
-//line build/parse.y:1066
+//line build/parse.y:1069
 
 // Go helper code.
 
@@ -413,11 +413,11 @@ var yyExca = [...]int16{
 	1, -1,
 	-2, 0,
 	-1, 81,
-	6, 56,
-	-2, 129,
+	6, 57,
+	-2, 130,
 	-1, 172,
-	20, 126,
-	-2, 127,
+	20, 127,
+	-2, 128,
 }
 
 const yyPrivate = 57344
@@ -582,36 +582,38 @@ var yyR1 = [...]int8{
 	0, 45, 39, 39, 46, 46, 40, 40, 40, 26,
 	26, 26, 26, 27, 27, 43, 44, 44, 28, 28,
 	28, 30, 30, 29, 29, 31, 31, 32, 34, 34,
-	33, 33, 33, 33, 33, 33, 33, 33, 33, 47,
-	47, 16, 16, 16, 16, 16, 16, 16, 16, 16,
-	16, 16, 16, 16, 16, 16, 6, 6, 5, 5,
-	4, 4, 4, 4, 42, 42, 41, 41, 9, 9,
-	12, 12, 8, 8, 11, 11, 7, 7, 7, 7,
-	7, 10, 10, 10, 10, 10, 17, 17, 18, 18,
+	33, 33, 33, 33, 33, 33, 33, 33, 33, 33,
+	47, 47, 16, 16, 16, 16, 16, 16, 16, 16,
+	16, 16, 16, 16, 16, 16, 16, 6, 6, 5,
+	5, 4, 4, 4, 4, 42, 42, 41, 41, 9,
+	9, 12, 12, 8, 8, 11, 11, 7, 7, 7,
+	7, 7, 10, 10, 10, 10, 10, 17, 17, 18,
+	18, 13, 13, 13, 13, 13, 13, 13, 13, 13,
 	13, 13, 13, 13, 13, 13, 13, 13, 13, 13,
 	13, 13, 13, 13, 13, 13, 13, 13, 13, 13,
-	13, 13, 13, 13, 13, 13, 13, 13, 13, 19,
-	19, 14, 14, 15, 15, 1, 1, 2, 2, 3,
-	3, 35, 37, 37, 36, 36, 36, 20, 20, 38,
-	24, 25, 25, 25, 25, 21, 22, 22, 23, 23,
+	19, 19, 14, 14, 15, 15, 1, 1, 2, 2,
+	3, 3, 35, 37, 37, 36, 36, 36, 20, 20,
+	38, 24, 25, 25, 25, 25, 21, 22, 22, 23,
+	23,
 }
 
 var yyR2 = [...]int8{
 	0, 2, 5, 2, 0, 2, 0, 3, 2, 0,
 	2, 2, 3, 1, 1, 5, 1, 3, 3, 6,
 	1, 4, 5, 1, 4, 2, 1, 4, 0, 3,
-	1, 2, 1, 3, 5, 3, 1, 1, 1, 0,
-	1, 1, 1, 1, 3, 8, 4, 4, 6, 8,
-	3, 4, 4, 3, 4, 3, 0, 2, 2, 3,
-	1, 3, 2, 2, 1, 3, 1, 3, 0, 2,
-	0, 2, 1, 3, 1, 3, 1, 3, 2, 1,
-	2, 1, 3, 5, 4, 4, 1, 3, 0, 1,
-	1, 4, 2, 2, 2, 2, 3, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 3, 4,
-	3, 3, 3, 3, 3, 3, 3, 3, 5, 1,
-	3, 0, 1, 0, 2, 0, 1, 1, 2, 0,
-	1, 3, 1, 3, 0, 1, 2, 1, 3, 1,
-	1, 3, 2, 2, 1, 4, 1, 3, 1, 2,
+	1, 2, 1, 3, 3, 5, 3, 1, 1, 1,
+	0, 1, 1, 1, 1, 3, 8, 4, 4, 6,
+	8, 3, 4, 4, 3, 4, 3, 0, 2, 2,
+	3, 1, 3, 2, 2, 1, 3, 1, 3, 0,
+	2, 0, 2, 1, 3, 1, 3, 1, 3, 2,
+	1, 2, 1, 3, 5, 4, 4, 1, 3, 0,
+	1, 1, 4, 2, 2, 2, 2, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
+	4, 3, 3, 3, 3, 3, 3, 3, 3, 5,
+	1, 3, 0, 1, 0, 2, 0, 1, 1, 2,
+	0, 1, 3, 1, 3, 0, 1, 2, 1, 3,
+	1, 1, 3, 2, 2, 1, 4, 1, 3, 1,
+	2,
 }
 
 var yyChk = [...]int16{
@@ -647,33 +649,33 @@ var yyChk = [...]int16{
 
 var yyDef = [...]int16{
 	9, -2, 0, 1, 10, 11, 0, 13, 14, 28,
-	0, 0, 20, 30, 32, 41, 36, 37, 38, 16,
-	23, 86, 140, 0, 0, 90, 68, 0, 0, 0,
-	0, 42, 43, 0, 123, 134, 123, 144, 0, 139,
-	12, 39, 0, 0, 137, 41, 0, 0, 0, 31,
+	0, 0, 20, 30, 32, 42, 37, 38, 39, 16,
+	23, 87, 141, 0, 0, 91, 69, 0, 0, 0,
+	0, 43, 44, 0, 124, 135, 124, 145, 0, 140,
+	12, 40, 0, 0, 138, 42, 0, 0, 0, 31,
 	0, 0, 0, 0, 26, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, -2, 88, 0, 125, 72, 76, 79, 0, 92,
-	93, 94, 95, 129, 0, 119, 129, 132, 0, 125,
-	119, 135, 0, 119, 142, 143, 0, 40, 18, 6,
-	4, 0, 0, 33, 35, 87, 0, 17, 0, 0,
-	25, 96, 97, 98, 99, 100, 101, 102, 103, 104,
-	105, 106, 107, 108, 0, 110, 111, 112, 113, 114,
-	115, 116, 117, 0, 70, 0, 44, 0, 129, 0,
-	130, 127, 89, 0, 0, 69, 126, 0, 78, 80,
-	0, 50, 0, 148, 146, 0, 130, 124, 0, 53,
-	0, 0, -2, 0, 136, 55, 141, 27, 29, 0,
-	3, 0, 138, 0, 0, 24, 109, 0, 0, 125,
-	74, 81, 76, 79, 0, 21, 46, 57, 130, 58,
-	60, 41, 0, 0, 128, 47, 121, 91, 73, 77,
-	0, 51, 149, 0, 0, 120, 52, 54, 131, 133,
-	0, 9, 0, 8, 5, 0, 34, 22, 118, 15,
-	71, 126, 0, 78, 80, 59, 0, 62, 63, 0,
-	122, 0, 147, 0, 0, 7, 19, 75, 82, 0,
-	0, 61, 48, 121, 129, 64, 66, 0, 145, 2,
-	0, 84, 85, 0, 0, 127, 0, 83, 49, 45,
-	65, 67,
+	0, -2, 89, 0, 126, 73, 77, 80, 0, 93,
+	94, 95, 96, 130, 0, 120, 130, 133, 0, 126,
+	120, 136, 0, 120, 143, 144, 0, 41, 18, 6,
+	4, 0, 0, 33, 36, 88, 34, 17, 0, 0,
+	25, 97, 98, 99, 100, 101, 102, 103, 104, 105,
+	106, 107, 108, 109, 0, 111, 112, 113, 114, 115,
+	116, 117, 118, 0, 71, 0, 45, 0, 130, 0,
+	131, 128, 90, 0, 0, 70, 127, 0, 79, 81,
+	0, 51, 0, 149, 147, 0, 131, 125, 0, 54,
+	0, 0, -2, 0, 137, 56, 142, 27, 29, 0,
+	3, 0, 139, 0, 0, 24, 110, 0, 0, 126,
+	75, 82, 77, 80, 0, 21, 47, 58, 131, 59,
+	61, 42, 0, 0, 129, 48, 122, 92, 74, 78,
+	0, 52, 150, 0, 0, 121, 53, 55, 132, 134,
+	0, 9, 0, 8, 5, 0, 35, 22, 119, 15,
+	72, 127, 0, 79, 81, 60, 0, 63, 64, 0,
+	123, 0, 148, 0, 0, 7, 19, 76, 83, 0,
+	0, 62, 49, 122, 130, 65, 67, 0, 146, 2,
+	0, 85, 86, 0, 0, 128, 0, 84, 50, 46,
+	66, 68,
 }
 
 var yyTok1 = [...]int8{
@@ -1328,53 +1330,59 @@ yydefault:
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 34:
-		yyDollar = yyS[yypt-5 : yypt+1]
+		yyDollar = yyS[yypt-3 : yypt+1]
 //line build/parse.y:482
 		{
-			yyVAL.expr = binary(typed(yyDollar[1].expr, yyDollar[3].expr), yyDollar[4].pos, yyDollar[4].tok, yyDollar[5].expr)
+			yyVAL.expr = typed(yyDollar[1].expr, yyDollar[3].expr)
 		}
 	case 35:
-		yyDollar = yyS[yypt-3 : yypt+1]
+		yyDollar = yyS[yypt-5 : yypt+1]
 //line build/parse.y:483
 		{
-			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
+			yyVAL.expr = binary(typed(yyDollar[1].expr, yyDollar[3].expr), yyDollar[4].pos, yyDollar[4].tok, yyDollar[5].expr)
 		}
 	case 36:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line build/parse.y:484
+		{
+			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
+		}
+	case 37:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:485
+//line build/parse.y:486
 		{
 			yyVAL.expr = &BranchStmt{
 				Token:    yyDollar[1].tok,
 				TokenPos: yyDollar[1].pos,
 			}
 		}
-	case 37:
+	case 38:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:492
+//line build/parse.y:493
 		{
 			yyVAL.expr = &BranchStmt{
 				Token:    yyDollar[1].tok,
 				TokenPos: yyDollar[1].pos,
 			}
 		}
-	case 38:
+	case 39:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:499
+//line build/parse.y:500
 		{
 			yyVAL.expr = &BranchStmt{
 				Token:    yyDollar[1].tok,
 				TokenPos: yyDollar[1].pos,
 			}
 		}
-	case 43:
+	case 44:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:513
+//line build/parse.y:514
 		{
 			yyVAL.expr = yyDollar[1].string
 		}
-	case 44:
+	case 45:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:517
+//line build/parse.y:518
 		{
 			yyVAL.expr = &DotExpr{
 				X:       yyDollar[1].expr,
@@ -1383,9 +1391,9 @@ yydefault:
 				Name:    yyDollar[3].tok,
 			}
 		}
-	case 45:
+	case 46:
 		yyDollar = yyS[yypt-8 : yypt+1]
-//line build/parse.y:526
+//line build/parse.y:527
 		{
 			load := &LoadStmt{
 				Load:         yyDollar[1].pos,
@@ -1399,9 +1407,9 @@ yydefault:
 			}
 			yyVAL.expr = load
 		}
-	case 46:
+	case 47:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line build/parse.y:540
+//line build/parse.y:541
 		{
 			yyVAL.expr = &CallExpr{
 				X:              yyDollar[1].expr,
@@ -1412,9 +1420,9 @@ yydefault:
 				ForceMultiLine: forceMultiLine(yyDollar[2].pos, yyDollar[3].exprs, yyDollar[4].pos),
 			}
 		}
-	case 47:
+	case 48:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line build/parse.y:551
+//line build/parse.y:552
 		{
 			yyVAL.expr = &IndexExpr{
 				X:          yyDollar[1].expr,
@@ -1423,9 +1431,9 @@ yydefault:
 				End:        yyDollar[4].pos,
 			}
 		}
-	case 48:
+	case 49:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line build/parse.y:560
+//line build/parse.y:561
 		{
 			yyVAL.expr = &SliceExpr{
 				X:          yyDollar[1].expr,
@@ -1436,9 +1444,9 @@ yydefault:
 				End:        yyDollar[6].pos,
 			}
 		}
-	case 49:
+	case 50:
 		yyDollar = yyS[yypt-8 : yypt+1]
-//line build/parse.y:571
+//line build/parse.y:572
 		{
 			yyVAL.expr = &SliceExpr{
 				X:           yyDollar[1].expr,
@@ -1451,9 +1459,9 @@ yydefault:
 				End:         yyDollar[8].pos,
 			}
 		}
-	case 50:
+	case 51:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:584
+//line build/parse.y:585
 		{
 			yyVAL.expr = &ListExpr{
 				Start:          yyDollar[1].pos,
@@ -1462,9 +1470,9 @@ yydefault:
 				ForceMultiLine: forceMultiLine(yyDollar[1].pos, yyDollar[2].exprs, yyDollar[3].pos),
 			}
 		}
-	case 51:
+	case 52:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line build/parse.y:593
+//line build/parse.y:594
 		{
 			yyVAL.expr = &Comprehension{
 				Curly:          false,
@@ -1475,9 +1483,9 @@ yydefault:
 				ForceMultiLine: forceMultiLineComprehension(yyDollar[1].pos, yyDollar[2].expr, yyDollar[3].exprs, yyDollar[4].pos),
 			}
 		}
-	case 52:
+	case 53:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line build/parse.y:604
+//line build/parse.y:605
 		{
 			yyVAL.expr = &Comprehension{
 				Curly:          true,
@@ -1488,9 +1496,9 @@ yydefault:
 				ForceMultiLine: forceMultiLineComprehension(yyDollar[1].pos, yyDollar[2].kv, yyDollar[3].exprs, yyDollar[4].pos),
 			}
 		}
-	case 53:
+	case 54:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:615
+//line build/parse.y:616
 		{
 			exprValues := make([]Expr, 0, len(yyDollar[2].kvs))
 			for _, kv := range yyDollar[2].kvs {
@@ -1503,9 +1511,9 @@ yydefault:
 				ForceMultiLine: forceMultiLine(yyDollar[1].pos, exprValues, yyDollar[3].pos),
 			}
 		}
-	case 54:
+	case 55:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line build/parse.y:628
+//line build/parse.y:629
 		{
 			yyVAL.expr = &SetExpr{
 				Start:          yyDollar[1].pos,
@@ -1514,9 +1522,9 @@ yydefault:
 				ForceMultiLine: forceMultiLine(yyDollar[1].pos, yyDollar[2].exprs, yyDollar[4].pos),
 			}
 		}
-	case 55:
+	case 56:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:637
+//line build/parse.y:638
 		{
 			if len(yyDollar[2].exprs) == 1 && yyDollar[2].comma.Line == 0 {
 				// Just a parenthesized expression, not a tuple.
@@ -1536,67 +1544,67 @@ yydefault:
 				}
 			}
 		}
-	case 56:
+	case 57:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line build/parse.y:658
+//line build/parse.y:659
 		{
 			yyVAL.exprs = nil
 		}
-	case 57:
+	case 58:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:662
+//line build/parse.y:663
 		{
 			yyVAL.exprs = yyDollar[1].exprs
 		}
-	case 58:
+	case 59:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:668
+//line build/parse.y:669
 		{
 			yyVAL.exprs = []Expr{yyDollar[2].expr}
 		}
-	case 59:
+	case 60:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:672
+//line build/parse.y:673
 		{
 			yyVAL.exprs = append(yyDollar[1].exprs, yyDollar[3].expr)
 		}
-	case 61:
+	case 62:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:679
+//line build/parse.y:680
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
-	case 62:
+	case 63:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:683
+//line build/parse.y:684
 		{
 			yyVAL.expr = unary(yyDollar[1].pos, yyDollar[1].tok, yyDollar[2].expr)
 		}
-	case 63:
+	case 64:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:687
+//line build/parse.y:688
 		{
 			yyVAL.expr = unary(yyDollar[1].pos, yyDollar[1].tok, yyDollar[2].expr)
 		}
-	case 64:
+	case 65:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:692
+//line build/parse.y:693
 		{
 			yyVAL.loadargs = []*struct {
 				from Ident
 				to   Ident
 			}{yyDollar[1].loadarg}
 		}
-	case 65:
+	case 66:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:696
+//line build/parse.y:697
 		{
 			yyDollar[1].loadargs = append(yyDollar[1].loadargs, yyDollar[3].loadarg)
 			yyVAL.loadargs = yyDollar[1].loadargs
 		}
-	case 66:
+	case 67:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:702
+//line build/parse.y:703
 		{
 			start := yyDollar[1].string.Start.add("'")
 			if yyDollar[1].string.TripleQuote {
@@ -1609,6 +1617,7 @@ yydefault:
 				from: Ident{
 					Name:    yyDollar[1].string.Value,
 					NamePos: start,
+					token:   yyDollar[1].string.Token,
 				},
 				to: Ident{
 					Name:    yyDollar[1].string.Value,
@@ -1616,9 +1625,9 @@ yydefault:
 				},
 			}
 		}
-	case 67:
+	case 68:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:719
+//line build/parse.y:721
 		{
 			start := yyDollar[3].string.Start.add("'")
 			if yyDollar[3].string.TripleQuote {
@@ -1631,109 +1640,110 @@ yydefault:
 				from: Ident{
 					Name:    yyDollar[3].string.Value,
 					NamePos: start,
+					token:   yyDollar[3].string.Token,
 				},
 				to: *yyDollar[1].expr.(*Ident),
 			}
 		}
-	case 68:
+	case 69:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line build/parse.y:734
+//line build/parse.y:737
 		{
 			yyVAL.exprs = nil
 		}
-	case 69:
+	case 70:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:738
+//line build/parse.y:741
 		{
 			yyVAL.exprs = yyDollar[1].exprs
 		}
-	case 70:
+	case 71:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line build/parse.y:743
+//line build/parse.y:746
 		{
 			yyVAL.exprs = nil
 		}
-	case 71:
+	case 72:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:747
+//line build/parse.y:750
 		{
 			yyVAL.exprs = yyDollar[1].exprs
 		}
-	case 72:
+	case 73:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:753
+//line build/parse.y:756
 		{
 			yyVAL.exprs = []Expr{yyDollar[1].expr}
 		}
-	case 73:
+	case 74:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:757
+//line build/parse.y:760
 		{
 			yyVAL.exprs = append(yyDollar[1].exprs, yyDollar[3].expr)
 		}
-	case 74:
+	case 75:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:764
+//line build/parse.y:767
 		{
 			yyVAL.exprs = []Expr{yyDollar[1].expr}
 		}
-	case 75:
+	case 76:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:768
+//line build/parse.y:771
 		{
 			yyVAL.exprs = append(yyDollar[1].exprs, yyDollar[3].expr)
 		}
-	case 77:
+	case 78:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:775
+//line build/parse.y:778
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
-	case 78:
+	case 79:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:779
+//line build/parse.y:782
 		{
 			yyVAL.expr = unary(yyDollar[1].pos, yyDollar[1].tok, yyDollar[2].expr)
 		}
-	case 79:
+	case 80:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:783
+//line build/parse.y:786
 		{
 			yyVAL.expr = unary(yyDollar[1].pos, yyDollar[1].tok, nil)
 		}
-	case 80:
+	case 81:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:787
+//line build/parse.y:790
 		{
 			yyVAL.expr = unary(yyDollar[1].pos, yyDollar[1].tok, yyDollar[2].expr)
 		}
-	case 82:
+	case 83:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:796
+//line build/parse.y:799
 		{
 			yyVAL.expr = typed(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 83:
+	case 84:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line build/parse.y:800
+//line build/parse.y:803
 		{
 			yyVAL.expr = binary(typed(yyDollar[1].expr, yyDollar[3].expr), yyDollar[4].pos, yyDollar[4].tok, yyDollar[5].expr)
 		}
-	case 84:
+	case 85:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line build/parse.y:804
+//line build/parse.y:807
 		{
 			yyVAL.expr = unary(yyDollar[1].pos, yyDollar[1].tok, typed(yyDollar[2].expr, yyDollar[4].expr))
 		}
-	case 85:
+	case 86:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line build/parse.y:808
+//line build/parse.y:811
 		{
 			yyVAL.expr = unary(yyDollar[1].pos, yyDollar[1].tok, typed(yyDollar[2].expr, yyDollar[4].expr))
 		}
-	case 87:
+	case 88:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:815
+//line build/parse.y:818
 		{
 			tuple, ok := yyDollar[1].expr.(*TupleExpr)
 			if !ok || !tuple.NoBrackets {
@@ -1747,15 +1757,15 @@ yydefault:
 			tuple.List = append(tuple.List, yyDollar[3].expr)
 			yyVAL.expr = tuple
 		}
-	case 88:
+	case 89:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line build/parse.y:830
+//line build/parse.y:833
 		{
 			yyVAL.expr = nil
 		}
-	case 91:
+	case 92:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line build/parse.y:838
+//line build/parse.y:841
 		{
 			yyVAL.expr = &LambdaExpr{
 				Function: Function{
@@ -1765,159 +1775,159 @@ yydefault:
 				},
 			}
 		}
-	case 92:
-		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:847
-		{
-			yyVAL.expr = unary(yyDollar[1].pos, yyDollar[1].tok, yyDollar[2].expr)
-		}
 	case 93:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:848
+//line build/parse.y:850
 		{
 			yyVAL.expr = unary(yyDollar[1].pos, yyDollar[1].tok, yyDollar[2].expr)
 		}
 	case 94:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:849
+//line build/parse.y:851
 		{
 			yyVAL.expr = unary(yyDollar[1].pos, yyDollar[1].tok, yyDollar[2].expr)
 		}
 	case 95:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:850
+//line build/parse.y:852
 		{
 			yyVAL.expr = unary(yyDollar[1].pos, yyDollar[1].tok, yyDollar[2].expr)
 		}
 	case 96:
-		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:851
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line build/parse.y:853
 		{
-			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
+			yyVAL.expr = unary(yyDollar[1].pos, yyDollar[1].tok, yyDollar[2].expr)
 		}
 	case 97:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:852
+//line build/parse.y:854
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 98:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:853
+//line build/parse.y:855
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 99:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:854
+//line build/parse.y:856
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 100:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:855
+//line build/parse.y:857
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 101:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:856
+//line build/parse.y:858
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 102:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:857
+//line build/parse.y:859
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 103:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:858
+//line build/parse.y:860
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 104:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:859
+//line build/parse.y:861
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 105:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:860
+//line build/parse.y:862
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 106:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:861
+//line build/parse.y:863
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 107:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:862
+//line build/parse.y:864
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 108:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:863
+//line build/parse.y:865
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 109:
-		yyDollar = yyS[yypt-4 : yypt+1]
-//line build/parse.y:864
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line build/parse.y:866
 		{
-			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, "not in", yyDollar[4].expr)
+			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 110:
-		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:865
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line build/parse.y:867
 		{
-			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
+			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, "not in", yyDollar[4].expr)
 		}
 	case 111:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:866
+//line build/parse.y:868
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 112:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:867
+//line build/parse.y:869
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 113:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:868
+//line build/parse.y:870
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 114:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:869
+//line build/parse.y:871
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 115:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:870
+//line build/parse.y:872
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 116:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:871
+//line build/parse.y:873
 		{
 			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 		}
 	case 117:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:873
+//line build/parse.y:874
+		{
+			yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
+		}
+	case 118:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line build/parse.y:876
 		{
 			if b, ok := yyDollar[3].expr.(*UnaryExpr); ok && b.Op == "not" {
 				yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, "is not", b.X)
@@ -1925,9 +1935,9 @@ yydefault:
 				yyVAL.expr = binary(yyDollar[1].expr, yyDollar[2].pos, yyDollar[2].tok, yyDollar[3].expr)
 			}
 		}
-	case 118:
+	case 119:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line build/parse.y:881
+//line build/parse.y:884
 		{
 			yyVAL.expr = &ConditionalExpr{
 				Then:      yyDollar[1].expr,
@@ -1937,57 +1947,57 @@ yydefault:
 				Else:      yyDollar[5].expr,
 			}
 		}
-	case 119:
+	case 120:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:893
+//line build/parse.y:896
 		{
 			yyVAL.exprs = []Expr{yyDollar[1].expr}
 		}
-	case 120:
+	case 121:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:897
+//line build/parse.y:900
 		{
 			yyVAL.exprs = append(yyDollar[1].exprs, yyDollar[3].expr)
 		}
-	case 121:
+	case 122:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line build/parse.y:902
+//line build/parse.y:905
 		{
 			yyVAL.expr = nil
 		}
-	case 123:
+	case 124:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line build/parse.y:908
+//line build/parse.y:911
 		{
 			yyVAL.exprs, yyVAL.comma = nil, Position{}
 		}
-	case 124:
+	case 125:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:912
+//line build/parse.y:915
 		{
 			yyVAL.exprs, yyVAL.comma = yyDollar[1].exprs, yyDollar[2].pos
 		}
-	case 125:
+	case 126:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line build/parse.y:922
+//line build/parse.y:925
 		{
 			yyVAL.pos = Position{}
 		}
-	case 128:
+	case 129:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:933
+//line build/parse.y:936
 		{
 			yyVAL.pos = yyDollar[1].pos
 		}
-	case 129:
+	case 130:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line build/parse.y:941
+//line build/parse.y:944
 		{
 			yyVAL.pos = Position{}
 		}
-	case 131:
+	case 132:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:948
+//line build/parse.y:951
 		{
 			yyVAL.kv = &KeyValueExpr{
 				Key:   yyDollar[1].expr,
@@ -1995,39 +2005,39 @@ yydefault:
 				Value: yyDollar[3].expr,
 			}
 		}
-	case 132:
+	case 133:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:958
+//line build/parse.y:961
 		{
 			yyVAL.kvs = []*KeyValueExpr{yyDollar[1].kv}
 		}
-	case 133:
+	case 134:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:962
+//line build/parse.y:965
 		{
 			yyVAL.kvs = append(yyDollar[1].kvs, yyDollar[3].kv)
 		}
-	case 134:
+	case 135:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line build/parse.y:967
+//line build/parse.y:970
 		{
 			yyVAL.kvs = nil
 		}
-	case 135:
+	case 136:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:971
+//line build/parse.y:974
 		{
 			yyVAL.kvs = yyDollar[1].kvs
 		}
-	case 136:
+	case 137:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:975
+//line build/parse.y:978
 		{
 			yyVAL.kvs = yyDollar[1].kvs
 		}
-	case 138:
+	case 139:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:982
+//line build/parse.y:985
 		{
 			tuple, ok := yyDollar[1].expr.(*TupleExpr)
 			if !ok || !tuple.NoBrackets {
@@ -2041,9 +2051,9 @@ yydefault:
 			tuple.List = append(tuple.List, yyDollar[3].expr)
 			yyVAL.expr = tuple
 		}
-	case 139:
+	case 140:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:998
+//line build/parse.y:1001
 		{
 			yyVAL.string = &StringExpr{
 				Start:       yyDollar[1].pos,
@@ -2053,39 +2063,39 @@ yydefault:
 				Token:       yyDollar[1].tok,
 			}
 		}
-	case 140:
+	case 141:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:1010
+//line build/parse.y:1013
 		{
 			yyVAL.expr = &Ident{NamePos: yyDollar[1].pos, Name: yyDollar[1].tok}
 		}
-	case 141:
+	case 142:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:1016
+//line build/parse.y:1019
 		{
 			yyVAL.expr = &LiteralExpr{Start: yyDollar[1].pos, Token: yyDollar[1].tok + "." + yyDollar[3].tok}
 		}
-	case 142:
+	case 143:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:1020
+//line build/parse.y:1023
 		{
 			yyVAL.expr = &LiteralExpr{Start: yyDollar[1].pos, Token: yyDollar[1].tok + "."}
 		}
-	case 143:
+	case 144:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:1024
+//line build/parse.y:1027
 		{
 			yyVAL.expr = &LiteralExpr{Start: yyDollar[1].pos, Token: "." + yyDollar[2].tok}
 		}
-	case 144:
+	case 145:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:1028
+//line build/parse.y:1031
 		{
 			yyVAL.expr = &LiteralExpr{Start: yyDollar[1].pos, Token: yyDollar[1].tok}
 		}
-	case 145:
+	case 146:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line build/parse.y:1034
+//line build/parse.y:1037
 		{
 			yyVAL.expr = &ForClause{
 				For:  yyDollar[1].pos,
@@ -2094,30 +2104,30 @@ yydefault:
 				X:    yyDollar[4].expr,
 			}
 		}
-	case 146:
+	case 147:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:1045
+//line build/parse.y:1048
 		{
 			yyVAL.exprs = []Expr{yyDollar[1].expr}
 		}
-	case 147:
+	case 148:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line build/parse.y:1049
+//line build/parse.y:1052
 		{
 			yyVAL.exprs = append(yyDollar[1].exprs, &IfClause{
 				If:   yyDollar[2].pos,
 				Cond: yyDollar[3].expr,
 			})
 		}
-	case 148:
+	case 149:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line build/parse.y:1058
+//line build/parse.y:1061
 		{
 			yyVAL.exprs = yyDollar[1].exprs
 		}
-	case 149:
+	case 150:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line build/parse.y:1062
+//line build/parse.y:1065
 		{
 			yyVAL.exprs = append(yyDollar[1].exprs, yyDollar[2].exprs...)
 		}
@@ -212,18 +212,22 @@ var parseTests = []struct {
 						{
 							Name:    "foo",
 							NamePos: Position{1, 19, 18},
+							token:   "\"foo\"",
 						},
 						{
 							Name:    "bar",
 							NamePos: Position{1, 28, 27},
+							token:   "\"\"\"bar\"\"\"",
 						},
 						{
 							Name:    "foo",
 							NamePos: Position{1, 41, 40},
+							token:   "\"foo\"",
 						},
 						{
 							Name:    "baz",
 							NamePos: Position{1, 54, 53},
+							token:   "\"\"\"baz\"\"\"",
 						},
 					},
 					To: []*Ident{
@@ -35,7 +35,10 @@ const (
 // FormatWithoutRewriting returns the formatted form of the given Starlark file.
 // This function is mostly useful for tests only, please consider using `Format` instead.
 func FormatWithoutRewriting(f *File) []byte {
-	pr := &printer{fileType: f.Type}
+	if f.source != nil {
+		return f.source.format(f)
+	}
+	pr := &printer{fileType: f.Type, profile: f.Profile}
 	pr.file(f)
 	return pr.Bytes()
 }
@@ -60,11 +63,13 @@ func FormatString(x Expr) string {
 	}
 
 	fileType := TypeBuild // for compatibility
+	var profile *FormattingProfile
 	if file, ok := x.(*File); ok {
 		fileType = file.Type
+		profile = file.Profile
 	}
 
-	pr := &printer{fileType: fileType}
+	pr := &printer{fileType: fileType, profile: profile}
 	switch x := x.(type) {
 	case *File:
 		pr.file(x)
@@ -76,24 +81,15 @@ func FormatString(x Expr) string {
 
 // A printer collects the state during printing of a file or expression.
 type printer struct {
-	fileType     FileType  // different rules can be applied to different file types.
-	bytes.Buffer           // output buffer
-	comment      []Comment // pending end-of-line comments
-	margin       int       // left margin (indent), a number of spaces
-	depth        int       // nesting depth inside ( ) [ ] { }
-	level        int       // nesting level of def-, if-else- and for-blocks
-	needsNewLine bool      // true if the next statement needs a new line before it
-}
-
-// formattingMode returns the current file formatting mode.
-// Can be only TypeBuild or TypeDefault.
-func (p *printer) formattingMode() FileType {
-	switch p.fileType {
-	case TypeBuild, TypeWorkspace, TypeModule:
-		return TypeBuild
-	default: // TypeDefault, TypeBzl
-		return TypeDefault
-	}
+	fileType     FileType           // different rules can be applied to different file types.
+	profile      *FormattingProfile // overrides the formatting profile of the file type if non-nil
+	bytes.Buffer                    // output buffer
+	comment      []Comment          // pending end-of-line comments
+	margin       int                // left margin (indent), a number of spaces
+	depth        int                // nesting depth inside ( ) [ ] { }
+	level        int                // nesting level of def-, if-else- and for-blocks
+	needsNewLine bool               // true if the next statement needs a new line before it
+	inType       bool               // true if printing a type annotation
 }
 
 // printf prints to the buffer.
@@ -280,8 +276,8 @@ func (p *printer) compactStmt(s1, s2 Expr) bool {
 	} else if isCommentBlock(s1) || isCommentBlock(s2) {
 		// Standalone comment blocks shouldn't be attached to other statements
 		return false
-	} else if (p.formattingMode() == TypeBuild) && p.level == 0 {
-		// Top-level statements in a BUILD or WORKSPACE file
+	} else if p.formattingProfile().SeparateStatements && p.level == 0 {
+		// Top-level statements in a BUILD or WORKSPACE file, or another profile that separates them
 		return false
 	} else if isFunctionDefinition(s1) || isFunctionDefinition(s2) {
 		// On of the statements is a function definition
@@ -609,6 +605,9 @@ func (p *printer) expr(v Expr, outerPrec int) {
 	case *CommentBlock:
 		// CommentBlock has no body
 
+	case *BadStmt:
+		p.printf("%s", v.Text)
+
 	case *LiteralExpr:
 		p.printf("%s", v.Token)
 
@@ -618,7 +617,7 @@ func (p *printer) expr(v Expr, outerPrec int) {
 	case *TypedIdent:
 		p.expr(v.Ident, precLow)
 		p.printf(": ")
-		p.expr(v.Type, precLow)
+		p.typeAnnotation(v.Type)
 
 	case *BranchStmt:
 		p.printf("%s", v.Token)
@@ -633,7 +632,7 @@ func (p *printer) expr(v Expr, outerPrec int) {
 			if strings.HasPrefix(v.Token, `r`) {
 				// Raw string literal
 				token := v.Token
-				if strings.HasSuffix(v.Token, `'`) && !strings.ContainsRune(v.Value, '"') {
+				if strings.HasSuffix(v.Token, `'`) && !strings.ContainsRune(v.Value, '"') && !p.formattingProfile().PreserveQuotes {
 					// Single quotes but no double quotes inside the string, replace with double quotes
 					if strings.HasSuffix(token, `'''`) {
 						token = `r"""` + token[4:len(token)-3] + `"""`
@@ -646,8 +645,9 @@ func (p *printer) expr(v Expr, outerPrec int) {
 			}
 
 			// Non-raw string literal
-			if strings.HasPrefix(v.Token, `"`) || strings.ContainsRune(v.Value, '"') {
-				// Either double quoted or there are double-quotes inside the string
+			if strings.HasPrefix(v.Token, `"`) || strings.ContainsRune(v.Value, '"') || p.formattingProfile().PreserveQuotes {
+				// Either double quoted, there are double-quotes inside the string or the quotes
+				// should be preserved
 				if IsCorrectEscaping(v.Token) {
 					p.printf("%s", v.Token)
 					break
@@ -798,18 +798,25 @@ func (p *printer) expr(v Expr, outerPrec int) {
 		for i := range v.From {
 			from := v.From[i]
 			to := v.To[i]
+			str := from.asString()
+			if p.formattingProfile().PreserveQuotes {
+				// Keep the original string literal, e.g. with single quotes
+				if value, triple, err := Unquote(from.token); err == nil && value == from.Name {
+					str.Token, str.TripleQuote = from.token, triple
+				}
+			}
 			var arg Expr
 			if from.Name == to.Name {
 				// Suffix comments are attached to the `to` token,
 				// Before comments are attached to the `from` token,
 				// they need to be combined.
-				arg = from.asString()
+				arg = str
 				arg.Comment().Before = to.Comment().Before
 			} else {
 				arg = &AssignExpr{
 					LHS: to,
 					Op:  "=",
-					RHS: from.asString(),
+					RHS: str,
 				}
 			}
 			args = append(args, arg)
@@ -860,7 +867,7 @@ func (p *printer) expr(v Expr, outerPrec int) {
 		p.seq("()", &v.StartPos, &v.Params, nil, modeDef, v.ForceCompact, v.ForceMultiLine)
 		if v.Type != nil {
 			p.printf(" -> ")
-			p.expr(v.Type, precLow)
+			p.typeAnnotation(v.Type)
 		}
 		p.printf(":")
 		p.nestedStatements(v.Body)
@@ -959,6 +966,26 @@ const (
 	modeLoad  // load(a, b, c)
 )
 
+// typeAnnotation prints a type annotation. Sequences in type annotations, e.g. the
+// argument types of `Callable[[int, str], bool]`, keep their original style, they
+// aren't formatted as lists of a BUILD file.
+func (p *printer) typeAnnotation(v Expr) {
+	inType := p.inType
+	p.inType = true
+	p.expr(v, precLow)
+	p.inType = inType
+}
+
+// preservesLayout reports whether the formatting profile keeps the original layout
+// of sequences of the given mode.
+func (p *printer) preservesLayout(mode seqMode) bool {
+	profile := p.formattingProfile()
+	if mode == modeCall {
+		return profile.PreserveCallLayout
+	}
+	return profile.PreserveListLayout
+}
+
 // useCompactMode reports whether a sequence should be formatted in a compact mode
 func (p *printer) useCompactMode(start *Position, list *[]Expr, end *End, mode seqMode, forceCompact, forceMultiLine bool) bool {
 	// If there are line comments, use multiline
@@ -981,10 +1008,11 @@ func (p *printer) useCompactMode(start *Position, list *[]Expr, end *End, mode s
 		return true
 	}
 
-	// In the Default and .bzl printing modes try to keep the original printing style.
-	// Non-top-level statements and lists of arguments of a function definition
-	// should also keep the original style regardless of the mode.
-	if (p.level != 0 || p.formattingMode() == TypeDefault || mode == modeDef) && mode != modeLoad {
+	// If the formatting profile preserves the layout (e.g. in the Default and .bzl printing
+	// modes) try to keep the original printing style.
+	// Non-top-level statements, lists of arguments of a function definition and
+	// type annotations should also keep the original style regardless of the mode.
+	if (p.level != 0 || p.preservesLayout(mode) || mode == modeDef || p.inType) && mode != modeLoad {
 		// If every element (including the brackets) ends on the same line where the next element starts,
 		// use the compact mode, otherwise use multiline mode.
 		// If an node's line number is 0, it means it doesn't appear in the original file,
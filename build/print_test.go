@@ -511,6 +511,7 @@ func (eq *eqchecker) checkValue(v, w reflect.Value) error {
 			case tf.Name == "MultiLine": // ignore multiline setting
 			case tf.Name == "LineBreak": // ignore line break setting
 			case t == stringExprType && tf.Name == "Token": // ignore raw string token
+			case !tf.IsExported(): // ignore internal state, e.g. the source of a lossless file
 			}
 		}
 
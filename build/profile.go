@@ -0,0 +1,73 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Formatting profiles.
+
+package build
+
+// A FormattingProfile describes the formatting decisions of the printer and the rewrites
+// that by default depend on the type of the file. A profile can be assigned to a file to format it
+// independently of its type, e.g. to format BUILD files of a vendored third-party
+// directory like generic Starlark files.
+type FormattingProfile struct {
+	// PreserveListLayout keeps the original layout of the top-level lists, dicts and tuples:
+	// they're printed on a single line if they're on a single line in the original file.
+	// Otherwise sequences with more than one element are printed one element per line.
+	PreserveListLayout bool
+	// PreserveCallLayout is like PreserveListLayout but for the arguments of function calls.
+	PreserveCallLayout bool
+	// PreserveQuotes keeps single-quoted string literals as they are instead of replacing
+	// the quotes with double quotes.
+	PreserveQuotes bool
+	// SeparateStatements separates all top-level statements with blank lines, except for
+	// groups of load statements and some MODULE.bazel statements. Otherwise the blank lines
+	// between the statements are preserved.
+	SeparateStatements bool
+	// BuildRewrites applies the rewrites of BUILD files, such as sorting the arguments of
+	// rule calls or shortening labels, instead of the rewrites of generic Starlark files.
+	BuildRewrites bool
+}
+
+var (
+	// BuildProfile is the formatting profile of BUILD, WORKSPACE and MODULE.bazel files.
+	BuildProfile = FormattingProfile{
+		SeparateStatements: true,
+		BuildRewrites:      true,
+	}
+	// DefaultProfile is the formatting profile of .bzl and generic Starlark files.
+	DefaultProfile = FormattingProfile{
+		PreserveListLayout: true,
+		PreserveCallLayout: true,
+	}
+)
+
+// ProfileForType returns the formatting profile used by default for files of the given type.
+func ProfileForType(fileType FileType) FormattingProfile {
+	switch fileType {
+	case TypeBuild, TypeWorkspace, TypeModule:
+		return BuildProfile
+	default: // TypeDefault, TypeBzl
+		return DefaultProfile
+	}
+}
+
+// formattingProfile returns the formatting profile of the printed file.
+func (p *printer) formattingProfile() FormattingProfile {
+	if p.profile != nil {
+		return *p.profile
+	}
+	return ProfileForType(p.fileType)
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"testing"
+)
+
+const profileInput = `cc_library(name = 'foo', srcs = ["a.cc", "b.cc"])
+cc_library(
+    name = "bar",
+    deps = [":foo"],
+)
+x = ['a', r'b']
+`
+
+func TestFormattingProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *FormattingProfile
+		want    string
+	}{
+		{
+			name:    "file type",
+			profile: nil,
+			want: `cc_library(
+    name = "foo",
+    srcs = [
+        "a.cc",
+        "b.cc",
+    ],
+)
+
+cc_library(
+    name = "bar",
+    deps = [":foo"],
+)
+
+x = [
+    "a",
+    r"b",
+]
+`,
+		},
+		{
+			name:    "default",
+			profile: &DefaultProfile,
+			want: `cc_library(name = "foo", srcs = ["a.cc", "b.cc"])
+cc_library(
+    name = "bar",
+    deps = [":foo"],
+)
+x = ["a", r"b"]
+`,
+		},
+		{
+			name:    "preserve call layout",
+			profile: &FormattingProfile{PreserveCallLayout: true, SeparateStatements: true},
+			want: `cc_library(name = "foo", srcs = [
+    "a.cc",
+    "b.cc",
+])
+
+cc_library(
+    name = "bar",
+    deps = [":foo"],
+)
+
+x = [
+    "a",
+    r"b",
+]
+`,
+		},
+		{
+			name:    "preserve quotes",
+			profile: &FormattingProfile{PreserveListLayout: true, PreserveCallLayout: true, PreserveQuotes: true},
+			want: `cc_library(name = 'foo', srcs = ["a.cc", "b.cc"])
+cc_library(
+    name = "bar",
+    deps = [":foo"],
+)
+x = ['a', r'b']
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ParseBuild("BUILD", []byte(profileInput))
+			if err != nil {
+				t.Fatalf("ParseBuild() = %v", err)
+			}
+			f.Profile = tc.profile
+			if got := string(FormatWithoutRewriting(f)); got != tc.want {
+				t.Errorf("FormatWithoutRewriting() =\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPreserveQuotesLoad(t *testing.T) {
+	input := `load(':a.bzl', 'a', x = '\x62', y = "c")
+`
+	for _, tc := range []struct {
+		profile *FormattingProfile
+		want    string
+	}{
+		{
+			profile: &DefaultProfile,
+			want: `load(":a.bzl", "a", x = "b", y = "c")
+`,
+		},
+		{
+			profile: &FormattingProfile{PreserveQuotes: true},
+			want: `load(':a.bzl', 'a', x = '\x62', y = "c")
+`,
+		},
+	} {
+		f, err := ParseBzl("a.bzl", []byte(input))
+		if err != nil {
+			t.Fatalf("ParseBzl() = %v", err)
+		}
+		f.Profile = tc.profile
+		if got := string(FormatWithoutRewriting(f)); got != tc.want {
+			t.Errorf("FormatWithoutRewriting() with profile %+v =\n%s\nwant:\n%s", tc.profile, got, tc.want)
+		}
+	}
+}
+
+func TestProfileRewrites(t *testing.T) {
+	input := `cc_library(srcs = ["b.cc", "a.cc"], name = "foo")
+`
+	for _, tc := range []struct {
+		name    string
+		profile *FormattingProfile
+		want    string
+	}{
+		{
+			name:    "file type",
+			profile: nil,
+			want: `cc_library(
+    name = "foo",
+    srcs = [
+        "a.cc",
+        "b.cc",
+    ],
+)
+`,
+		},
+		{
+			name:    "default",
+			profile: &DefaultProfile,
+			want: `cc_library(srcs = ["a.cc", "b.cc"], name = "foo")
+`,
+		},
+		{
+			name:    "build rewrites",
+			profile: &FormattingProfile{PreserveListLayout: true, PreserveCallLayout: true, BuildRewrites: true},
+			want: `cc_library(name = "foo", srcs = ["a.cc", "b.cc"])
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ParseBuild("BUILD", []byte(input))
+			if err != nil {
+				t.Fatalf("ParseBuild() = %v", err)
+			}
+			f.Profile = tc.profile
+			if got := string(Format(f)); got != tc.want {
+				t.Errorf("Format() =\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestProfileForType(t *testing.T) {
+	tests := map[FileType]FormattingProfile{
+		TypeBuild:     BuildProfile,
+		TypeWorkspace: BuildProfile,
+		TypeModule:    BuildProfile,
+		TypeBzl:       DefaultProfile,
+		TypeDefault:   DefaultProfile,
+	}
+	for fileType, want := range tests {
+		if got := ProfileForType(fileType); got != want {
+			t.Errorf("ProfileForType(%v) = %+v, want %+v", fileType, got, want)
+		}
+	}
+}
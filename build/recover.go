@@ -0,0 +1,233 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Parsing of files with syntax errors.
+
+package build
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// ParseWithRecovery parses a file like Parse, but doesn't stop at the first syntax error.
+//
+// If the file can't be parsed, it's split into top-level statements that are parsed
+// separately. The statements that still can't be parsed are replaced with BadStmt nodes
+// that keep their source, so the returned file is never nil and contains all the valid
+// statements of the input. The syntax errors of all the bad statements are returned in
+// the order of their positions, the list is empty if the file is valid.
+func ParseWithRecovery(filename string, data []byte) (*File, []ParseError) {
+	f, err := Parse(filename, data)
+	if err == nil {
+		return f, nil
+	}
+
+	r := &recoverer{filename: filename, data: data}
+	r.parseStmts(0, len(data), false)
+	f = &File{Path: filename, Type: getFileType(filename), Stmt: r.stmts}
+	f.Before = r.before
+	f.After = r.after
+	return f, r.errs
+}
+
+// recoverer holds the state of ParseWithRecovery.
+type recoverer struct {
+	filename string
+	data     []byte
+	stmts    []Expr
+	errs     []ParseError
+
+	before []Comment // the comments at the beginning of the file
+	after  []Comment // the trailing comments of the last parsed part of the file
+}
+
+// parseStmts parses data[start:end] that consists of whole lines. If it can't be parsed
+// as a whole, the top-level statements are parsed separately. If brackets are ignored,
+// every line that starts at column 0 starts a new statement, even if a bracket of a
+// previous line isn't closed.
+func (r *recoverer) parseStmts(start, end int, ignoreBrackets bool) {
+	bounds := append([]int{start}, stmtStarts(r.data, start, end, ignoreBrackets)...)
+	bounds = append(bounds, end)
+	for i := 0; i+1 < len(bounds); i++ {
+		f, err := r.parseRange(bounds[i], bounds[i+1])
+		if err == nil {
+			r.add(f)
+			continue
+		}
+		if !ignoreBrackets && len(stmtStarts(r.data, bounds[i], bounds[i+1], true)) > 0 {
+			// Probably an unclosed bracket, try to parse the following lines
+			r.parseStmts(bounds[i], bounds[i+1], true)
+			continue
+		}
+		r.addBadStmt(bounds[i], bounds[i+1], err)
+	}
+}
+
+// parseRange parses data[start:end], keeping the positions relative to the whole file.
+func (r *recoverer) parseRange(start, end int) (*File, error) {
+	in := newInput(r.filename, r.data)
+	if end == len(r.data) {
+		// Include the newline added by newInput
+		end = len(in.complete)
+	}
+	in.remaining = in.complete[start:end]
+	in.pos = positionAt(r.data, start)
+	return in.parse()
+}
+
+// add adds the statements of a successfully parsed part of the file.
+func (r *recoverer) add(f *File) {
+	r.flushComments()
+	if len(r.stmts) == 0 {
+		r.before = append(r.before, f.Before...)
+	} else if len(f.Before) > 0 {
+		r.stmts = append(r.stmts, &CommentBlock{Comments: Comments{Before: f.Before}, Start: f.Before[0].Start})
+	}
+	r.stmts = append(r.stmts, f.Stmt...)
+	r.after = f.After
+}
+
+// addBadStmt adds a part of the file that can't be parsed.
+func (r *recoverer) addBadStmt(start, end int, err error) {
+	text := bytes.TrimSpace(r.data[start:end])
+	start += bytes.Index(r.data[start:end], text)
+	end = start + len(text)
+
+	perr, ok := err.(ParseError)
+	if !ok {
+		perr = ParseError{Message: err.Error(), Filename: r.filename, Pos: positionAt(r.data, start)}
+	}
+	r.errs = append(r.errs, perr)
+	r.flushComments()
+	r.stmts = append(r.stmts, &BadStmt{
+		Start: positionAt(r.data, start),
+		End:   positionAt(r.data, end),
+		Text:  string(text),
+	})
+}
+
+// flushComments adds the trailing comments of the previous part of the file as a
+// comment block, they aren't trailing comments of the file if it's followed by
+// other statements.
+func (r *recoverer) flushComments() {
+	if len(r.after) > 0 {
+		r.stmts = append(r.stmts, &CommentBlock{Comments: Comments{Before: r.after}, Start: r.after[0].Start})
+		r.after = nil
+	}
+}
+
+// stmtStarts returns the byte offsets in data[start:end] where new top-level statements
+// start, excluding start. A statement starts at a line that begins at column 0 outside
+// brackets and strings and isn't a comment or a continuation of a previous statement
+// such as `else:`. The comments and blank lines directly before a statement are
+// considered to be a part of it.
+func stmtStarts(data []byte, start, end int, ignoreBrackets bool) []int {
+	var starts []int
+	depth := 0
+	quote := "" // the closing quote of the current string, if any
+	for i := start; i < end; {
+		if i > start && data[i-1] == '\n' && quote == "" && (depth == 0 || ignoreBrackets) && startsStmt(data[i:end]) {
+			depth = 0
+			lowerBound := start
+			if len(starts) > 0 {
+				lowerBound = starts[len(starts)-1]
+			}
+			if stmtStart := leadingTrivia(data, lowerBound, i); stmtStart > lowerBound {
+				starts = append(starts, stmtStart)
+			}
+		}
+
+		c := data[i]
+		switch {
+		case quote != "" && c == '\\':
+			i += 2
+			continue
+		case quote != "" && bytes.HasPrefix(data[i:end], []byte(quote)):
+			i += len(quote)
+			quote = ""
+			continue
+		case quote != "":
+			if c == '\n' && len(quote) == 1 {
+				// Unterminated string
+				quote = ""
+			}
+		case c == '#':
+			if n := bytes.IndexByte(data[i:end], '\n'); n >= 0 {
+				i += n
+				continue
+			}
+			i = end
+			continue
+		case c == '"' || c == '\'':
+			quote = string(c)
+			if bytes.HasPrefix(data[i:end], []byte{c, c, c}) {
+				quote = string([]byte{c, c, c})
+			}
+			i += len(quote)
+			continue
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			if depth > 0 {
+				depth--
+			}
+		}
+		i++
+	}
+	return starts
+}
+
+// startsStmt reports whether a line can be the first line of a top-level statement.
+func startsStmt(line []byte) bool {
+	if len(line) == 0 {
+		return false
+	}
+	switch line[0] {
+	case ' ', '\t', '\r', '\n', '#', ')', ']', '}':
+		return false
+	}
+	for _, keyword := range []string{"else", "elif"} {
+		if bytes.HasPrefix(line, []byte(keyword)) && (len(line) == len(keyword) || !isIdent(int(line[len(keyword)]))) {
+			return false
+		}
+	}
+	return true
+}
+
+// leadingTrivia returns the offset of the first line of the block of comments and blank
+// lines that directly precedes offset, not before lowerBound.
+func leadingTrivia(data []byte, lowerBound, offset int) int {
+	for offset > lowerBound {
+		lineStart := bytes.LastIndexByte(data[lowerBound:offset-1], '\n') + lowerBound + 1
+		line := bytes.TrimSpace(data[lineStart:offset])
+		if len(line) > 0 && (line[0] != '#' || data[lineStart] != '#') {
+			break
+		}
+		offset = lineStart
+	}
+	return offset
+}
+
+// positionAt returns the position of a byte offset in data.
+func positionAt(data []byte, offset int) Position {
+	pos := Position{Line: 1, LineRune: 1}
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	pos.Line += bytes.Count(data[:lineStart], []byte("\n"))
+	pos.LineRune += utf8.RuneCount(data[lineStart:offset])
+	pos.Byte = offset
+	return pos
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// describeStmts returns the types and the line ranges of the statements of a file.
+func describeStmts(f *File) string {
+	var lines []string
+	for _, stmt := range f.Stmt {
+		start, end := stmt.Span()
+		lines = append(lines, fmt.Sprintf("%T %d-%d", stmt, start.Line, end.Line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestParseWithRecovery(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		input  string
+		stmts  string
+		errors []string
+	}{
+		{
+			name: "valid",
+			input: `load(":a.bzl", "a")
+
+a(name = "x")
+`,
+			stmts: `*build.LoadStmt 1-1
+*build.CallExpr 3-3`,
+		},
+		{
+			name: "multiple errors",
+			input: `# Header
+
+load(":a.bzl", "a")
+
+a(
+    name = "x",
+    srcs = [
+)
+
+# About y
+a(name = "y")
+
+def f():
+    return 1 +
+
+a(name = "z")
+`,
+			stmts: `*build.CommentBlock 1-1
+*build.LoadStmt 3-3
+*build.BadStmt 5-8
+*build.CallExpr 11-11
+*build.BadStmt 13-14
+*build.CallExpr 16-16`,
+			errors: []string{
+				"BUILD:8:2: syntax error near )",
+				"BUILD:15:1: syntax error near \n",
+			},
+		},
+		{
+			name: "unclosed bracket",
+			input: `a(
+    name = "x",
+    deps = [
+
+a(name = "y")
+b = [
+`,
+			stmts: `*build.BadStmt 1-3
+*build.CallExpr 5-5
+*build.BadStmt 6-6`,
+			errors: []string{
+				"BUILD:4:1: syntax error",
+				"BUILD:8:1: syntax error",
+			},
+		},
+		{
+			name: "statements at column 0 inside brackets",
+			input: `a(
+name = "x",
+)
+
+b = """
+c(
+"""
+
+d(
+`,
+			stmts: `*build.CallExpr 1-3
+*build.AssignExpr 5-7
+*build.BadStmt 9-9`,
+			errors: []string{
+				"BUILD:11:1: syntax error",
+			},
+		},
+		{
+			name: "else branch",
+			input: `if x:
+    a = 1
+else:
+    a = 2
+b = )
+`,
+			stmts: `*build.IfStmt 1-4
+*build.BadStmt 5-5`,
+			errors: []string{
+				"BUILD:5:6: syntax error near )",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, errs := ParseWithRecovery("BUILD", []byte(tc.input))
+			if f == nil {
+				t.Fatalf("ParseWithRecovery() returned no file")
+			}
+			if got := describeStmts(f); got != tc.stmts {
+				t.Errorf("ParseWithRecovery() statements:\n%s\nwant:\n%s", got, tc.stmts)
+			}
+			var messages []string
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			if strings.Join(messages, "\n") != strings.Join(tc.errors, "\n") {
+				t.Errorf("ParseWithRecovery() errors:\n%q\nwant:\n%q", messages, tc.errors)
+			}
+		})
+	}
+}
+
+func TestParseWithRecoveryPrint(t *testing.T) {
+	input := `load(":a.bzl",   "a")
+
+a(name = "x",
+  deps = [":y"
+
+# Comment
+a(name = "y")
+# Trailing comment
+`
+	want := `load(":a.bzl", "a")
+
+a(name = "x",
+  deps = [":y"
+
+# Comment
+a(name = "y")
+# Trailing comment
+`
+	f, errs := ParseWithRecovery("BUILD", []byte(input))
+	if len(errs) != 1 {
+		t.Errorf("ParseWithRecovery() errors = %v, want 1 error", errs)
+	}
+	if got := string(FormatWithoutRewriting(f)); got != want {
+		t.Errorf("FormatWithoutRewriting() =\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -74,7 +74,12 @@ type Rewriter struct {
 
 // Rewrite applies rewrites to a file
 func Rewrite(f *File) {
-	var rewriter = &Rewriter{
+	defaultRewriter().Rewrite(f)
+}
+
+// defaultRewriter returns a rewriter configured with the tables.
+func defaultRewriter() *Rewriter {
+	return &Rewriter{
 		IsLabelArg:                      tables.IsLabelArg,
 		LabelDenyList:                   tables.LabelDenylist,
 		IsSortableListArg:               tables.IsSortableListArg,
@@ -84,21 +89,46 @@ func Rewrite(f *File) {
 		StripLabelLeadingSlashes:        tables.StripLabelLeadingSlashes,
 		ShortenAbsoluteLabelsToRelative: tables.ShortenAbsoluteLabelsToRelative,
 	}
-	rewriter.Rewrite(f)
 }
 
 // Rewrite applies the rewrites to a file
 func (w *Rewriter) Rewrite(f *File) {
+	w.rewrite(f, nil)
+}
+
+// rewrite applies the rewrites to a file, except for the ones in the skip set.
+func (w *Rewriter) rewrite(f *File, skip map[string]bool) {
 	for _, r := range rewrites {
+		if skip[r.name] {
+			continue
+		}
 		// f.Type&r.scope is a bitwise comparison. Because starlark files result in a scope that will
 		// not be changed by rewrites, we have included another check looking on the right side.
 		// If we have an empty rewrite set, we do not want any rewrites to happen.
-		if (!disabled(r.name) && (f.Type&r.scope != 0) && w.RewriteSet == nil) || (w.RewriteSet != nil && rewriteSetContains(w, r.name)) {
+		if (!disabled(r.name) && (rewriteScope(f)&r.scope != 0) && w.RewriteSet == nil) || (w.RewriteSet != nil && rewriteSetContains(w, r.name)) {
 			r.fn(f, w)
 		}
 	}
 }
 
+// rewriteScope returns the file type that selects the rewrites applied to a file: its
+// own type, unless its formatting profile selects the rewrites of another type.
+func rewriteScope(f *File) FileType {
+	if f.Profile == nil {
+		return f.Type
+	}
+	if f.Profile.BuildRewrites {
+		if f.Type&scopeBuild != 0 {
+			return f.Type
+		}
+		return TypeBuild
+	}
+	if f.Type&scopeDefault != 0 {
+		return f.Type
+	}
+	return TypeDefault
+}
+
 func rewriteSetContains(w *Rewriter, name string) bool {
 	for _, value := range w.RewriteSet {
 		if value == name {
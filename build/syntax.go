@@ -108,8 +108,11 @@ type File struct {
 	Label         string // optional; file path relative to the package name (always forward slashes)
 	WorkspaceRoot string // optional; path to the directory containing the WORKSPACE file
 	Type          FileType
+	Profile       *FormattingProfile // optional; overrides the formatting profile of the file type
 	Comments
 	Stmt []Expr
+
+	source *fileSource // the source of the file if it was parsed with ParseLossless
 }
 
 // DisplayPath returns the filename if it's not empty, "<stdin>" otherwise
@@ -163,11 +166,33 @@ func (x *CommentBlock) Copy() Expr {
 	return &n
 }
 
+// A BadStmt represents a top-level statement with a syntax error, see ParseWithRecovery.
+// It's printed as is.
+type BadStmt struct {
+	Comments
+	Start Position
+	End   Position
+	Text  string // the source of the statement
+}
+
+// Span returns the start and end positions of the node
+func (x *BadStmt) Span() (start, end Position) {
+	return x.Start, x.End
+}
+
+//Copy creates and returns a non-deep copy of BadStmt
+func (x *BadStmt) Copy() Expr {
+	n := *x
+	return &n
+}
+
 // An Ident represents an identifier.
 type Ident struct {
 	Comments
 	NamePos Position
 	Name    string
+
+	token string // the string literal of the names loaded by load statements
 }
 
 // Span returns the start and end positions of the node
@@ -50,7 +50,8 @@ func GetParamIdent(param Expr) (ident *Ident, op string) {
 // GetTypes returns the list of types defined by the a given expression.
 // Examples:
 //
-// List[tuple[bool, int]] should return [List, Tuple, bool, int]
+// List[tuple[bool, int]] should return [List, tuple, bool, int]
+// str | None should return [str, None]
 // str should return str
 func GetTypes(t Expr) []string {
 	switch t := t.(type) {
@@ -69,6 +70,26 @@ func GetTypes(t Expr) []string {
 		left := GetTypes(t.X)
 		right := GetTypes(t.Y)
 		return append(left, right...)
+	case *TupleExpr:
+		// Type arguments, e.g. `bool, int` in `tuple[bool, int]`
+		var ret []string
+		for _, x := range t.List {
+			ret = append(ret, GetTypes(x)...)
+		}
+		return ret
+	case *ListExpr:
+		// Argument types of callables, e.g. `[int, str]` in `Callable[[int, str], bool]`
+		var ret []string
+		for _, x := range t.List {
+			ret = append(ret, GetTypes(x)...)
+		}
+		return ret
+	case *BinaryExpr:
+		// Union types, e.g. `int | None`
+		if t.Op == "|" {
+			return append(GetTypes(t.X), GetTypes(t.Y)...)
+		}
+		return []string{}
 	case *DotExpr:
 		// Special handling for skylark-rust interpreter, types are referred to by a `.type` suffix
 		if t.Name == "type" {
@@ -198,10 +198,18 @@ func WalkOnce(v Expr, f func(x *Expr)) {
 			f(&to)
 			v.To[i] = to.(*Ident)
 		}
+	case *TypedIdent:
+		ident := (Expr)(v.Ident)
+		f(&ident)
+		v.Ident = ident.(*Ident)
+		f(&v.Type)
 	case *DefStmt:
 		for i := range v.Params {
 			f(&v.Params[i])
 		}
+		if v.Type != nil {
+			f(&v.Type)
+		}
 		for i := range v.Body {
 			f(&v.Body[i])
 		}
@@ -28,8 +28,10 @@ import (
 
 	"github.com/bazelbuild/buildtools/build"
 	"github.com/bazelbuild/buildtools/buildifier/config"
+	"github.com/bazelbuild/buildtools/buildifier/lsp"
 	"github.com/bazelbuild/buildtools/buildifier/utils"
 	"github.com/bazelbuild/buildtools/differ"
+	"github.com/bazelbuild/buildtools/warn"
 	"github.com/bazelbuild/buildtools/wspace"
 )
 
@@ -40,7 +42,7 @@ func usage() {
 	fmt.Fprintf(flag.CommandLine.Output(), `usage: buildifier [-d] [-v] [-r] [-config=path.json] [-diff_command=command] [-help] [-multi_diff] [-mode=mode] [-lint=lint_mode] [-path=path] [files...]
 
 Buildifier applies standard formatting to the named Starlark files.  The mode
-flag selects the processing: check, diff, fix, or print_if_changed.  In check
+flag selects the processing: check, diff, fix, print_if_changed, or lsp.  In check
 mode, buildifier prints a list of files that need reformatting.  In diff mode,
 buildifier shows the diffs that it would make.  It creates the diffs by running
 a diff command, which can be specified using the -diff_command flag. You can
@@ -48,17 +50,26 @@ indicate that the diff command can show differences between more than two files
 in the manner of tkdiff by specifying the -multi_diff flag.  In fix mode,
 buildifier updates the files that need reformatting and, if the -v flag is
 given, prints their names to standard error.  In print_if_changed mode,
-buildifier shows the file contents it would write.  The default mode is fix. -d
-is an alias for -mode=diff.
+buildifier shows the file contents it would write.  In lsp mode, buildifier
+runs a language server that communicates over standard input and output and
+provides formatting, diagnostics for the warnings selected by the -warnings
+flag, and quick fixes for warnings that can be fixed automatically.  The
+default mode is fix. -d is an alias for -mode=diff.
 
 The lint flag selects the lint mode to be used: off, warn, fix.
 In off mode, the linting is not performed.
 In warn mode, buildifier prints warnings for common mistakes and suboptimal
 coding practices that include links providing more context and fix suggestions.
 In fix mode, buildifier updates the files with all warning resolutions produced
-by automated fixes.
+by automated fixes. The -fix_only flag limits the fixes to the given comma-separated
+warnings and calculates each fix independently; fixes that overlap with other
+fixes are skipped and reported. It implies -lint=fix.
 The default lint mode is off.
 
+In warn mode, the -baseline flag specifies a JSON file with known findings that
+aren't reported. If the file doesn't exist, buildifier creates it with all the
+current findings instead of reporting them.
+
 If no files are listed, buildifier reads a Starlark file from standard
 input. In fix mode, it writes the reformatted Starlark file to standard output,
 even if no changes are necessary.
@@ -146,6 +157,17 @@ func main() {
 	build.DisableRewrites = c.DisableRewrites
 	build.AllowSort = c.AllowSort
 
+	if c.Mode == "lsp" {
+		// The language server protocol requires exit code 1 if the client
+		// hasn't shut down the server properly.
+		server := lsp.NewServer(c.InputType, c.LintWarnings, c.FormattingProfile)
+		if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "buildifier: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	differ, deprecationWarning := differ.Find()
 	if c.DiffCommand != "" {
 		differ.Cmd = c.DiffCommand
@@ -156,7 +178,7 @@ func main() {
 		}
 	}
 
-	b := buildifier{c, differ}
+	b := buildifier{config: c, differ: differ}
 	exitCode := b.run(args)
 
 	os.Exit(exitCode)
@@ -165,12 +187,31 @@ func main() {
 type buildifier struct {
 	config *config.Config
 	differ *differ.Differ
+	// baseline contains the known findings that aren't reported
+	baseline *utils.Baseline
+	// recordBaseline is true if the baseline file doesn't exist yet and should
+	// be created with all findings
+	recordBaseline bool
 }
 
 func (b *buildifier) run(args []string) int {
 	tf := &utils.TempFile{}
 	defer tf.Clean()
 
+	if b.config.Baseline != "" {
+		baseline, err := utils.LoadBaseline(b.config.Baseline)
+		switch {
+		case os.IsNotExist(err):
+			b.baseline = utils.NewBaseline()
+			b.recordBaseline = true
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "buildifier: %v\n", err)
+			return 2
+		default:
+			b.baseline = baseline
+		}
+	}
+
 	exitCode := 0
 	var diagnostics *utils.Diagnostics
 	if len(args) == 0 || (len(args) == 1 && (args)[0] == "-") {
@@ -199,6 +240,14 @@ func (b *buildifier) run(args []string) int {
 		diagnostics, exitCode = b.processFiles(files, tf)
 	}
 
+	if b.recordBaseline {
+		if err := b.baseline.Write(b.config.Baseline); err != nil {
+			fmt.Fprintf(os.Stderr, "buildifier: writing baseline: %v\n", err)
+			return 2
+		}
+		fmt.Fprintf(os.Stderr, "buildifier: recorded %d findings in %s\n", len(b.baseline.Findings), b.config.Baseline)
+	}
+
 	diagnosticsOutput := diagnostics.Format(b.config.Format, b.config.Verbose)
 	if b.config.Format != "" {
 		// Explicitly provided --format means the diagnostics are printed to stdout
@@ -278,6 +327,39 @@ func (b *buildifier) processFiles(files []string, tf *utils.TempFile) (*utils.Di
 	return utils.NewDiagnostics(fileDiagnostics...), exitCode
 }
 
+// processInvalidFile reports all syntax errors of a file that can't be parsed.
+// The valid statements of the file are still linted in the warn mode, the file
+// is never modified.
+func (b *buildifier) processInvalidFile(displayFilename string, data []byte) (*utils.FileDiagnostics, int) {
+	f, errs := utils.GetRecoveringParser(b.config.InputType)(displayFilename, data)
+	for _, err := range errs {
+		// Do not use buildifier: prefix on this error.
+		// Since it is a parse error, it begins with file:line:
+		// and we want that to be the first thing in the error.
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+	if b.config.Lint != "warn" || b.config.FixOnly != "" {
+		return utils.InvalidFileDiagnostics(displayFilename), 1
+	}
+
+	if absoluteFilename, err := filepath.Abs(displayFilename); err == nil {
+		f.WorkspaceRoot, f.Pkg, f.Label = wspace.SplitFilePath(absoluteFilename)
+	}
+	warnings := utils.Lint(f, b.config.Lint, &b.config.LintWarnings, b.config.Verbose)
+	if b.baseline != nil {
+		if b.recordBaseline {
+			b.baseline.Add(f, data, warnings)
+			warnings = nil
+		} else {
+			warnings = b.baseline.Filter(f, data, warnings)
+		}
+	}
+	fileDiagnostics := utils.NewFileDiagnostics(f.DisplayPath(), warnings)
+	fileDiagnostics.Formatted = false
+	fileDiagnostics.Valid = false
+	return fileDiagnostics, 1
+}
+
 // processFile processes a single file containing data.
 // It has been read from filename and should be written back if fixing.
 func (b *buildifier) processFile(filename string, data []byte, displayFileNames bool, tf *utils.TempFile) (*utils.FileDiagnostics, int) {
@@ -292,27 +374,53 @@ func (b *buildifier) processFile(filename string, data []byte, displayFileNames
 
 	f, err := parser(displayFilename, data)
 	if err != nil {
-		// Do not use buildifier: prefix on this error.
-		// Since it is a parse error, it begins with file:line:
-		// and we want that to be the first thing in the error.
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		if exitCode < 1 {
-			exitCode = 1
-		}
-		return utils.InvalidFileDiagnostics(displayFilename), exitCode
+		return b.processInvalidFile(displayFilename, data)
 	}
 
 	if absoluteFilename, err := filepath.Abs(displayFilename); err == nil {
 		f.WorkspaceRoot, f.Pkg, f.Label = wspace.SplitFilePath(absoluteFilename)
 	}
-
-	warnings := utils.Lint(f, b.config.Lint, &b.config.LintWarnings, b.config.Verbose)
+	// The profile is needed by the linter to check whether the file is formatted
+	f.Profile = b.config.FormattingProfile(displayFilename, f.Type)
+
+	var warnings []*warn.Finding
+	switch {
+	case b.config.FixOnly != "":
+		var conflicts []warn.FixConflict
+		f, conflicts, err = utils.FixOnly(f, &b.config.LintWarnings, parser)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "buildifier: %s: applying fixes: %v\n", displayFilename, err)
+			return utils.InvalidFileDiagnostics(displayFilename), 2
+		}
+		for _, c := range conflicts {
+			fmt.Fprintf(os.Stderr, "%s:%d: %s: fix skipped, it conflicts with the fix for %s on line %d\n",
+				f.DisplayPath(), c.Skipped.Start.Line, c.Skipped.Category, c.Applied.Category, c.Applied.Start.Line)
+		}
+	case b.config.Format == "sarif" && b.config.Lint == "warn":
+		// SARIF results can contain suggested fixes
+		warnings = utils.Suggest(f, data, &b.config.LintWarnings, parser)
+	default:
+		warnings = utils.Lint(f, b.config.Lint, &b.config.LintWarnings, b.config.Verbose)
+	}
+	if b.baseline != nil {
+		if b.recordBaseline {
+			b.baseline.Add(f, data, warnings)
+			warnings = nil
+		} else {
+			warnings = b.baseline.Filter(f, data, warnings)
+		}
+	}
 	if len(warnings) > 0 {
 		exitCode = 4
 	}
 	fileDiagnostics := utils.NewFileDiagnostics(f.DisplayPath(), warnings)
 
-	ndata := build.Format(f)
+	var ndata []byte
+	if b.config.Lines != "" {
+		ndata = build.FormatRange(f, data, b.config.LinesStart, b.config.LinesEnd)
+	} else {
+		ndata = build.Format(f)
+	}
 
 	switch b.config.Mode {
 	case "check":
@@ -25,10 +25,12 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/bazelbuild/buildtools/tables"
 	"github.com/bazelbuild/buildtools/warn"
+	"github.com/bazelbuild/buildtools/warn/custom"
 	"github.com/bazelbuild/buildtools/wspace"
 )
 
@@ -96,9 +98,9 @@ type Config struct {
 	// Starlark files), module (for MODULE.bazel files)
 	// or auto (default, based on the filename)
 	InputType string `json:"type,omitempty"`
-	// Format sets the diagnostics format: text or json (default text)
+	// Format sets the diagnostics format: text, json, or sarif (default text)
 	Format string `json:"format,omitempty"`
-	// Mode determines the formatting mode: check, diff, or fix (default fix)
+	// Mode determines the formatting mode: check, diff, fix, or lsp (default fix)
 	Mode string `json:"mode,omitempty"`
 	// DiffMode is an alias for
 	DiffMode bool `json:"diffMode,omitempty"`
@@ -108,6 +110,15 @@ type Config struct {
 	Warnings string `json:"warnings,omitempty"`
 	// WarningsList is a list of warnings (alternative to comma-separated warnings string)
 	WarningsList []string `json:"warningsList,omitempty"`
+	// CustomWarnings is a list of Starlark files that define additional warnings.
+	// Relative paths are resolved against the directory of the config file.
+	CustomWarnings []string `json:"customWarnings,omitempty"`
+	// FixOnly is a comma-separated list of warning identifiers whose automatic
+	// fixes are calculated and applied independently of each other (implies --lint=fix)
+	FixOnly string `json:"fixOnly,omitempty"`
+	// Baseline is the path to a JSON file with known lint findings that aren't
+	// reported. The file is created with the current findings if it doesn't exist.
+	Baseline string `json:"baseline,omitempty"`
 	// Recursive instructs buildifier to find starlark files recursively
 	Recursive bool `json:"recursive,omitempty"`
 	// Verbose instructs buildifier to output verbose diagnostics
@@ -130,6 +141,23 @@ type Config struct {
 	DisableRewrites ArrayFlags `json:"buildifier_disable,omitempty"`
 	// AllowSort specifies additional sort contexts to treat as safe
 	AllowSort ArrayFlags `json:"allowsort,omitempty"`
+	// Profile is the name of the formatting profile: build, default or a profile defined
+	// in Profiles (default based on the file type)
+	Profile string `json:"profile,omitempty"`
+	// Profiles defines named formatting profiles in addition to the built-in build and
+	// default profiles
+	Profiles map[string]*Profile `json:"profiles,omitempty"`
+	// ProfileOverrides maps directories to the names of the formatting profiles used for
+	// the files in them instead of Profile. Relative directories are resolved against the
+	// directory of the config file, the longest directory that contains a file is used.
+	ProfileOverrides map[string]string `json:"profileOverrides,omitempty"`
+
+	// Lines is the range of lines to format, in the form START:END (1-based, inclusive).
+	// Only the statements overlapping the range are formatted.
+	Lines string `json:"-"`
+	// LinesStart and LinesEnd are the validated bounds of the Lines range
+	LinesStart int `json:"-"`
+	LinesEnd   int `json:"-"`
 
 	// Help is true if the -h flag is set
 	Help bool `json:"-"`
@@ -173,16 +201,20 @@ func (c *Config) FlagSet(name string, errorHandling flag.ErrorHandling) *flag.Fl
 	flags.BoolVar(&c.DiffMode, "d", c.DiffMode, "alias for -mode=diff")
 	flags.BoolVar(&c.Recursive, "r", c.Recursive, "find starlark files recursively")
 	flags.BoolVar(&c.MultiDiff, "multi_diff", c.MultiDiff, "the command specified by the -diff_command flag can diff multiple files in the style of tkdiff (default false)")
-	flags.StringVar(&c.Mode, "mode", c.Mode, "formatting mode: check, diff, or fix (default fix)")
-	flags.StringVar(&c.Format, "format", c.Format, "diagnostics format: text or json (default text)")
+	flags.StringVar(&c.Mode, "mode", c.Mode, "formatting mode: check, diff, fix, or lsp (default fix)")
+	flags.StringVar(&c.Format, "format", c.Format, "diagnostics format: text, json, or sarif (default text)")
 	flags.StringVar(&c.DiffCommand, "diff_command", c.DiffCommand, "command to run when the formatting mode is diff (default uses the BUILDIFIER_DIFF, BUILDIFIER_MULTIDIFF, and DISPLAY environment variables to create the diff command)")
 	flags.StringVar(&c.Lint, "lint", c.Lint, "lint mode: off, warn, or fix (default off)")
 	flags.StringVar(&c.Warnings, "warnings", c.Warnings, "comma-separated warnings used in the lint mode or \"all\"")
+	flags.StringVar(&c.FixOnly, "fix_only", c.FixOnly, "comma-separated warnings to fix independently of each other, skipping conflicting fixes (implies --lint=fix)")
+	flags.StringVar(&c.Baseline, "baseline", c.Baseline, "path to JSON file with known lint findings that aren't reported, created with the current findings if it doesn't exist")
 	flags.StringVar(&c.WorkspaceRelativePath, "path", c.WorkspaceRelativePath, "assume BUILD file has this path relative to the workspace directory")
 	flags.StringVar(&c.TablesPath, "tables", c.TablesPath, "path to JSON file with custom table definitions which will replace the built-in tables")
 	flags.StringVar(&c.AddTablesPath, "add_tables", c.AddTablesPath, "path to JSON file with custom table definitions which will be merged with the built-in tables")
 	flags.StringVar(&c.InputType, "type", c.InputType, "Input file type: build (for BUILD files), bzl (for .bzl files), workspace (for WORKSPACE files), module (for MODULE.bazel files), default (for generic Starlark files) or auto (default, based on the filename)")
 	flags.StringVar(&c.ConfigPath, "config", "", "path to .buildifier.json config file")
+	flags.StringVar(&c.Profile, "profile", c.Profile, "formatting profile: build, default, or the name of a profile from the config file (default based on the file type)")
+	flags.StringVar(&c.Lines, "lines", "", "format only the statements overlapping the given range of lines, in the form START:END (1-based, inclusive)")
 	flags.Var(&c.AllowSort, "allowsort", "additional sort contexts to treat as safe")
 	flags.Var(&c.DisableRewrites, "buildifier_disable", "list of buildifier rewrites to disable")
 
@@ -201,16 +233,43 @@ func (c *Config) Validate(args []string) error {
 		return err
 	}
 
-	if err := ValidateModes(&c.Mode, &c.Lint, &c.DiffMode); err != nil {
+	if c.FixOnly != "" {
+		if c.Lint == "" {
+			c.Lint = "fix"
+		} else if c.Lint != "fix" {
+			return fmt.Errorf("--fix_only is only compatible with --lint=fix")
+		}
+	}
+
+	if err := ValidateModes(&c.Mode, &c.Lint, &c.DiffMode, "lsp"); err != nil {
 		return err
 	}
 
+	if c.Baseline != "" && c.Lint != "warn" {
+		return fmt.Errorf("--baseline is only compatible with --lint=warn")
+	}
+
+	// The language server receives files from the editor.
+	if c.Mode == "lsp" && len(args) > 0 {
+		return fmt.Errorf("cannot specify files with --mode=lsp")
+	}
+
 	// If the path flag is set, must only be formatting a single file.
 	// It doesn't make sense for multiple files to have the same path.
 	if (c.WorkspaceRelativePath != "" || c.Mode == "print_if_changed") && len(args) > 1 {
 		return fmt.Errorf("can only format one file when using -path flag or -mode=print_if_changed")
 	}
 
+	if c.Lines != "" {
+		if err := c.validateLines(args); err != nil {
+			return err
+		}
+	}
+
+	if err := c.validateProfiles(); err != nil {
+		return err
+	}
+
 	if c.TablesPath != "" {
 		foundTablesPath, err := findTablesPath(c.TablesPath)
 		if err != nil {
@@ -231,6 +290,19 @@ func (c *Config) Validate(args []string) error {
 		}
 	}
 
+	for _, path := range c.CustomWarnings {
+		if !filepath.IsAbs(path) && c.ConfigPath != "" {
+			path = filepath.Join(filepath.Dir(c.ConfigPath), path)
+		}
+		warnings, err := custom.LoadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to load custom warnings from %s: %w", path, err)
+		}
+		if err := custom.Register(warnings...); err != nil {
+			return fmt.Errorf("failed to register custom warnings from %s: %w", path, err)
+		}
+	}
+
 	warningsList := c.WarningsList
 	if c.Warnings != "" {
 		warningsList = append(warningsList, c.Warnings)
@@ -242,6 +314,39 @@ func (c *Config) Validate(args []string) error {
 	}
 	c.LintWarnings = lintWarnings
 
+	if c.FixOnly != "" {
+		fixWarnings, err := ValidateFixOnly(&c.FixOnly, &warn.AllWarnings)
+		if err != nil {
+			return err
+		}
+		c.LintWarnings = fixWarnings
+	}
+
+	return nil
+}
+
+// validateLines parses the range of the --lines flag and checks that it can be applied.
+func (c *Config) validateLines(args []string) error {
+	start, end, ok := strings.Cut(c.Lines, ":")
+	var err error
+	if ok {
+		c.LinesStart, err = strconv.Atoi(start)
+	}
+	if ok && err == nil {
+		c.LinesEnd, err = strconv.Atoi(end)
+	}
+	if !ok || err != nil || c.LinesStart < 1 || c.LinesEnd < c.LinesStart {
+		return fmt.Errorf("invalid --lines range %q, expected START:END with 1 <= START <= END", c.Lines)
+	}
+	if c.Lint == "fix" {
+		return fmt.Errorf("--lines is only compatible with --lint=off or --lint=warn")
+	}
+	if c.Mode == "lsp" {
+		return fmt.Errorf("--lines is not compatible with --mode=lsp")
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("can only format one file when using --lines flag")
+	}
 	return nil
 }
 
@@ -58,12 +58,16 @@ func ExampleExample() {
 	//     "constant-glob",
 	//     "ctx-actions",
 	//     "ctx-args",
+	//     "dangling-label",
 	//     "deprecated-function",
 	//     "depset-items",
 	//     "depset-iteration",
 	//     "depset-union",
+	//     "dev-dependency-mismatch",
+	//     "dev-dependency-registration",
 	//     "dict-concatenation",
 	//     "dict-method-named-arg",
+	//     "duplicate-bazel-dep",
 	//     "duplicated-name",
 	//     "external-path",
 	//     "filetype",
@@ -124,6 +128,7 @@ func ExampleExample() {
 	//     "native-sh-library",
 	//     "native-sh-test",
 	//     "no-effect",
+	//     "non-root-override",
 	//     "output-group",
 	//     "overly-nested-depset",
 	//     "package-name",
@@ -138,11 +143,16 @@ func ExampleExample() {
 	//     "skylark-comment",
 	//     "skylark-docstring",
 	//     "string-iteration",
+	//     "target-visibility",
+	//     "type-annotation-mismatch",
 	//     "uninitialized",
 	//     "unnamed-macro",
 	//     "unreachable",
+	//     "unsorted-bazel-deps",
 	//     "unsorted-dict-items",
-	//     "unused-variable"
+	//     "unused-extension",
+	//     "unused-variable",
+	//     "use-repo"
 	//   ]
 	// }
 }
@@ -156,16 +166,20 @@ func ExampleFlagSet() {
 	// Output:
 	// add_tables: path to JSON file with custom table definitions which will be merged with the built-in tables ("")
 	// allowsort: additional sort contexts to treat as safe ("")
+	// baseline: path to JSON file with known lint findings that aren't reported, created with the current findings if it doesn't exist ("")
 	// buildifier_disable: list of buildifier rewrites to disable ("")
 	// config: path to .buildifier.json config file ("")
 	// d: alias for -mode=diff ("false")
 	// diff_command: command to run when the formatting mode is diff (default uses the BUILDIFIER_DIFF, BUILDIFIER_MULTIDIFF, and DISPLAY environment variables to create the diff command) ("")
-	// format: diagnostics format: text or json (default text) ("")
+	// fix_only: comma-separated warnings to fix independently of each other, skipping conflicting fixes (implies --lint=fix) ("")
+	// format: diagnostics format: text, json, or sarif (default text) ("")
 	// help: print usage information ("false")
+	// lines: format only the statements overlapping the given range of lines, in the form START:END (1-based, inclusive) ("")
 	// lint: lint mode: off, warn, or fix (default off) ("")
-	// mode: formatting mode: check, diff, or fix (default fix) ("")
+	// mode: formatting mode: check, diff, fix, or lsp (default fix) ("")
 	// multi_diff: the command specified by the -diff_command flag can diff multiple files in the style of tkdiff (default false) ("false")
 	// path: assume BUILD file has this path relative to the workspace directory ("")
+	// profile: formatting profile: build, default, or the name of a profile from the config file (default based on the file type) ("")
 	// r: find starlark files recursively ("false")
 	// tables: path to JSON file with custom table definitions which will replace the built-in tables ("")
 	// type: Input file type: build (for BUILD files), bzl (for .bzl files), workspace (for WORKSPACE files), module (for MODULE.bazel files), default (for generic Starlark files) or auto (default, based on the filename) ("auto")
@@ -191,6 +205,7 @@ func ExampleFlagSet_parse() {
 		"--mode=fix",
 		"--multi_diff=true",
 		"--path=pkg/foo",
+		"--profile=default",
 		"-r",
 		"--tables=/path/to/tables.json",
 		"--type=default",
@@ -226,7 +241,8 @@ func ExampleFlagSet_parse() {
 	//   "allowsort": [
 	//     "proto_library.deps",
 	//     "proto_library.srcs"
-	//   ]
+	//   ],
+	//   "profile": "default"
 	// }
 }
 
@@ -246,7 +262,8 @@ func TestValidate(t *testing.T) {
 		"mode d error":          {options: "--mode=diff -d", wantErr: fmt.Errorf("cannot specify both -d and -mode flags")},
 		"mode fix":              {options: "--mode=fix", wantMode: "fix"},
 		"mode print_if_changed": {options: "--mode=print_if_changed", wantMode: "print_if_changed"},
-		"mode error":            {options: "--mode=foo", wantErr: fmt.Errorf("unrecognized mode foo; valid modes are check, diff, fix, print_if_changed")},
+		"mode lsp":              {options: "--mode=lsp", wantMode: "lsp"},
+		"mode error":            {options: "--mode=foo", wantErr: fmt.Errorf("unrecognized mode foo; valid modes are check, diff, fix, print_if_changed, lsp")},
 		"lint not set":          {wantLint: "off"},
 		"lint off":              {options: "--lint=off", wantLint: "off"},
 		"lint warn":             {options: "--lint=warn", wantLint: "warn"},
@@ -255,7 +272,23 @@ func TestValidate(t *testing.T) {
 		"format mode error":     {options: "--mode=fix --format=text", wantErr: fmt.Errorf("cannot specify --format without --mode=check")},
 		"format text":           {options: "--mode=check --format=text"},
 		"format json":           {options: "--mode=check --format=json"},
-		"format error":          {options: "--mode=check --format=foo", wantErr: fmt.Errorf("unrecognized format foo; valid types are text, json")},
+		"format sarif":          {options: "--mode=check --format=sarif"},
+		"baseline":              {options: "--lint=warn --baseline=baseline.json", wantLint: "warn"},
+		"baseline error":        {options: "--baseline=baseline.json", wantErr: fmt.Errorf("--baseline is only compatible with --lint=warn")},
+		"fix only":              {options: "--fix_only=load,integer-division", wantLint: "fix", wantWarnings: []string{"integer-division", "load"}},
+		"fix only lint fix":     {options: "--lint=fix --fix_only=load", wantLint: "fix", wantWarnings: []string{"load"}},
+		"fix only lint error":   {options: "--lint=warn --fix_only=load", wantErr: fmt.Errorf("--fix_only is only compatible with --lint=fix")},
+		"fix only mode error":   {options: "--mode=check --fix_only=load", wantErr: fmt.Errorf("--lint=fix is only compatible with --mode=fix")},
+		"fix only unknown":      {options: "--fix_only=load,foo", wantErr: fmt.Errorf("unrecognized warning \"foo\" for --fix_only")},
+		"lines":                 {options: "--lines=3:5 --mode=check", args: "BUILD", wantMode: "check"},
+		"lines format error":    {options: "--lines=5", wantErr: fmt.Errorf(`invalid --lines range "5", expected START:END with 1 <= START <= END`)},
+		"lines range error":     {options: "--lines=5:3", wantErr: fmt.Errorf(`invalid --lines range "5:3", expected START:END with 1 <= START <= END`)},
+		"lines lint error":      {options: "--lines=3:5 --lint=fix", wantErr: fmt.Errorf("--lines is only compatible with --lint=off or --lint=warn")},
+		"lines files error":     {options: "--lines=3:5", args: "BUILD foo.bzl", wantErr: fmt.Errorf("can only format one file when using --lines flag")},
+		"format error":          {options: "--mode=check --format=foo", wantErr: fmt.Errorf("unrecognized format foo; valid types are text, json, sarif")},
+		"profile build":         {options: "--profile=build"},
+		"profile default":       {options: "--profile=default"},
+		"profile error":         {options: "--profile=foo", wantErr: fmt.Errorf("unrecognized formatting profile foo; valid profiles are build, default")},
 		"type build":            {options: "--type=build"},
 		"type bzl":              {options: "--type=bzl"},
 		"type workspace":        {options: "--type=workspace"},
@@ -279,12 +312,16 @@ func TestValidate(t *testing.T) {
 			"constant-glob",
 			"ctx-actions",
 			"ctx-args",
+			"dangling-label",
 			"deprecated-function",
 			"depset-items",
 			"depset-iteration",
 			"depset-union",
+			"dev-dependency-mismatch",
+			"dev-dependency-registration",
 			"dict-concatenation",
 			"dict-method-named-arg",
+			"duplicate-bazel-dep",
 			"duplicated-name",
 			"external-path",
 			"filetype",
@@ -345,6 +382,7 @@ func TestValidate(t *testing.T) {
 			"native-sh-library",
 			"native-sh-test",
 			"no-effect",
+			"non-root-override",
 			"output-group",
 			"overly-nested-depset",
 			"package-name",
@@ -359,11 +397,16 @@ func TestValidate(t *testing.T) {
 			"skylark-comment",
 			"skylark-docstring",
 			"string-iteration",
+			"target-visibility",
+			"type-annotation-mismatch",
 			"uninitialized",
 			"unnamed-macro",
 			"unreachable",
+			"unsorted-bazel-deps",
 			"unsorted-dict-items",
+			"unused-extension",
 			"unused-variable",
+			"use-repo",
 		}},
 		"warnings default": {options: "--warnings=default", wantWarnings: []string{
 			"allowed-symbol-load-locations",
@@ -381,12 +424,16 @@ func TestValidate(t *testing.T) {
 			"constant-glob",
 			"ctx-actions",
 			"ctx-args",
+			// "dangling-label",
 			"deprecated-function",
 			"depset-items",
 			"depset-iteration",
 			"depset-union",
+			"dev-dependency-mismatch",
+			"dev-dependency-registration",
 			"dict-concatenation",
 			"dict-method-named-arg",
+			"duplicate-bazel-dep",
 			"duplicated-name",
 			"external-path",
 			"filetype",
@@ -447,6 +494,7 @@ func TestValidate(t *testing.T) {
 			"native-sh-library",
 			"native-sh-test",
 			"no-effect",
+			"non-root-override",
 			"output-group",
 			"overly-nested-depset",
 			"package-name",
@@ -461,11 +509,16 @@ func TestValidate(t *testing.T) {
 			"skylark-comment",
 			"skylark-docstring",
 			"string-iteration",
+			// "target-visibility",
+			"type-annotation-mismatch",
 			"uninitialized",
 			"unnamed-macro",
 			"unreachable",
+			// "unsorted-bazel-deps",
 			// "unsorted-dict-items",
+			"unused-extension",
 			"unused-variable",
+			// "use-repo",
 		}},
 		"warnings plus/minus": {options: "--warnings=+unsorted-dict-items,-print,-deprecated-function", wantWarnings: []string{
 			"allowed-symbol-load-locations",
@@ -483,12 +536,16 @@ func TestValidate(t *testing.T) {
 			"constant-glob",
 			"ctx-actions",
 			"ctx-args",
+			// "dangling-label",
 			// "deprecated-function",
 			"depset-items",
 			"depset-iteration",
 			"depset-union",
+			"dev-dependency-mismatch",
+			"dev-dependency-registration",
 			"dict-concatenation",
 			"dict-method-named-arg",
+			"duplicate-bazel-dep",
 			"duplicated-name",
 			"external-path",
 			"filetype",
@@ -549,6 +606,7 @@ func TestValidate(t *testing.T) {
 			"native-sh-library",
 			"native-sh-test",
 			"no-effect",
+			"non-root-override",
 			"output-group",
 			"overly-nested-depset",
 			"package-name",
@@ -563,11 +621,16 @@ func TestValidate(t *testing.T) {
 			"skylark-comment",
 			"skylark-docstring",
 			"string-iteration",
+			// "target-visibility",
+			"type-annotation-mismatch",
 			"uninitialized",
 			"unnamed-macro",
 			"unreachable",
+			// "unsorted-bazel-deps",
 			"unsorted-dict-items",
+			"unused-extension",
 			"unused-variable",
+			// "use-repo",
 		}},
 		"warnings no duplicates": {options: "--warnings=+unused-variable", wantWarnings: []string{
 			"allowed-symbol-load-locations",
@@ -589,8 +652,11 @@ func TestValidate(t *testing.T) {
 			"depset-items",
 			"depset-iteration",
 			"depset-union",
+			"dev-dependency-mismatch",
+			"dev-dependency-registration",
 			"dict-concatenation",
 			"dict-method-named-arg",
+			"duplicate-bazel-dep",
 			"duplicated-name",
 			"external-path",
 			"filetype",
@@ -651,6 +717,7 @@ func TestValidate(t *testing.T) {
 			"native-sh-library",
 			"native-sh-test",
 			"no-effect",
+			"non-root-override",
 			"output-group",
 			"overly-nested-depset",
 			"package-name",
@@ -665,9 +732,11 @@ func TestValidate(t *testing.T) {
 			"skylark-comment",
 			"skylark-docstring",
 			"string-iteration",
+			"type-annotation-mismatch",
 			"uninitialized",
 			"unnamed-macro",
 			"unreachable",
+			"unused-extension",
 			"unused-variable",
 		}},
 		"warnings error": {options: "--warnings=native-py,-print,-deprecated-function", wantErr: fmt.Errorf(`warning categories with modifiers ("+" or "-") can't be mixed with raw warning categories`)},
@@ -710,6 +779,32 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestValidateCustomWarningsError(t *testing.T) {
+	tmp := t.TempDir()
+	starFile := filepath.Join(tmp, "warnings.star")
+	if err := os.WriteFile(starFile, []byte("warning(name = 'foo')\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		customWarnings []string
+		wantErr        string
+	}{
+		"missing file": {customWarnings: []string{"missing.star"}, wantErr: "failed to load custom warnings from " + filepath.Join(tmp, "missing.star")},
+		"invalid file": {customWarnings: []string{"warnings.star"}, wantErr: "missing argument for implementation"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := New()
+			c.ConfigPath = filepath.Join(tmp, ".buildifier.json")
+			c.CustomWarnings = tc.customWarnings
+			got := c.Validate(nil)
+			if got == nil || !strings.Contains(got.Error(), tc.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", got, tc.wantErr)
+			}
+		})
+	}
+}
+
 func TestFindConfigPath(t *testing.T) {
 	for name, tc := range map[string]struct {
 		files map[string]string
@@ -0,0 +1,174 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bazelbuild/buildtools/build"
+)
+
+// builtinProfiles are the formatting profiles that don't need to be defined in the config file.
+var builtinProfiles = map[string]build.FormattingProfile{
+	"build":   build.BuildProfile,
+	"default": build.DefaultProfile,
+}
+
+// Profile is a formatting profile defined in the config file, see build.FormattingProfile.
+// The options that aren't set are taken from the base profile.
+type Profile struct {
+	// Base is the built-in profile the unset options are taken from: build or default
+	// (default based on the file type)
+	Base string `json:"base,omitempty"`
+	// PreserveListLayout keeps top-level lists on a single line if they're on a single line
+	PreserveListLayout *bool `json:"preserveListLayout,omitempty"`
+	// PreserveCallLayout keeps top-level function calls on a single line if they're on a single line
+	PreserveCallLayout *bool `json:"preserveCallLayout,omitempty"`
+	// PreserveQuotes keeps single-quoted strings instead of replacing the quotes with double quotes
+	PreserveQuotes *bool `json:"preserveQuotes,omitempty"`
+	// SeparateStatements separates all top-level statements with blank lines
+	SeparateStatements *bool `json:"separateStatements,omitempty"`
+	// BuildRewrites applies the rewrites of BUILD files instead of the ones of .bzl files
+	BuildRewrites *bool `json:"buildRewrites,omitempty"`
+}
+
+// formattingProfile returns the formatting profile for a file of the given type.
+func (p *Profile) formattingProfile(fileType build.FileType) build.FormattingProfile {
+	profile, ok := builtinProfiles[p.Base]
+	if !ok {
+		profile = build.ProfileForType(fileType)
+	}
+	for _, option := range []struct {
+		value *bool
+		field *bool
+	}{
+		{p.PreserveListLayout, &profile.PreserveListLayout},
+		{p.PreserveCallLayout, &profile.PreserveCallLayout},
+		{p.PreserveQuotes, &profile.PreserveQuotes},
+		{p.SeparateStatements, &profile.SeparateStatements},
+		{p.BuildRewrites, &profile.BuildRewrites},
+	} {
+		if option.value != nil {
+			*option.field = *option.value
+		}
+	}
+	return profile
+}
+
+// validateProfiles checks that the formatting profiles are correctly defined and that
+// only existing profiles are used.
+func (c *Config) validateProfiles() error {
+	for name, profile := range c.Profiles {
+		if _, ok := builtinProfiles[name]; ok {
+			return fmt.Errorf("formatting profile %q is built-in and can't be redefined", name)
+		}
+		if profile == nil {
+			return fmt.Errorf("formatting profile %q is empty", name)
+		}
+		if _, ok := builtinProfiles[profile.Base]; !ok && profile.Base != "" {
+			return fmt.Errorf("unrecognized base profile %s of formatting profile %q; valid base profiles are build, default", profile.Base, name)
+		}
+	}
+	if err := c.validateProfileName(c.Profile); err != nil {
+		return err
+	}
+	for dir, name := range c.ProfileOverrides {
+		if dir == "" {
+			return fmt.Errorf("empty directory in profile overrides")
+		}
+		if err := c.validateProfileName(name); err != nil {
+			return fmt.Errorf("%w for directory %s", err, dir)
+		}
+	}
+	return nil
+}
+
+// validateProfileName checks that a formatting profile exists. An empty name means that
+// the profile depends on the file type.
+func (c *Config) validateProfileName(name string) error {
+	if _, ok := builtinProfiles[name]; ok || name == "" {
+		return nil
+	}
+	if _, ok := c.Profiles[name]; ok {
+		return nil
+	}
+	names := []string{"build", "default"}
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names[2:])
+	return fmt.Errorf("unrecognized formatting profile %s; valid profiles are %s", name, strings.Join(names, ", "))
+}
+
+// FormattingProfile returns the formatting profile of a file of the given type, or nil if
+// the default profile of the file type should be used. The profile of the longest
+// directory in ProfileOverrides that contains the file is used, otherwise the profile
+// set by the Profile option. The config should be validated first.
+func (c *Config) FormattingProfile(filename string, fileType build.FileType) *build.FormattingProfile {
+	name := c.Profile
+	if dir := c.profileOverrideDir(filename); dir != "" {
+		name = c.ProfileOverrides[dir]
+	}
+	if name == "" {
+		return nil
+	}
+	if profile, ok := builtinProfiles[name]; ok {
+		return &profile
+	}
+	profile := c.Profiles[name].formattingProfile(fileType)
+	return &profile
+}
+
+// profileOverrideDir returns the longest directory of ProfileOverrides that contains
+// the file, or an empty string if there's none. Relative directories are resolved
+// against the directory of the config file.
+func (c *Config) profileOverrideDir(filename string) string {
+	if filename == "" || len(c.ProfileOverrides) == 0 {
+		return ""
+	}
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return ""
+	}
+	configDir := ""
+	if c.ConfigPath != "" {
+		configDir = filepath.Dir(c.ConfigPath)
+	}
+
+	longest, longestLen := "", -1
+	for dir := range c.ProfileOverrides {
+		absDir := filepath.FromSlash(dir)
+		if !filepath.IsAbs(absDir) {
+			absDir = filepath.Join(configDir, absDir)
+		}
+		absDir, err := filepath.Abs(absDir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(absDir, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(absDir) > longestLen {
+			longest, longestLen = dir, len(absDir)
+		}
+	}
+	return longest
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bazelbuild/buildtools/build"
+)
+
+const profilesConfig = `{
+  "profile": "quoted",
+  "profiles": {
+    "quoted": {
+      "preserveQuotes": true
+    },
+    "vendored": {
+      "base": "default",
+      "separateStatements": true,
+      "buildRewrites": true
+    }
+  },
+  "profileOverrides": {
+    "third_party": "vendored",
+    "third_party/ours": "build",
+    "/abs/generated": "default"
+  }
+}`
+
+func TestFormattingProfile(t *testing.T) {
+	tmp := t.TempDir()
+	c := New()
+	c.ConfigPath = filepath.Join(tmp, ".buildifier.json")
+	if err := c.LoadReader(strings.NewReader(profilesConfig)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	quotedBuild := build.BuildProfile
+	quotedBuild.PreserveQuotes = true
+	quotedBzl := build.DefaultProfile
+	quotedBzl.PreserveQuotes = true
+	vendored := build.DefaultProfile
+	vendored.SeparateStatements = true
+	vendored.BuildRewrites = true
+
+	for _, tc := range []struct {
+		filename string
+		fileType build.FileType
+		want     *build.FormattingProfile
+	}{
+		{filename: filepath.Join(tmp, "BUILD"), fileType: build.TypeBuild, want: &quotedBuild},
+		{filename: filepath.Join(tmp, "foo.bzl"), fileType: build.TypeBzl, want: &quotedBzl},
+		{filename: "", fileType: build.TypeBuild, want: &quotedBuild},
+		{filename: filepath.Join(tmp, "third_party", "BUILD"), fileType: build.TypeBuild, want: &vendored},
+		{filename: filepath.Join(tmp, "third_party", "foo", "foo.bzl"), fileType: build.TypeBzl, want: &vendored},
+		{filename: filepath.Join(tmp, "third_party", "ours", "BUILD"), fileType: build.TypeBuild, want: &build.BuildProfile},
+		{filename: filepath.Join(tmp, "third_party_other", "BUILD"), fileType: build.TypeBuild, want: &quotedBuild},
+		{filename: filepath.FromSlash("/abs/generated/BUILD"), fileType: build.TypeBuild, want: &build.DefaultProfile},
+	} {
+		got := c.FormattingProfile(tc.filename, tc.fileType)
+		if got == nil || *got != *tc.want {
+			t.Errorf("FormattingProfile(%q, %v) = %+v, want %+v", tc.filename, tc.fileType, got, tc.want)
+		}
+	}
+
+	c.Profile = ""
+	if got := c.FormattingProfile(filepath.Join(tmp, "BUILD"), build.TypeBuild); got != nil {
+		t.Errorf("FormattingProfile() without a profile = %+v, want nil", got)
+	}
+}
+
+func TestValidateProfiles(t *testing.T) {
+	for name, tc := range map[string]struct {
+		config  string
+		wantErr string
+	}{
+		"valid": {
+			config: profilesConfig,
+		},
+		"redefined built-in profile": {
+			config:  `{"profiles": {"build": {"preserveQuotes": true}}}`,
+			wantErr: `formatting profile "build" is built-in and can't be redefined`,
+		},
+		"unknown base": {
+			config:  `{"profiles": {"foo": {"base": "bzl"}}}`,
+			wantErr: `unrecognized base profile bzl of formatting profile "foo"; valid base profiles are build, default`,
+		},
+		"unknown profile": {
+			config:  `{"profile": "bar", "profiles": {"foo": {}}}`,
+			wantErr: "unrecognized formatting profile bar; valid profiles are build, default, foo",
+		},
+		"unknown override profile": {
+			config:  `{"profileOverrides": {"third_party": "foo"}}`,
+			wantErr: "unrecognized formatting profile foo; valid profiles are build, default for directory third_party",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := New()
+			if err := c.LoadReader(strings.NewReader(tc.config)); err != nil {
+				t.Fatal(err)
+			}
+			err := c.Validate(nil)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("Validate() = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
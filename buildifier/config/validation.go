@@ -40,13 +40,13 @@ func ValidateFormat(format, mode *string) error {
 	case "":
 		return nil
 
-	case "text", "json":
+	case "text", "json", "sarif":
 		if *mode != "check" {
 			return fmt.Errorf("cannot specify --format without --mode=check")
 		}
 
 	default:
-		return fmt.Errorf("unrecognized format %s; valid types are text, json", *format)
+		return fmt.Errorf("unrecognized format %s; valid types are text, json, sarif", *format)
 	}
 	return nil
 }
@@ -148,3 +148,17 @@ func ValidateWarnings(warnings *string, allWarnings, defaultWarnings *[]string)
 	slices.Sort(ws)
 	return ws, nil
 }
+
+// ValidateFixOnly validates the value of the --fix_only flag
+func ValidateFixOnly(fixOnly *string, allWarnings *[]string) ([]string, error) {
+	warningsMap := make(map[string]bool)
+	for _, warning := range strings.Split(*fixOnly, ",") {
+		if !slices.Contains(*allWarnings, warning) {
+			return []string{}, fmt.Errorf("unrecognized warning %q for --fix_only", warning)
+		}
+		warningsMap[warning] = true
+	}
+	ws := slices.Collect(maps.Keys(warningsMap))
+	slices.Sort(ws)
+	return ws, nil
+}
@@ -654,6 +654,36 @@ diff -u json_report ../../golden/json_report_invalid_file_golden || die "$1: wro
 
 cd ../..
 
+# Test that all syntax errors are reported and the valid statements are linted
+
+cat > test_dir/invalid.bzl <<EOF
+a = 1 / 2
+
+x = [
+
+def f():
+    return )
+
+b = 3 / 4
+EOF
+cp test_dir/invalid.bzl golden/invalid.bzl
+
+cat > golden/invalid_report_golden <<EOF
+test_dir/invalid.bzl:4:1: syntax error
+test_dir/invalid.bzl:6:13: syntax error near )
+test_dir/invalid.bzl:1: integer-division: The "/" operator for integer division is deprecated in favor of "//". (https://github.com/bazelbuild/buildtools/blob/main/WARNINGS.md#integer-division)
+test_dir/invalid.bzl:8: integer-division: The "/" operator for integer division is deprecated in favor of "//". (https://github.com/bazelbuild/buildtools/blob/main/WARNINGS.md#integer-division)
+test_dir/invalid.bzl # reformat
+EOF
+
+ret=0
+$buildifier --lint=warn --warnings=integer-division test_dir/invalid.bzl 2> test_dir/invalid_report || ret=$?
+if [[ $ret -ne 1 ]]; then
+  die "$1: buildifier --lint=warn with syntax errors: expected exit code 1, got $ret"
+fi
+diff -u golden/invalid_report_golden test_dir/invalid_report || die "$1: wrong console output for --lint=warn with syntax errors"
+diff -u golden/invalid.bzl test_dir/invalid.bzl || die "$1: a file with syntax errors has been modified"
+
 # Test the multifile functionality
 
 mkdir multifile
@@ -725,3 +755,40 @@ $buildifier --lint=warn --warnings=allowed-symbol-load-locations -tables=buildif
 diff -u report_golden report || die "$1: wrong console output for allowed symbol load locations"
 
 cd ../..
+
+# Test formatting profiles
+
+mkdir -p test_dir/profiles/third_party
+cd test_dir/profiles
+
+cat > .buildifier.json <<EOF
+{
+  "profileOverrides": {
+    "third_party": "default"
+  }
+}
+EOF
+
+input='cc_library(name = "a", srcs = ["a.cc", "b.cc"])
+cc_library(name = "b")'
+echo "$input" > BUILD
+echo "$input" > third_party/BUILD
+
+cat > BUILD.golden <<EOF
+cc_library(
+    name = "a",
+    srcs = [
+        "a.cc",
+        "b.cc",
+    ],
+)
+
+cc_library(name = "b")
+EOF
+echo "$input" > third_party/BUILD.golden
+
+$buildifier --config=.buildifier.json BUILD third_party/BUILD
+diff -u BUILD.golden BUILD || die "$1: wrong formatting with the build profile"
+diff -u third_party/BUILD.golden third_party/BUILD || die "$1: wrong formatting with a profile override"
+
+cd ../..
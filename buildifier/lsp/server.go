@@ -0,0 +1,420 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lsp implements a Language Server Protocol server for buildifier.
+// The server communicates over a pair of streams (usually stdin and stdout)
+// and provides formatting, lint diagnostics and quick fixes for the
+// auto-fixable warnings.
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/buildifier/utils"
+	"github.com/bazelbuild/buildtools/warn"
+	"github.com/bazelbuild/buildtools/wspace"
+)
+
+// A document is a file opened in the editor.
+type document struct {
+	uri  string
+	path string // absolute file path, empty if the URI doesn't use the file scheme
+	text []byte
+	file *build.File        // the valid statements of the text if it has syntax errors
+	errs []build.ParseError // the syntax errors, if any
+}
+
+// Server is a language server that keeps the open documents parsed in memory.
+type Server struct {
+	parser           func(filename string, data []byte) (*build.File, error)
+	recoveringParser func(filename string, data []byte) (*build.File, []build.ParseError)
+	warnings         []string
+	profile          func(filename string, fileType build.FileType) *build.FormattingProfile
+
+	docs    map[string]*document
+	readers map[string]*warn.FileReader // file readers shared by the documents of a workspace
+
+	out      io.Writer
+	shutdown bool
+}
+
+// NewServer creates a language server. The input type has the same meaning
+// as the buildifier -type flag, and warnings is the list of enabled warnings.
+// The optional profile function returns the formatting profile of a file,
+// nil means that the default profile of the file type is used.
+func NewServer(inputType string, warnings []string, profile func(filename string, fileType build.FileType) *build.FormattingProfile) *Server {
+	return &Server{
+		parser:           utils.GetParser(inputType),
+		recoveringParser: utils.GetRecoveringParser(inputType),
+		warnings:         warnings,
+		profile:          profile,
+		docs:             make(map[string]*document),
+		readers:          make(map[string]*warn.FileReader),
+	}
+}
+
+// Serve reads requests from in and writes responses and notifications to out
+// until the client sends the exit notification or in is closed.
+// Returns nil if the client has properly shut down the server.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	r := bufio.NewReader(in)
+	for {
+		req, err := readRequest(r)
+		if err != nil {
+			var rerr *responseError
+			if errors.As(err, &rerr) {
+				if err := writeMessage(s.out, &response{JSONRPC: "2.0", Error: rerr}); err != nil {
+					return err
+				}
+				continue
+			}
+			if err == io.EOF {
+				return fmt.Errorf("unexpected end of input")
+			}
+			return err
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("exit notification received before shutdown")
+			}
+			return nil
+		}
+		result, rerr := s.handle(req)
+		if req.ID == nil {
+			// Notifications have no responses
+			continue
+		}
+		resp := &response{JSONRPC: "2.0", ID: req.ID, Error: rerr}
+		if rerr == nil {
+			if resp.Result, err = json.Marshal(result); err != nil {
+				return err
+			}
+		}
+		if err := writeMessage(s.out, resp); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or a notification to its handler.
+func (s *Server) handle(req *request) (interface{}, *responseError) {
+	var err error
+	switch req.Method {
+	case "initialize":
+		return s.initialize(), nil
+	case "initialized", "$/cancelRequest", "$/setTrace", "textDocument/willSave":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			err = s.update(params.TextDocument.URI, []byte(params.TextDocument.Text))
+		}
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err = json.Unmarshal(req.Params, &params); err == nil && len(params.ContentChanges) > 0 {
+			// Only full document synchronization is supported, the last change contains the whole text
+			text := params.ContentChanges[len(params.ContentChanges)-1].Text
+			err = s.update(params.TextDocument.URI, []byte(text))
+		}
+	case "textDocument/didSave":
+		var params didSaveParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			s.invalidate(s.docs[params.TextDocument.URI])
+		}
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			err = s.close(params.TextDocument.URI)
+		}
+	case "textDocument/formatting":
+		var params formattingParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			return s.format(params.TextDocument.URI), nil
+		}
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			return s.codeActions(params.TextDocument.URI, params.Range), nil
+		}
+	default:
+		return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not supported: %s", req.Method)}
+	}
+	if err != nil {
+		return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil, nil
+}
+
+func (s *Server) initialize() interface{} {
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"textDocumentSync": map[string]interface{}{
+				"openClose": true,
+				"change":    1, // full
+				"save":      true,
+			},
+			"documentFormattingProvider": true,
+			"codeActionProvider": map[string]interface{}{
+				"codeActionKinds": []string{"quickfix"},
+			},
+		},
+		"serverInfo": map[string]string{
+			"name": "buildifier",
+		},
+	}
+}
+
+// filePath converts a document URI to an absolute file path.
+// Returns an empty string for non-file URIs.
+func filePath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	path := u.Path
+	// file:///C:/foo/BUILD on Windows
+	if len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path)
+}
+
+// parse parses the document text and sets up the workspace related fields and the
+// formatting profile of the AST.
+func (s *Server) parse(doc *document) (*build.File, error) {
+	f, err := s.parser(doc.path, doc.text)
+	if err != nil {
+		return nil, err
+	}
+	if doc.path != "" {
+		f.WorkspaceRoot, f.Pkg, f.Label = wspace.SplitFilePath(doc.path)
+	}
+	if s.profile != nil {
+		f.Profile = s.profile(doc.path, f.Type)
+	}
+	return f, nil
+}
+
+// update stores the new text of a document and publishes its diagnostics.
+// Documents with syntax errors are parsed as far as possible, so that the
+// warnings of their valid parts can still be reported while the user is typing.
+func (s *Server) update(uri string, text []byte) error {
+	doc := &document{uri: uri, path: filePath(uri), text: text}
+	doc.file, doc.errs = s.recoveringParser(doc.path, doc.text)
+	if doc.path != "" {
+		doc.file.WorkspaceRoot, doc.file.Pkg, doc.file.Label = wspace.SplitFilePath(doc.path)
+	}
+	s.docs[uri] = doc
+	s.invalidate(doc)
+	return s.publishDiagnostics(doc)
+}
+
+// close forgets a document and clears its diagnostics.
+func (s *Server) close(uri string) error {
+	doc, ok := s.docs[uri]
+	if !ok {
+		return nil
+	}
+	delete(s.docs, uri)
+	s.invalidate(doc)
+	return writeMessage(s.out, &notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  &publishDiagnosticsParams{URI: uri, Diagnostics: []diagnostic{}},
+	})
+}
+
+// invalidate drops the cached files of the workspace a document belongs to,
+// they may be read by multi-file warnings of other files. BUILD files are read
+// to check the visibility of targets, and the BUILD files of external
+// repositories are found in the WORKSPACE file.
+func (s *Server) invalidate(doc *document) {
+	if doc == nil {
+		return
+	}
+	delete(s.readers, doc.file.WorkspaceRoot)
+}
+
+// fileReader returns the file reader shared by all documents of a workspace.
+// Open documents are read from memory, other files from the disk.
+func (s *Server) fileReader(workspaceRoot string) *warn.FileReader {
+	if workspaceRoot == "" {
+		return nil
+	}
+	if fr, ok := s.readers[workspaceRoot]; ok {
+		return fr
+	}
+	fr := warn.NewFileReader(func(filename string) ([]byte, error) {
+		path := filepath.Join(workspaceRoot, filepath.FromSlash(filename))
+		for _, doc := range s.docs {
+			if doc.path == path {
+				return doc.text, nil
+			}
+		}
+		return os.ReadFile(path)
+	})
+	fr.SetFileLister(func() ([]string, error) {
+		return wspace.FindStarlarkFiles(workspaceRoot)
+	})
+	fr.SetPathChecker(func(filename string) bool {
+		path := filepath.Join(workspaceRoot, filepath.FromSlash(filename))
+		for _, doc := range s.docs {
+			if doc.path == path {
+				return true
+			}
+		}
+		_, err := os.Stat(path)
+		return err == nil
+	})
+	s.readers[workspaceRoot] = fr
+	return fr
+}
+
+// findings returns the warnings for a parsed file.
+func (s *Server) findings(f *build.File, mode warn.LintMode, formatted *[]byte) []*warn.Finding {
+	return warn.FileWarnings(f, s.warnings, formatted, mode, s.fileReader(f.WorkspaceRoot))
+}
+
+// makeDiagnostic converts a finding into an LSP diagnostic.
+func makeDiagnostic(text []byte, w *warn.Finding) diagnostic {
+	d := diagnostic{
+		Range: textRange{
+			Start: positionAt(text, w.Start.Byte),
+			End:   positionAt(text, w.End.Byte),
+		},
+		Severity: severityWarning,
+		Code:     w.Category,
+		Source:   "buildifier",
+		Message:  w.Message,
+	}
+	if w.URL != "" {
+		d.CodeDescription = &codeDescription{Href: w.URL}
+	}
+	return d
+}
+
+// diagnostics returns the syntax errors and the lint warnings of a document.
+func (s *Server) diagnostics(doc *document) []diagnostic {
+	diagnostics := []diagnostic{}
+	for _, perr := range doc.errs {
+		pos := positionAt(doc.text, perr.Pos.Byte)
+		diagnostics = append(diagnostics, diagnostic{
+			Range:    textRange{Start: pos, End: pos},
+			Severity: severityError,
+			Source:   "buildifier",
+			Message:  perr.Message,
+		})
+	}
+	for _, w := range s.findings(doc.file, warn.ModeWarn, nil) {
+		diagnostics = append(diagnostics, makeDiagnostic(doc.text, w))
+	}
+	return diagnostics
+}
+
+func (s *Server) publishDiagnostics(doc *document) error {
+	return writeMessage(s.out, &notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  &publishDiagnosticsParams{URI: doc.uri, Diagnostics: s.diagnostics(doc)},
+	})
+}
+
+// format returns the edits needed to format a document.
+func (s *Server) format(uri string) []textEdit {
+	edits := []textEdit{}
+	doc, ok := s.docs[uri]
+	if !ok || len(doc.errs) > 0 {
+		return edits
+	}
+	// Formatting rewrites the AST, so a fresh copy is needed to keep the cached one intact.
+	f, err := s.parse(doc)
+	if err != nil {
+		return edits
+	}
+	formatted := build.Format(f)
+	if bytes.Equal(formatted, doc.text) {
+		return edits
+	}
+	return append(edits, textEdit{Range: fullRange(doc.text), NewText: string(formatted)})
+}
+
+// codeActions returns quick fixes for the auto-fixable warnings within a range.
+func (s *Server) codeActions(uri string, rng textRange) []codeAction {
+	actions := []codeAction{}
+	doc, ok := s.docs[uri]
+	if !ok || len(doc.errs) > 0 {
+		return actions
+	}
+	// Replacements are calculated against the formatted file content.
+	f, err := s.parse(doc)
+	if err != nil {
+		return actions
+	}
+	formatted := build.Format(f)
+	if f, err = s.parse(doc); err != nil {
+		return actions
+	}
+	alreadyFormatted := bytes.Equal(formatted, doc.text)
+
+	for _, w := range s.findings(f, warn.ModeSuggest, &formatted) {
+		if w.Replacement == nil {
+			continue
+		}
+		d := makeDiagnostic(doc.text, w)
+		if !d.Range.overlaps(rng) {
+			continue
+		}
+		r := w.Replacement
+		var edit textEdit
+		if alreadyFormatted {
+			edit = textEdit{
+				Range: textRange{
+					Start: positionAt(doc.text, r.Start),
+					End:   positionAt(doc.text, r.End),
+				},
+				NewText: r.Content,
+			}
+		} else {
+			// The replacement offsets don't match the document, replace it as a whole.
+			var newText strings.Builder
+			newText.Write(formatted[:r.Start])
+			newText.WriteString(r.Content)
+			newText.Write(formatted[r.End:])
+			edit = textEdit{Range: fullRange(doc.text), NewText: newText.String()}
+		}
+		actions = append(actions, codeAction{
+			Title:       fmt.Sprintf("Fix %q warning", w.Category),
+			Kind:        "quickfix",
+			Diagnostics: []diagnostic{d},
+			Edit:        &workspaceEdit{Changes: map[string][]textEdit{uri: {edit}}},
+		})
+	}
+	return actions
+}
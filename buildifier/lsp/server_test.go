@@ -0,0 +1,238 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// session builds the input stream of a client session.
+type session struct {
+	bytes.Buffer
+	nextID int
+}
+
+func (s *session) send(method string, params interface{}, isRequest bool) {
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	}
+	if isRequest {
+		s.nextID++
+		msg["id"] = s.nextID
+	}
+	data, _ := json.Marshal(msg)
+	fmt.Fprintf(s, "Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+func TestServer(t *testing.T) {
+	uri := "untitled:BUILD"
+	text := `load(":foo.bzl", "bar")
+
+cc_library(name="lib")
+`
+	in := &session{}
+	in.send("initialize", map[string]interface{}{}, true)
+	in.send("initialized", map[string]interface{}{}, false)
+	in.send("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "starlark", "version": 1, "text": text},
+	}, false)
+	in.send("textDocument/formatting", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+	}, true)
+	in.send("textDocument/codeAction", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"range":        map[string]interface{}{"start": map[string]int{"line": 0, "character": 0}, "end": map[string]int{"line": 1, "character": 0}},
+	}, true)
+	in.send("foo/bar", map[string]interface{}{}, true)
+	in.send("shutdown", nil, true)
+	in.send("exit", nil, false)
+
+	out := &bytes.Buffer{}
+	server := NewServer("build", []string{"load"}, nil)
+	if err := server.Serve(in, out); err != nil {
+		t.Fatalf("Serve() = %v, want nil", err)
+	}
+
+	messages := splitMessages(t, out.Bytes())
+	if len(messages) != 6 {
+		t.Fatalf("got %d messages, want 6:\n%s", len(messages), out.String())
+	}
+
+	// initialize
+	if !strings.Contains(messages[0], `"documentFormattingProvider":true`) {
+		t.Errorf("initialize: unexpected response %s", messages[0])
+	}
+
+	// didOpen
+	var diagnostics struct {
+		Method string
+		Params publishDiagnosticsParams
+	}
+	if err := json.Unmarshal([]byte(messages[1]), &diagnostics); err != nil {
+		t.Fatal(err)
+	}
+	if diagnostics.Method != "textDocument/publishDiagnostics" || len(diagnostics.Params.Diagnostics) != 1 {
+		t.Fatalf("didOpen: unexpected notification %s", messages[1])
+	}
+	d := diagnostics.Params.Diagnostics[0]
+	if d.Code != "load" || d.Range.Start != (position{0, 18}) || d.Range.End != (position{0, 21}) {
+		t.Errorf("didOpen: unexpected diagnostic %+v", d)
+	}
+
+	// formatting
+	var formatting struct{ Result []textEdit }
+	if err := json.Unmarshal([]byte(messages[2]), &formatting); err != nil {
+		t.Fatal(err)
+	}
+	wantFormatted := `load(":foo.bzl", "bar")
+
+cc_library(name = "lib")
+`
+	if len(formatting.Result) != 1 || formatting.Result[0].NewText != wantFormatted || formatting.Result[0].Range.End != (position{3, 0}) {
+		t.Errorf("formatting: unexpected response %s", messages[2])
+	}
+
+	// codeAction
+	var actions struct{ Result []codeAction }
+	if err := json.Unmarshal([]byte(messages[3]), &actions); err != nil {
+		t.Fatal(err)
+	}
+	wantFixed := `cc_library(name = "lib")
+`
+	if len(actions.Result) != 1 {
+		t.Fatalf("codeAction: unexpected response %s", messages[3])
+	}
+	edits := actions.Result[0].Edit.Changes[uri]
+	if len(edits) != 1 || edits[0].NewText != wantFixed {
+		t.Errorf("codeAction: unexpected edits %+v", edits)
+	}
+
+	// unknown method
+	if !strings.Contains(messages[4], fmt.Sprintf(`"code":%d`, codeMethodNotFound)) {
+		t.Errorf("foo/bar: unexpected response %s", messages[4])
+	}
+
+	// shutdown
+	if !strings.Contains(messages[5], `"result":null`) {
+		t.Errorf("shutdown: unexpected response %s", messages[5])
+	}
+}
+
+func TestServerSyntaxErrors(t *testing.T) {
+	uri := "untitled:BUILD"
+	text := `load(":foo.bzl", "bar")
+
+cc_library(
+    name = "lib",
+    srcs = [
+
+cc_library(name = "lib2", deps = [)
+`
+	in := &session{}
+	in.send("initialize", map[string]interface{}{}, true)
+	in.send("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "starlark", "version": 1, "text": text},
+	}, false)
+	in.send("textDocument/formatting", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+	}, true)
+	in.send("shutdown", nil, true)
+	in.send("exit", nil, false)
+
+	out := &bytes.Buffer{}
+	if err := NewServer("build", []string{"load"}, nil).Serve(in, out); err != nil {
+		t.Fatalf("Serve() = %v, want nil", err)
+	}
+	messages := splitMessages(t, out.Bytes())
+	if len(messages) != 4 {
+		t.Fatalf("got %d messages, want 4:\n%s", len(messages), out.String())
+	}
+
+	// didOpen: both syntax errors and the warnings of the valid statements are reported
+	var diagnostics struct {
+		Params publishDiagnosticsParams
+	}
+	if err := json.Unmarshal([]byte(messages[1]), &diagnostics); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range diagnostics.Params.Diagnostics {
+		got = append(got, fmt.Sprintf("%d:%d %d %s", d.Range.Start.Line, d.Range.Start.Character, d.Severity, strings.Split(d.Message, "\n")[0]))
+	}
+	want := []string{
+		"5:0 1 syntax error",
+		"6:35 1 syntax error near )",
+		`0:18 2 Loaded symbol "bar" is unused. Please remove it.`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("didOpen: got diagnostics\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// formatting: files with syntax errors aren't formatted
+	if !strings.Contains(messages[2], `"result":[]`) {
+		t.Errorf("formatting: unexpected response %s", messages[2])
+	}
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	in := &session{}
+	in.send("exit", nil, false)
+	if err := NewServer("build", nil, nil).Serve(in, &bytes.Buffer{}); err == nil {
+		t.Errorf("Serve() = nil, want error")
+	}
+}
+
+func TestPositionAt(t *testing.T) {
+	text := []byte("a = 1\nb = \"é\U0001F600\"\n")
+	for _, tc := range []struct {
+		offset int
+		want   position
+	}{
+		{0, position{0, 0}},
+		{5, position{0, 5}},
+		{6, position{1, 0}},
+		{13, position{1, 6}},  // after "é" (2 bytes, 1 code unit)
+		{17, position{1, 8}},  // after the emoji (4 bytes, 2 code units)
+		{100, position{2, 0}}, // past the end
+	} {
+		if got := positionAt(text, tc.offset); got != tc.want {
+			t.Errorf("positionAt(%d) = %+v, want %+v", tc.offset, got, tc.want)
+		}
+	}
+}
+
+// splitMessages splits the server output into message payloads.
+func splitMessages(t *testing.T, out []byte) []string {
+	var messages []string
+	for len(out) > 0 {
+		var length int
+		n, err := fmt.Sscanf(string(out), "Content-Length: %d\r\n\r\n", &length)
+		if n != 1 || err != nil {
+			t.Fatalf("can't parse server output %q: %v", out, err)
+		}
+		start := bytes.Index(out, []byte("\r\n\r\n")) + 4
+		messages = append(messages, string(out[start:start+length]))
+		out = out[start+length:]
+	}
+	return messages
+}
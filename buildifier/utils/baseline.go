@@ -0,0 +1,152 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/warn"
+)
+
+// Baseline is a set of known findings that shouldn't be reported. Findings are
+// identified by a fingerprint of the offending code rather than by their
+// position, so that unrelated changes in a file don't invalidate the baseline.
+type Baseline struct {
+	Findings []*BaselineFinding `json:"findings"`
+	counts   map[BaselineFinding]int
+}
+
+// BaselineFinding is a single known finding.
+type BaselineFinding struct {
+	File        string `json:"file"`
+	Category    string `json:"category"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// NewBaseline returns an empty baseline.
+func NewBaseline() *Baseline {
+	return &Baseline{
+		Findings: []*BaselineFinding{},
+		counts:   make(map[BaselineFinding]int),
+	}
+}
+
+// LoadBaseline reads a baseline file.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b := NewBaseline()
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("parsing baseline %s: %v", path, err)
+	}
+	for _, finding := range b.Findings {
+		b.counts[*finding]++
+	}
+	return b, nil
+}
+
+// Write writes the baseline to a file.
+func (b *Baseline) Write(path string) error {
+	sort.SliceStable(b.Findings, func(i, j int) bool {
+		x, y := b.Findings[i], b.Findings[j]
+		if x.File != y.File {
+			return x.File < y.File
+		}
+		if x.Category != y.Category {
+			return x.Category < y.Category
+		}
+		return x.Fingerprint < y.Fingerprint
+	})
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Add records the findings of a file with the given contents.
+func (b *Baseline) Add(f *build.File, data []byte, findings []*warn.Finding) {
+	for _, finding := range findings {
+		key := makeBaselineFinding(f, data, finding)
+		b.Findings = append(b.Findings, &key)
+		b.counts[key]++
+	}
+}
+
+// Filter returns the findings of a file with the given contents that are not
+// in the baseline. If the same code has several findings of the same category,
+// only those exceeding the number of known findings are returned.
+func (b *Baseline) Filter(f *build.File, data []byte, findings []*warn.Finding) []*warn.Finding {
+	seen := make(map[BaselineFinding]int)
+	var result []*warn.Finding
+	for _, finding := range findings {
+		key := makeBaselineFinding(f, data, finding)
+		seen[key]++
+		if seen[key] > b.counts[key] {
+			result = append(result, finding)
+		}
+	}
+	return result
+}
+
+// baselinePath returns the path of a file used in the baseline: relative to
+// the workspace root if it's known, so that the baseline doesn't depend on
+// the working directory.
+func baselinePath(f *build.File) string {
+	if f.WorkspaceRoot == "" {
+		return f.DisplayPath()
+	}
+	return strings.TrimPrefix(f.CanonicalPath(), "//")
+}
+
+func makeBaselineFinding(f *build.File, data []byte, finding *warn.Finding) BaselineFinding {
+	return BaselineFinding{
+		File:        baselinePath(f),
+		Category:    finding.Category,
+		Fingerprint: Fingerprint(data, finding),
+	}
+}
+
+// Fingerprint returns a stable identifier of a finding in a file with the given
+// contents. It's based on the category and the source code of the offending
+// node with all whitespace removed, so that it doesn't change if the file is
+// reformatted or if the node is moved to another line.
+func Fingerprint(data []byte, finding *warn.Finding) string {
+	start, end := finding.Start.Byte, finding.End.Byte
+	if start < 0 || end > len(data) || start > end {
+		start, end = 0, 0
+	}
+	code := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, string(data[start:end]))
+
+	hash := sha256.Sum256([]byte(finding.Category + "\x00" + code))
+	return hex.EncodeToString(hash[:8])
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/warn"
+)
+
+func lintForBaseline(t *testing.T, data string) (*build.File, []byte, []*warn.Finding) {
+	f, err := build.ParseBzl("pkg/foo.bzl", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings := []string{"integer-division", "print"}
+	return f, []byte(data), Lint(f, "warn", &warnings, false)
+}
+
+func TestBaseline(t *testing.T) {
+	f, data, findings := lintForBaseline(t, `x = 5 / 2
+y = 7 / 3
+`)
+	baseline := NewBaseline()
+	baseline.Add(f, data, findings)
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := baseline.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(baseline.Findings) != 2 {
+		t.Fatalf("got %d findings in the baseline, want 2", len(baseline.Findings))
+	}
+
+	// Moving and reformatting the known findings doesn't make them new,
+	// but another occurrence of the same code does.
+	f, data, findings = lintForBaseline(t, `print("foo")
+
+y = 7/3
+
+x = 5 /   2
+
+z = 5 / 2
+`)
+	var got []string
+	for _, finding := range baseline.Filter(f, data, findings) {
+		got = append(got, finding.Category+":"+string(data[finding.Start.Byte:finding.End.Byte]))
+	}
+	want := []string{"print:print(\"foo\")", "integer-division:5 / 2"}
+	if len(got) != len(want) {
+		t.Fatalf("Filter() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Filter()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if got, want := findings[len(findings)-1].Start.Line, 7; got != want {
+		t.Errorf("the new finding is on line %d, want %d", got, want)
+	}
+}
+
+func TestLoadBaselineError(t *testing.T) {
+	if _, err := LoadBaseline(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("LoadBaseline() for a missing file: got no error")
+	}
+}
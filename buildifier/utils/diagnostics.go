@@ -30,7 +30,7 @@ type Diagnostics struct {
 	Files   []*FileDiagnostics `json:"files"`   // diagnostics per file
 }
 
-// Format formats a Diagnostics object either as plain text or as json
+// Format formats a Diagnostics object either as plain text, as json or as SARIF
 func (d *Diagnostics) Format(format string, verbose bool) string {
 	switch format {
 	case "text", "":
@@ -41,6 +41,10 @@ func (d *Diagnostics) Format(format string, verbose bool) string {
 				if !w.Actionable {
 					formatString = "%s:%d: %s: %s [%s]\n"
 				}
+				if w.URL == "" {
+					// Registered warnings may have no documentation
+					formatString = "%s:%d: %s: %s%s\n"
+				}
 				output.WriteString(fmt.Sprintf(formatString,
 					f.Filename,
 					w.Start.Line,
@@ -61,6 +65,8 @@ func (d *Diagnostics) Format(format string, verbose bool) string {
 			result, _ = json.Marshal(*d)
 		}
 		return string(result) + "\n"
+	case "sarif":
+		return formatSarif(d, verbose)
 	}
 	return ""
 }
@@ -81,6 +87,8 @@ type warning struct {
 	AutoFixable bool     `json:"autoFixable"`
 	Message     string   `json:"message"`
 	URL         string   `json:"url"`
+	// fix is a suggested fix for the warning, it's only exposed in the SARIF format
+	fix *warn.Replacement
 }
 
 type position struct {
@@ -121,6 +129,7 @@ func NewFileDiagnostics(filename string, warnings []*warn.Finding) *FileDiagnost
 			AutoFixable: w.AutoFixable,
 			Message:     w.Message,
 			URL:         w.URL,
+			fix:         w.Replacement,
 		})
 	}
 
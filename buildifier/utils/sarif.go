@@ -0,0 +1,224 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+// Diagnostics output in the SARIF 2.1.0 format:
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+import (
+	"encoding/json"
+	"path/filepath"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool          `json:"tool"`
+	Invocations []sarifInvocation  `json:"invocations"`
+	ColumnKind  string             `json:"columnKind"`
+	Results     []sarifResult      `json:"results"`
+	Artifacts   []sarifArtifactRef `json:"artifacts,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID      string `json:"id"`
+	HelpURI string `json:"helpUri,omitempty"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	Fixes     []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifArtifactRef struct {
+	Location sarifArtifactLocation `json:"location"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+	// Byte offsets are used for replacements only
+	ByteOffset *int `json:"byteOffset,omitempty"`
+	ByteLength *int `json:"byteLength,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion   `json:"deletedRegion"`
+	InsertedContent *sarifMessage `json:"insertedContent,omitempty"`
+}
+
+// sarifURI converts a file name to a URI reference.
+func sarifURI(filename string) string {
+	return filepath.ToSlash(filename)
+}
+
+// formatSarif formats a Diagnostics object as a SARIF log with a single run.
+func formatSarif(d *Diagnostics, verbose bool) string {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "buildifier",
+			InformationURI: "https://github.com/bazelbuild/buildtools/tree/main/buildifier",
+			Rules:          []sarifRule{},
+		}},
+		// Columns are counted in runes
+		ColumnKind: "unicodeCodePoints",
+		Results:    []sarifResult{},
+	}
+	invocation := sarifInvocation{ExecutionSuccessful: true}
+	ruleIndex := make(map[string]int)
+
+	for _, f := range d.Files {
+		artifact := sarifArtifactLocation{URI: sarifURI(f.Filename)}
+		run.Artifacts = append(run.Artifacts, sarifArtifactRef{Location: artifact})
+		if !f.Valid {
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, sarifNotification{
+				Level:   "error",
+				Message: sarifMessage{Text: "the file can't be parsed"},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: artifact},
+				}},
+			})
+		}
+
+		for _, w := range f.Warnings {
+			index, ok := ruleIndex[w.Category]
+			if !ok {
+				index = len(run.Tool.Driver.Rules)
+				ruleIndex[w.Category] = index
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: w.Category, HelpURI: w.URL})
+			}
+
+			level := "warning"
+			if !w.Actionable {
+				level = "note"
+			}
+
+			result := sarifResult{
+				RuleID:    w.Category,
+				RuleIndex: index,
+				Level:     level,
+				Message:   sarifMessage{Text: w.Message},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: artifact,
+						Region: &sarifRegion{
+							StartLine:   w.Start.Line,
+							StartColumn: w.Start.Column,
+							EndLine:     w.End.Line,
+							EndColumn:   w.End.Column,
+						},
+					},
+				}},
+			}
+
+			if r := w.fix; r != nil {
+				offset, length := r.Start, r.End-r.Start
+				replacement := sarifReplacement{
+					DeletedRegion: sarifRegion{ByteOffset: &offset, ByteLength: &length},
+				}
+				if r.Content != "" {
+					replacement.InsertedContent = &sarifMessage{Text: r.Content}
+				}
+				result.Fixes = []sarifFix{{
+					Description: sarifMessage{Text: r.Description},
+					ArtifactChanges: []sarifArtifactChange{{
+						ArtifactLocation: artifact,
+						Replacements:     []sarifReplacement{replacement},
+					}},
+				}}
+			}
+			run.Results = append(run.Results, result)
+		}
+	}
+	run.Invocations = []sarifInvocation{invocation}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	}
+	var result []byte
+	if verbose {
+		result, _ = json.MarshalIndent(log, "", "    ")
+	} else {
+		result, _ = json.Marshal(log)
+	}
+	return string(result) + "\n"
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bazelbuild/buildtools/build"
+)
+
+func TestFormatSarif(t *testing.T) {
+	data := []byte("x = 5 / 2\n")
+	f, err := build.ParseBzl("pkg/foo.bzl", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings := []string{"integer-division"}
+	diagnostics := NewDiagnostics(
+		NewFileDiagnostics(f.DisplayPath(), Suggest(f, data, &warnings, build.ParseBzl)),
+		InvalidFileDiagnostics("pkg/BUILD"),
+	)
+
+	var log sarifLog
+	if err := json.Unmarshal([]byte(diagnostics.Format("sarif", false)), &log); err != nil {
+		t.Fatalf("can't parse the SARIF output: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF log: %+v", log)
+	}
+	run := log.Runs[0]
+
+	if len(run.Tool.Driver.Rules) != 1 || run.Tool.Driver.Rules[0].ID != "integer-division" ||
+		run.Tool.Driver.Rules[0].HelpURI != "https://github.com/bazelbuild/buildtools/blob/main/WARNINGS.md#integer-division" {
+		t.Errorf("unexpected rules: %+v", run.Tool.Driver.Rules)
+	}
+
+	if len(run.Results) != 1 {
+		t.Fatalf("got %d results, want 1", len(run.Results))
+	}
+	result := run.Results[0]
+	if result.RuleID != "integer-division" || result.RuleIndex != 0 || result.Level != "warning" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	location := result.Locations[0].PhysicalLocation
+	wantRegion := sarifRegion{StartLine: 1, StartColumn: 5, EndLine: 1, EndColumn: 10}
+	if location.ArtifactLocation.URI != "pkg/foo.bzl" || location.Region == nil || *location.Region != wantRegion {
+		t.Errorf("unexpected location: %+v, %+v", location.ArtifactLocation, location.Region)
+	}
+
+	if len(result.Fixes) != 1 || len(result.Fixes[0].ArtifactChanges) != 1 {
+		t.Fatalf("unexpected fixes: %+v", result.Fixes)
+	}
+	replacement := result.Fixes[0].ArtifactChanges[0].Replacements[0]
+	start, end := *replacement.DeletedRegion.ByteOffset, *replacement.DeletedRegion.ByteOffset+*replacement.DeletedRegion.ByteLength
+	fixed := string(data[:start]) + replacement.InsertedContent.Text + string(data[end:])
+	if fixed != "x = 5 // 2\n" {
+		t.Errorf("applying the fix: got %q, want %q", fixed, "x = 5 // 2\n")
+	}
+
+	notifications := run.Invocations[0].ToolExecutionNotifications
+	if len(notifications) != 1 || notifications[0].Locations[0].PhysicalLocation.ArtifactLocation.URI != "pkg/BUILD" {
+		t.Errorf("unexpected notifications: %+v", notifications)
+	}
+}
+
+func TestFormatSarifUnformatted(t *testing.T) {
+	// Fixes aren't reported for unformatted files
+	data := []byte("x = 5/2\n")
+	f, err := build.ParseBzl("foo.bzl", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings := []string{"integer-division"}
+	diagnostics := NewDiagnostics(NewFileDiagnostics(f.DisplayPath(), Suggest(f, data, &warnings, build.ParseBzl)))
+
+	var log sarifLog
+	if err := json.Unmarshal([]byte(diagnostics.Format("sarif", true)), &log); err != nil {
+		t.Fatalf("can't parse the SARIF output: %v", err)
+	}
+	if results := log.Runs[0].Results; len(results) != 1 || len(results[0].Fixes) != 0 {
+		t.Errorf("unexpected results: %+v", results)
+	}
+}
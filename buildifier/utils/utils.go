@@ -19,12 +19,14 @@ limitations under the License.
 package utils
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/bazelbuild/buildtools/build"
 	"github.com/bazelbuild/buildtools/warn"
+	"github.com/bazelbuild/buildtools/wspace"
 )
 
 func isStarlarkFile(name string) bool {
@@ -104,6 +106,29 @@ func GetParser(inputType string) func(filename string, data []byte) (*build.File
 	}
 }
 
+// GetRecoveringParser returns a parser for a given file type that doesn't stop at the first
+// syntax error, see build.ParseWithRecovery.
+func GetRecoveringParser(inputType string) func(filename string, data []byte) (*build.File, []build.ParseError) {
+	return func(filename string, data []byte) (*build.File, []build.ParseError) {
+		f, errs := build.ParseWithRecovery(filename, data)
+		switch inputType {
+		case "build":
+			f.Type = build.TypeBuild
+		case "bzl":
+			f.Type = build.TypeBzl
+		case "auto":
+			// The type is detected from the file name
+		case "workspace":
+			f.Type = build.TypeWorkspace
+		case "module":
+			f.Type = build.TypeModule
+		default:
+			f.Type = build.TypeDefault
+		}
+		return f, errs
+	}
+}
+
 // getFileReader returns a *FileReader object that reads files from the local
 // filesystem if the workspace root is known.
 func getFileReader(workspaceRoot string) *warn.FileReader {
@@ -119,7 +144,15 @@ func getFileReader(workspaceRoot string) *warn.FileReader {
 		return os.ReadFile(path)
 	}
 
-	return warn.NewFileReader(readFile)
+	fileReader := warn.NewFileReader(readFile)
+	fileReader.SetFileLister(func() ([]string, error) {
+		return wspace.FindStarlarkFiles(workspaceRoot)
+	})
+	fileReader.SetPathChecker(func(path string) bool {
+		_, err := os.Stat(filepath.Join(workspaceRoot, filepath.FromSlash(path)))
+		return err == nil
+	})
+	return fileReader
 }
 
 // Lint calls the linter and returns a list of unresolved findings
@@ -134,3 +167,33 @@ func Lint(f *build.File, lint string, warningsList *[]string, verbose bool) []*w
 	}
 	return nil
 }
+
+// FixOnly applies the automatic fixes for the given warnings independently of
+// each other and returns the fixed file parsed with parser. Fixes that overlap
+// with other fixes are skipped and returned as conflicts.
+func FixOnly(f *build.File, warningsList *[]string, parser func(filename string, data []byte) (*build.File, error)) (*build.File, []warn.FixConflict, error) {
+	data, conflicts := warn.FixWarningsIndependently(f, *warningsList, getFileReader(f.WorkspaceRoot))
+	fixed, err := parser(f.Path, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	fixed.WorkspaceRoot, fixed.Pkg, fixed.Label, fixed.Profile = f.WorkspaceRoot, f.Pkg, f.Label, f.Profile
+	return fixed, conflicts, nil
+}
+
+// Suggest calls the linter in the "warn" mode and returns a list of findings with suggested
+// fixes. The fixes are byte offsets relative to data, they're only calculated if data is
+// already formatted. Formatting rewrites the AST, so it's checked on a copy of the file
+// parsed with parser, and f is linted as it is.
+func Suggest(f *build.File, data []byte, warningsList *[]string, parser func(filename string, data []byte) (*build.File, error)) []*warn.Finding {
+	fileReader := getFileReader(f.WorkspaceRoot)
+
+	formatted, err := parser(f.Path, data)
+	if err == nil {
+		formatted.Profile = f.Profile
+	}
+	if err != nil || !bytes.Equal(data, build.Format(formatted)) {
+		return warn.FileWarnings(f, *warningsList, nil, warn.ModeWarn, fileReader)
+	}
+	return warn.FileWarnings(f, *warningsList, &data, warn.ModeSuggest, fileReader)
+}
@@ -18,6 +18,8 @@ package utils
 
 import (
 	"testing"
+
+	"github.com/bazelbuild/buildtools/build"
 )
 
 func TestIsStarlarkFile(t *testing.T) {
@@ -165,3 +167,47 @@ func TestIsStarlarkFile(t *testing.T) {
 		}
 	}
 }
+
+func TestSuggestKeepsFile(t *testing.T) {
+	// The file isn't formatted, formatting would sort the list
+	data := []byte(`cc_library(name = "a", srcs = ["b.cc", "a.cc"])
+`)
+	f, err := build.ParseBuild("BUILD", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := string(build.FormatWithoutRewriting(f))
+	warnings := []string{"integer-division"}
+	Suggest(f, data, &warnings, build.ParseBuild)
+	if after := string(build.FormatWithoutRewriting(f)); after != before {
+		t.Errorf("Suggest() modified the file:\n%s\nwant:\n%s", after, before)
+	}
+}
+
+func TestSuggestUsesProfile(t *testing.T) {
+	// The file is formatted according to the default profile but not to the BUILD profile
+	data := []byte(`cc_library(name = "a", srcs = ["a.cc", "b.cc"])
+x = 1 / 2
+`)
+	warnings := []string{"integer-division"}
+	for _, tc := range []struct {
+		profile *build.FormattingProfile
+		fix     bool
+	}{
+		{profile: nil, fix: false},
+		{profile: &build.DefaultProfile, fix: true},
+	} {
+		f, err := build.ParseBuild("BUILD", data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Profile = tc.profile
+		findings := Suggest(f, data, &warnings, build.ParseBuild)
+		if len(findings) != 1 {
+			t.Fatalf("Suggest() with profile %+v returned %d findings, want 1", tc.profile, len(findings))
+		}
+		if fix := findings[0].Replacement != nil; fix != tc.fix {
+			t.Errorf("Suggest() with profile %+v returned a suggested fix: %t, want %t", tc.profile, fix, tc.fix)
+		}
+	}
+}
@@ -2423,6 +2423,92 @@ EOF
   diff -u MODULE.bazel.expected MODULE.bazel || fail "Output didn't match"
 }
 
+function test_bazel_dep_add() {
+  cat > MODULE.bazel <<EOF
+module(
+    name = "foo",
+    version = "0.27.0",
+)
+
+bazel_dep(name = "gazelle", version = "0.30.0")
+bazel_dep(name = "stardoc", version = "0.5.3", dev_dependency = True)
+EOF
+
+  cat > MODULE.bazel.expected <<EOF
+module(
+    name = "foo",
+    version = "0.27.0",
+)
+
+bazel_dep(name = "gazelle", version = "0.31.0")
+bazel_dep(name = "rules_go", version = "0.39.0")
+
+bazel_dep(name = "stardoc", version = "0.5.3", dev_dependency = True)
+bazel_dep(name = "rules_testing", version = "0.4.0", dev_dependency = True)
+EOF
+
+  $buildozer 'bazel_dep_add gazelle 0.31.0' 'bazel_dep_add rules_go 0.39.0' 'bazel_dep_add dev rules_testing 0.4.0' //MODULE.bazel:all
+  diff -u MODULE.bazel.expected MODULE.bazel || fail "Output didn't match"
+}
+
+function test_bazel_dep_remove_and_bump() {
+  cat > MODULE.bazel <<EOF
+module(
+    name = "foo",
+    version = "0.27.0",
+)
+
+bazel_dep(name = "gazelle", version = "0.30.0")
+bazel_dep(name = "rules_go", version = "0.39.0", repo_name = "io_bazel_rules_go")
+bazel_dep(name = "stardoc", version = "0.5.3", dev_dependency = True)
+EOF
+
+  cat > MODULE.bazel.expected <<EOF
+module(
+    name = "foo",
+    version = "0.27.0",
+)
+
+bazel_dep(name = "rules_go", version = "0.41.0", repo_name = "io_bazel_rules_go")
+EOF
+
+  $buildozer 'bazel_dep_remove gazelle stardoc' 'bazel_dep_bump rules_go 0.41.0' 'bazel_dep_bump rules_python 0.24.0' //MODULE.bazel:all
+  diff -u MODULE.bazel.expected MODULE.bazel || fail "Output didn't match"
+}
+
+function test_tag_add_and_remove() {
+  cat > MODULE.bazel <<EOF
+maven = use_extension("@rules_jvm_external//:extensions.bzl", "maven")
+maven.install(
+    name = "maven",
+    artifacts = ["junit:junit:4.13.2"],
+)
+maven.install(
+    name = "other",
+    artifacts = ["com.google.guava:guava:32.0.0-jre"],
+)
+use_repo(maven, "maven", "other")
+EOF
+
+  cat > MODULE.bazel.expected <<EOF
+maven = use_extension("@rules_jvm_external//:extensions.bzl", "maven")
+maven.install(
+    name = "maven",
+    artifacts = ["junit:junit:4.13.2"],
+)
+maven.install(
+    name = "test",
+    artifacts = ["org.mockito:mockito-core:5.4.0"],
+    fail_on_missing_checksum = False,
+)
+use_repo(maven, "maven", "other")
+EOF
+
+  $buildozer 'tag_add maven install name=test artifacts=["org.mockito:mockito-core:5.4.0"] fail_on_missing_checksum=False' \
+    'tag_remove @rules_jvm_external//:extensions.bzl maven install name=other' //MODULE.bazel:all
+  diff -u MODULE.bazel.expected MODULE.bazel || fail "Output didn't match"
+}
+
 function test_format() {
   cat > MODULE.bazel <<EOF
 module(
@@ -62,6 +62,11 @@ var (
 	shortenLabelsFlag  = flag.Bool("shorten_labels", true, "convert added labels to short form, e.g. //foo:bar => :bar")
 	deleteWithComments = flag.Bool("delete_with_comments", true, "If a list attribute should be deleted even if there is a comment attached to it")
 	respectBazelignore = flag.Bool("respect_bazelignore", true, "use .bazelignore file for ignoring paths")
+	diff               = flag.Bool("diff", false, "print a unified diff of the changes instead of writing the files, exit with code 4 if there are changes")
+	atomic             = flag.Bool("atomic", false, "write the changed files only if all commands succeed")
+	structuredValues   = flag.Bool("structured_values", false, "print attribute values as JSON values with the variables of the file resolved, instead of source code")
+	preserveFormatting = flag.Bool("preserve_formatting", false, "keep the formatting of the parts of the files that aren't changed, only format the changed attributes and list elements")
+	interactive        = flag.Bool("i", false, "interactive mode: read commands from stdin, keep the changes in memory until they are committed")
 )
 
 func stringList(name, help string) func() []string {
@@ -122,6 +127,13 @@ func main() {
 		IsPrintingProto:    *isPrintingProto,
 		IsPrintingJSON:     *isPrintingJSON,
 		RespectBazelignore: *respectBazelignore,
+		Diff:               *diff,
+		Atomic:             *atomic,
+		StructuredValues:   *structuredValues,
+		PreserveFormatting: *preserveFormatting,
+	}
+	if *interactive {
+		os.Exit(edit.Interactive(opts, os.Stdin, flag.Args()))
 	}
 	os.Exit(edit.Buildozer(opts, flag.Args()))
 }
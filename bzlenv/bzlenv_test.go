@@ -80,3 +80,48 @@ def foo3():
 		t.Errorf("\nexpected:\n%s\ngot:\n%s", expected, output)
 	}
 }
+
+func TestWalkEnvironmentTypeAnnotations(t *testing.T) {
+	input := `
+a: int = 1
+
+def bar(x: int, y: list[str] = []) -> int:
+    b: int = a
+    return b + x
+`
+
+	expected := `
+a0: int = 1
+
+def bar1(x2: int, y3: list[str] = []) -> int:
+    b4: int = a0
+    return b4 + x2
+`
+
+	var buildFile build.Expr
+	buildFile, _ = build.Parse("test_file.bzl", []byte(input))
+
+	var walk func(e *build.Expr, env *Environment)
+	walk = func(e *build.Expr, env *Environment) {
+		switch e := (*e).(type) {
+		case *build.DefStmt:
+			binding := env.Get(e.Name)
+			if binding != nil {
+				e.Name += strconv.Itoa(binding.ID)
+			}
+		case *build.Ident:
+			binding := env.Get(e.Name)
+			if binding != nil {
+				e.Name += strconv.Itoa(binding.ID)
+			}
+		}
+		WalkOnceWithEnvironment(*e, env, walk)
+	}
+	walk(&buildFile, NewEnvironment())
+
+	output := strings.Trim(build.FormatString(buildFile), "\n")
+	expected = strings.Trim(expected, "\n")
+	if output != expected {
+		t.Errorf("\nexpected:\n%s\ngot:\n%s", expected, output)
+	}
+}
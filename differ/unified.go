@@ -0,0 +1,245 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differ
+
+// Built-in unified diff, for the environments where an external diff program
+// is not available or its output has to be captured.
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+// diffOp is a line of an edit script: ' ' for an unchanged line, '-' for a
+// deleted line and '+' for an inserted line.
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// Unified returns the differences between old and new in the unified diff
+// format, or an empty string if they are equal. oldName and newName are used
+// in the header of the diff.
+func Unified(oldName, newName string, old, new []byte) string {
+	if string(old) == string(new) {
+		return ""
+	}
+	ops := diffLines(splitLines(string(old)), splitLines(string(new)))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	// oldLine[i] and newLine[i] are the numbers of lines before the i-th operation
+	oldLine := make([]int, len(ops)+1)
+	newLine := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if op.kind != '+' {
+			oldLine[i+1]++
+		}
+		if op.kind != '-' {
+			newLine[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// Find the end of the hunk: changes separated by at most 2*contextLines
+		// unchanged lines are merged.
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*contextLines {
+				break
+			}
+		}
+		end += contextLines
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(oldLine[start], oldLine[end]-oldLine[start]),
+			hunkRange(newLine[start], newLine[end]-newLine[start]))
+		for _, op := range ops[start:end] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return b.String()
+}
+
+// hunkRange formats a range of lines of a hunk header. start is the number of
+// lines before the range.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines splits a string into lines, each line keeps its trailing newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes the shortest edit script between two lists of lines using
+// the linear space variant of the Myers algorithm.
+func diffLines(a, b []string) []diffOp {
+	var ops []diffOp
+	diffRange(a, b, &ops)
+
+	// Print the deleted lines of each change before the inserted lines
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		var deleted, inserted []diffOp
+		j := i
+		for ; j < len(ops) && ops[j].kind != ' '; j++ {
+			if ops[j].kind == '-' {
+				deleted = append(deleted, ops[j])
+			} else {
+				inserted = append(inserted, ops[j])
+			}
+		}
+		copy(ops[i:], deleted)
+		copy(ops[i+len(deleted):], inserted)
+		i = j
+	}
+	return ops
+}
+
+// diffRange appends the shortest edit script between a and b to ops. The script
+// is split at the middle snake of the shortest path, and the parts before and
+// after it are computed recursively.
+func diffRange(a, b []string, ops *[]diffOp) {
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		*ops = append(*ops, diffOp{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			*ops = append(*ops, diffOp{'+', line})
+		}
+	case len(b) == 0:
+		for _, line := range a {
+			*ops = append(*ops, diffOp{'-', line})
+		}
+	default:
+		x, y, u, v := middleSnake(a, b)
+		diffRange(a[:x], b[:y], ops)
+		for _, line := range a[x:u] {
+			*ops = append(*ops, diffOp{' ', line})
+		}
+		diffRange(a[u:], b[v:], ops)
+	}
+
+	for _, line := range common {
+		*ops = append(*ops, diffOp{' ', line})
+	}
+}
+
+// middleSnake returns the start (x, y) and the end (u, v) of the middle snake of
+// the shortest edit script between a and b, which must differ in their first
+// and last lines. The paths from both ends are searched simultaneously until they
+// overlap, the backward path is searched on the reversed lists.
+func middleSnake(a, b []string) (x, y, u, v int) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	// forward[offset+k] is the furthest x reached on the diagonal k from the start,
+	// backward[offset+k] is the furthest distance from the end on the reversed diagonal k
+	forward := make([]int, 2*maxD+3)
+	backward := make([]int, 2*maxD+3)
+
+	for d := 0; d <= maxD; d++ {
+		for k := -d; k <= d; k += 2 {
+			x := nextX(forward, offset, k, d)
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			if kb := delta - k; odd && kb >= -(d-1) && kb <= d-1 && x+backward[offset+kb] >= n {
+				return startX, startY, x, y
+			}
+		}
+		for kb := -d; kb <= d; kb += 2 {
+			x := nextX(backward, offset, kb, d)
+			y := x - kb
+			startX, startY := x, y
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			backward[offset+kb] = x
+			if k := delta - kb; !odd && k >= -d && k <= d && x+forward[offset+k] >= n {
+				return n - x, m - y, n - startX, m - startY
+			}
+		}
+	}
+	// Unreachable: the paths overlap at the latest when d reaches maxD
+	return 0, 0, n, m
+}
+
+// nextX returns the x where the path on the diagonal k continues after d-1 edits,
+// extending the furthest reaching path of the neighbouring diagonals.
+func nextX(furthest []int, offset, k, d int) int {
+	if k == -d || (k != d && furthest[offset+k-1] < furthest[offset+k+1]) {
+		return furthest[offset+k+1]
+	}
+	return furthest[offset+k-1] + 1
+}
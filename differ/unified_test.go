@@ -0,0 +1,200 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differ
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			name: "equal",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "",
+		},
+		{
+			name: "change",
+			old:  "a\nb\nc\n",
+			new:  "a\nB\nc\n",
+			want: `--- old
++++ new
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+`,
+		},
+		{
+			name: "new file",
+			old:  "",
+			new:  "a\n",
+			want: `--- old
++++ new
+@@ -0,0 +1 @@
++a
+`,
+		},
+		{
+			name: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			new:  "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			want: `--- old
++++ new
+@@ -1,3 +1,4 @@
++0
+ 1
+ 2
+ 3
+@@ -9,4 +10,3 @@
+ 9
+ 10
+ 11
+-12
+`,
+		},
+		{
+			name: "merged hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n",
+			new:  "1\n2\nthree\n4\n5\n6\nseven\n",
+			want: `--- old
++++ new
+@@ -1,7 +1,7 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+-7
++seven
+`,
+		},
+		{
+			name: "deletions before insertions",
+			old:  "a\nb\nc\nd\n",
+			new:  "a\nx\ny\nz\nd\n",
+			want: `--- old
++++ new
+@@ -1,4 +1,5 @@
+ a
+-b
+-c
++x
++y
++z
+ d
+`,
+		},
+		{
+			name: "no newline at end of file",
+			old:  "a\nb",
+			new:  "a\nb\n",
+			want: `--- old
++++ new
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Unified("old", "new", []byte(tc.old), []byte(tc.new)); got != tc.want {
+				t.Errorf("Unified() =\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestDiffLinesMinimal(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, r.Intn(20))
+		for i := range lines {
+			lines[i] = string(rune('a' + r.Intn(4)))
+		}
+		return lines
+	}
+	for i := 0; i < 1000; i++ {
+		a, b := randomLines(), randomLines()
+		ops := diffLines(a, b)
+
+		var gotA, gotB []string
+		edits := 0
+		for _, op := range ops {
+			if op.kind != '+' {
+				gotA = append(gotA, op.line)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.line)
+			}
+			if op.kind != ' ' {
+				edits++
+			}
+		}
+		if fmt.Sprint(gotA) != fmt.Sprint(a) || fmt.Sprint(gotB) != fmt.Sprint(b) {
+			t.Fatalf("diffLines(%q, %q) = %v doesn't transform the lines", a, b, ops)
+		}
+		if want := len(a) + len(b) - 2*lcsLength(a, b); edits != want {
+			t.Fatalf("diffLines(%q, %q) has %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	// The edit script of completely different files must be computed in linear space
+	a := make([]string, 5000)
+	b := make([]string, 5000)
+	for i := range a {
+		a[i] = fmt.Sprintf("old %d\n", i)
+		b[i] = fmt.Sprintf("new %d\n", i)
+	}
+	if ops := diffLines(a, b); len(ops) != len(a)+len(b) {
+		t.Errorf("diffLines() has %d operations, want %d", len(ops), len(a)+len(b))
+	}
+}
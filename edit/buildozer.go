@@ -21,6 +21,7 @@ package edit
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -30,14 +31,18 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 
 	apipb "github.com/bazelbuild/buildtools/api_proto"
 	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/bzlenv"
+	"github.com/bazelbuild/buildtools/differ"
 	"github.com/bazelbuild/buildtools/edit/bzlmod"
 	"github.com/bazelbuild/buildtools/file"
 	"github.com/bazelbuild/buildtools/labels"
+	"github.com/bazelbuild/buildtools/tables"
 	"github.com/bazelbuild/buildtools/wspace"
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
@@ -61,6 +66,10 @@ type Options struct {
 	OutWriter          io.Writer // where to write normal output (`os.Stdout` will be used if not specified)
 	ErrWriter          io.Writer // where to write error output (`os.Stderr` will be used if not specified)
 	RespectBazelignore bool      // whether to use .bazelignore file for ignoring paths
+	Diff               bool      // print a unified diff of the changes instead of writing the files
+	Atomic             bool      // write the changed files only if all commands succeed
+	StructuredValues   bool      // print attribute values as structured values instead of source code
+	PreserveFormatting bool      // keep the formatting of the unchanged parts of the files
 }
 
 // NewOpts returns a new Options struct with some defaults set.
@@ -291,9 +300,54 @@ func cmdNew(opts *Options, env CmdEnvironment) (*build.File, error) {
 	} else {
 		env.File.Stmt = InsertAfter(insertionIndex, env.File.Stmt, call)
 	}
+	if location := symbolLoadLocation(kind); location != "" && !isSymbolDefined(env.File, kind) {
+		env.File.Stmt = InsertLoad(env.File.Stmt, location, []string{kind}, []string{kind})
+	}
 	return env.File, nil
 }
 
+// symbolLoadLocation returns the location of the .bzl file to load a symbol
+// from, as specified in tables.SymbolLoadLocations, or the only location
+// allowed in tables.AllowedSymbolLoadLocations. Returns an empty string if the
+// location is unknown.
+func symbolLoadLocation(symbol string) string {
+	if location, ok := tables.SymbolLoadLocations[symbol]; ok {
+		return location
+	}
+	if locations := tables.AllowedSymbolLoadLocations[symbol]; len(locations) == 1 {
+		for location := range locations {
+			return location
+		}
+	}
+	return ""
+}
+
+// isSymbolDefined returns true if a symbol is loaded, assigned or defined as
+// a function at the top level of the file.
+func isSymbolDefined(f *build.File, symbol string) bool {
+	for _, stmt := range f.Stmt {
+		switch stmt := stmt.(type) {
+		case *build.LoadStmt:
+			for _, to := range stmt.To {
+				if to.Name == symbol {
+					return true
+				}
+			}
+		case *build.DefStmt:
+			if stmt.Name == symbol {
+				return true
+			}
+		case *build.AssignExpr:
+			for _, ident := range bzlenv.CollectLValues(stmt.LHS) {
+				if ident.Name == symbol {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 // findInsertionIndex is used by cmdNew to find the place at which to insert the new rule.
 func findInsertionIndex(env CmdEnvironment) (bool, int, error) {
 	if len(env.Args) < 4 {
@@ -370,6 +424,7 @@ func cmdPrint(opts *Options, env CmdEnvironment) (*build.File, error) {
 		format = []string{"name", "kind"}
 	}
 	fields := make([]*apipb.Output_Record_Field, len(format))
+	var vars map[string]*build.AssignExpr
 
 	for i, str := range format {
 		value := env.Rule.Attr(str)
@@ -416,6 +471,13 @@ func cmdPrint(opts *Options, env CmdEnvironment) (*build.File, error) {
 			fields[i] = &apipb.Output_Record_Field{
 				Value: &apipb.Output_Record_Field_Error{Error: apipb.Output_Record_Field_MISSING},
 			}
+		} else if opts.StructuredValues {
+			if vars == nil {
+				vars = getGlobalVariables(env.File.Stmt)
+			}
+			fields[i] = &apipb.Output_Record_Field{
+				Value: &apipb.Output_Record_Field_Structured{Structured: structuredValue(value, vars)},
+			}
 		} else if lit, ok := value.(*build.LiteralExpr); ok {
 			fields[i] = &apipb.Output_Record_Field{
 				Value: &apipb.Output_Record_Field_Text{Text: lit.Token},
@@ -907,50 +969,223 @@ func cmdImplUseRepo(env CmdEnvironment, mode string) (*build.File, error) {
 		return nil, fmt.Errorf("%s: only applies to MODULE.bazel files", mode)
 	}
 
+	proxies, repos, err := extensionProxies(env, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	useRepos := bzlmod.UseRepos(env.File, proxies)
+	if len(useRepos) == 0 {
+		var newUseRepo *build.CallExpr
+		env.File, newUseRepo = bzlmod.NewUseRepo(env.File, proxies)
+		useRepos = []*build.CallExpr{newUseRepo}
+	}
+
+	if mode == "use_repo_add" {
+		bzlmod.AddRepoUsages(useRepos, repos...)
+	} else {
+		bzlmod.RemoveRepoUsages(useRepos, repos...)
+	}
+
+	return env.File, nil
+}
+
+// extensionProxies returns the extension proxies specified by the arguments of a command, either
+// as `[dev] <extension .bzl file> <extension name>` or as `<use_extension variable name>`, and the
+// remaining arguments.
+func extensionProxies(env CmdEnvironment, mode string) ([]string, []string, error) {
 	dev := false
 	args := env.Args
-	if env.Args[0] == "dev" && isExtensionLabel(env.Args[1]) {
+	if len(env.Args) > 1 && env.Args[0] == "dev" && isExtensionLabel(env.Args[1]) {
 		dev = true
 		args = env.Args[1:]
 	}
 
-	var proxies []string
-	var repos []string
 	if isExtensionLabel(args[0]) {
+		if len(args) < 2 {
+			return nil, nil, fmt.Errorf("%s: missing extension name", mode)
+		}
 		extBzlFile := args[0]
 		extName := args[1]
 
-		proxies = bzlmod.Proxies(env.File, extBzlFile, extName, dev)
+		proxies := bzlmod.Proxies(env.File, extBzlFile, extName, dev)
 		if len(proxies) == 0 {
-			return nil, fmt.Errorf("%s: no use_extension assignment found for extension %q defined in %q", mode, extName, extBzlFile)
+			return nil, nil, fmt.Errorf("%s: no use_extension assignment found for extension %q defined in %q", mode, extName, extBzlFile)
 		}
-		repos = args[2:]
-	} else {
-		proxy := args[0]
+		return proxies, args[2:], nil
+	}
 
-		proxies = bzlmod.AllProxies(env.File, proxy)
-		if len(proxies) == 0 {
-			return nil, fmt.Errorf("%s: no use_extension assignment to variable %q found", mode, proxy)
+	proxy := args[0]
+	proxies := bzlmod.AllProxies(env.File, proxy)
+	if len(proxies) == 0 {
+		return nil, nil, fmt.Errorf("%s: no use_extension assignment to variable %q found", mode, proxy)
+	}
+	return proxies, args[1:], nil
+}
+
+func cmdBazelDepAdd(opts *Options, env CmdEnvironment) (*build.File, error) {
+	if env.File.Type != build.TypeModule {
+		return nil, fmt.Errorf("bazel_dep_add: only applies to MODULE.bazel files")
+	}
+	dev := false
+	args := env.Args
+	if len(args) == 3 {
+		if args[0] != "dev" {
+			return nil, fmt.Errorf("bazel_dep_add: expected \"dev\" as the first argument, got %q", args[0])
 		}
-		repos = args[1:]
+		dev = true
+		args = args[1:]
+	} else if args[0] == "dev" {
+		return nil, fmt.Errorf("bazel_dep_add: expected a module name and a version after \"dev\"")
 	}
+	name, version := args[0], args[1]
 
-	useRepos := bzlmod.UseRepos(env.File, proxies)
-	if len(useRepos) == 0 {
-		var newUseRepo *build.CallExpr
-		env.File, newUseRepo = bzlmod.NewUseRepo(env.File, proxies)
-		useRepos = []*build.CallExpr{newUseRepo}
+	dep := bzlmod.BazelDep(env.File, name)
+	if dep == nil {
+		env.File, dep = bzlmod.NewBazelDep(env.File, name, dev)
+	} else if dev {
+		dep.SetAttr("dev_dependency", &build.Ident{Name: "True"})
 	}
+	// Without the dev argument, an existing dev dependency stays a dev dependency
+	dep.SetAttr("version", &build.StringExpr{Value: version})
+	return env.File, nil
+}
 
-	if mode == "use_repo_add" {
-		bzlmod.AddRepoUsages(useRepos, repos...)
-	} else {
-		bzlmod.RemoveRepoUsages(useRepos, repos...)
+func cmdBazelDepRemove(opts *Options, env CmdEnvironment) (*build.File, error) {
+	if env.File.Type != build.TypeModule {
+		return nil, fmt.Errorf("bazel_dep_remove: only applies to MODULE.bazel files")
+	}
+	removed := 0
+	for _, name := range env.Args {
+		removed += env.File.DelRules("bazel_dep", name)
+	}
+	if removed == 0 {
+		return nil, nil
 	}
+	return env.File, nil
+}
 
+func cmdBazelDepBump(opts *Options, env CmdEnvironment) (*build.File, error) {
+	if env.File.Type != build.TypeModule {
+		return nil, fmt.Errorf("bazel_dep_bump: only applies to MODULE.bazel files")
+	}
+	dep := bzlmod.BazelDep(env.File, env.Args[0])
+	if dep == nil {
+		// Nothing to bump, the module doesn't depend on the given module.
+		return nil, nil
+	}
+	dep.SetAttr("version", &build.StringExpr{Value: env.Args[1]})
 	return env.File, nil
 }
 
+func cmdTagAdd(opts *Options, env CmdEnvironment) (*build.File, error) {
+	return cmdImplTag(env, "tag_add")
+}
+
+func cmdTagRemove(opts *Options, env CmdEnvironment) (*build.File, error) {
+	return cmdImplTag(env, "tag_remove")
+}
+
+func cmdImplTag(env CmdEnvironment, mode string) (*build.File, error) {
+	if env.File.Type != build.TypeModule {
+		return nil, fmt.Errorf("%s: only applies to MODULE.bazel files", mode)
+	}
+
+	proxies, args, err := extensionProxies(env, mode)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%s: missing tag name", mode)
+	}
+	tagName := args[0]
+	var attrs []*build.AssignExpr
+	for _, arg := range args[1:] {
+		i := strings.Index(arg, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("%s: expected an attribute of the form <name>=<value>, got %q", mode, arg)
+		}
+		attrs = append(attrs, &build.AssignExpr{
+			LHS: &build.Ident{Name: arg[:i]},
+			Op:  "=",
+			RHS: parseTagValue(arg[i+1:]),
+		})
+	}
+
+	if mode == "tag_add" {
+		var tag *build.CallExpr
+		env.File, tag = bzlmod.NewTag(env.File, proxies, tagName)
+		for _, attr := range attrs {
+			tag.List = append(tag.List, attr)
+		}
+		return env.File, nil
+	}
+
+	toRemove := make(map[*build.CallExpr]bool)
+	for _, tag := range bzlmod.Tags(env.File, proxies, tagName) {
+		if tagHasAttrs(tag, attrs) {
+			toRemove[tag] = true
+		}
+	}
+	if len(toRemove) == 0 {
+		return nil, nil
+	}
+	var stmts []build.Expr
+	for _, stmt := range env.File.Stmt {
+		if call, ok := stmt.(*build.CallExpr); !ok || !toRemove[call] {
+			stmts = append(stmts, stmt)
+		}
+	}
+	env.File.Stmt = stmts
+	return env.File, nil
+}
+
+// parseTagValue converts the value of a tag attribute given on the command line to an expression.
+// Strings, numbers, lists, dicts, tuples, True, False and None are parsed, anything else is
+// considered to be an unquoted string.
+func parseTagValue(value string) build.Expr {
+	if f, err := build.ParseBuild("", []byte(value)); err == nil && len(f.Stmt) == 1 {
+		switch expr := f.Stmt[0].(type) {
+		case *build.StringExpr, *build.ListExpr, *build.DictExpr, *build.TupleExpr:
+			return expr
+		case *build.LiteralExpr:
+			if _, err := strconv.ParseFloat(expr.Token, 64); err == nil {
+				return expr
+			}
+			if _, err := strconv.ParseInt(expr.Token, 0, 64); err == nil {
+				return expr
+			}
+		case *build.Ident:
+			if expr.Name == "True" || expr.Name == "False" || expr.Name == "None" {
+				return expr
+			}
+		}
+	}
+	return &build.StringExpr{Value: value}
+}
+
+// tagHasAttrs returns true if the tag call has all the given attributes with the same values.
+func tagHasAttrs(tag *build.CallExpr, attrs []*build.AssignExpr) bool {
+	for _, attr := range attrs {
+		name := attr.LHS.(*build.Ident).Name
+		found := false
+		for _, arg := range tag.List {
+			kwarg, ok := arg.(*build.AssignExpr)
+			if !ok {
+				continue
+			}
+			if ident, ok := kwarg.LHS.(*build.Ident); ok && ident.Name == name {
+				found = build.FormatString(kwarg.RHS) == build.FormatString(attr.RHS)
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 func cmdFormat(opts *Options, env CmdEnvironment) (*build.File, error) {
 	// Force formatting by not returning a nil *build.File.
 	return env.File, nil
@@ -1012,7 +1247,14 @@ var AllCommands = map[string]CommandInfo{
 	"dict_list_add":         {cmdDictListAdd, true, 3, -1, "<attr> <key> <value(s)>"},
 	"use_repo_add":          {cmdUseRepoAdd, false, 2, -1, "([dev] <extension .bzl file> <extension name>|<use_extension variable name>) <repo(s)>"},
 	"use_repo_remove":       {cmdUseRepoRemove, false, 2, -1, "([dev] <extension .bzl file> <extension name>|<use_extension variable name>) <repo(s)>"},
+	"bazel_dep_add":         {cmdBazelDepAdd, false, 2, 3, "[dev] <module name> <version>"},
+	"bazel_dep_remove":      {cmdBazelDepRemove, false, 1, -1, "<module name(s)>"},
+	"bazel_dep_bump":        {cmdBazelDepBump, false, 2, 2, "<module name> <version>"},
+	"tag_add":               {cmdTagAdd, false, 2, -1, "([dev] <extension .bzl file> <extension name>|<use_extension variable name>) <tag name> <attr=value(s)>"},
+	"tag_remove":            {cmdTagRemove, false, 2, -1, "([dev] <extension .bzl file> <extension name>|<use_extension variable name>) <tag name> <attr=value(s)>"},
 	"format":                {cmdFormat, false, 0, 0, ""},
+	"move_target":           {cmdWorkspace, true, 1, 1, "<new_package>"},
+	"rename_target":         {cmdWorkspace, true, 1, 2, "<old_label>? <new_label>"},
 }
 
 var readonlyCommands = map[string]bool{
@@ -1037,10 +1279,16 @@ func expandTargets(f *build.File, rule string) ([]*build.Rule, error) {
 			if r := f.RuleAt(linenum); r != nil {
 				return []*build.Rule{r}, nil
 			}
-		} else {
-			return f.Rules(kind), nil
+			return nil, fmt.Errorf("rule '%s' not found", rule)
 		}
 	}
+	// Selectors like "%java_library[testonly=1]", "attr(tags, manual)" or
+	// "~.*_test" match rules by kind, attribute values or name.
+	if sel, err := parseRuleSelector(rule); err != nil {
+		return nil, err
+	} else if sel != nil {
+		return sel.filter(f), nil
+	}
 	return nil, fmt.Errorf("rule '%s' not found", rule)
 }
 
@@ -1067,19 +1315,23 @@ type command struct {
 // checkCommandUsage checks the number of argument of a command.
 // It prints an error and usage when it is not valid.
 func checkCommandUsage(opts *Options, name string, cmd CommandInfo, count int) {
-	if count >= cmd.MinArg && (cmd.MaxArg == -1 || count <= cmd.MaxArg) {
-		return
+	if err := commandUsageError(name, cmd, count); err != nil {
+		fmt.Fprintf(opts.ErrWriter, "%s\n", err)
+		Usage()
+		os.Exit(1)
 	}
+}
 
+// commandUsageError returns an error if the number of arguments of a command
+// is not valid.
+func commandUsageError(name string, cmd CommandInfo, count int) error {
 	if count < cmd.MinArg {
-		fmt.Fprintf(opts.ErrWriter, "Too few arguments for command '%s', expected at least %d.\n",
-			name, cmd.MinArg)
-	} else {
-		fmt.Fprintf(opts.ErrWriter, "Too many arguments for command '%s', expected at most %d.\n",
-			name, cmd.MaxArg)
+		return fmt.Errorf("Too few arguments for command '%s', expected at least %d.", name, cmd.MinArg)
 	}
-	Usage()
-	os.Exit(1)
+	if cmd.MaxArg != -1 && count > cmd.MaxArg {
+		return fmt.Errorf("Too many arguments for command '%s', expected at most %d.", name, cmd.MaxArg)
+	}
+	return nil
 }
 
 // Match text that only contains spaces or line breaks if they're escaped with '\'.
@@ -1165,6 +1417,7 @@ type commandsForTarget struct {
 type commandsForFile struct {
 	file     string
 	commands []commandsForTarget
+	edited   *build.File // the file modified by workspace commands, if any
 }
 
 // commandError returns an error that formats 'err' in the context of the
@@ -1178,6 +1431,8 @@ type rewriteResult struct {
 	file     string
 	errs     []error
 	modified bool
+	diff     string        // the changes in the unified diff format, if Options.Diff is set
+	pending  *pendingWrite // the changes to write at the end, if Options.Atomic is set
 	records  []*apipb.Output_Record
 }
 
@@ -1246,22 +1501,10 @@ func rewrite(opts *Options, commandsForFile commandsForFile) *rewriteResult {
 		}
 	} else {
 		origName := name
-		for _, suffix := range BuildFileNames {
-			if strings.HasSuffix(name, "/"+suffix) {
-				name = strings.TrimSuffix(name, suffix)
-				break
-			}
-		}
-		for _, suffix := range BuildFileNames {
-			name = name + suffix
-			data, fi, err = file.ReadFile(name)
-			if err == nil {
-				break
-			}
-			name = strings.TrimSuffix(name, suffix)
-		}
-		if err != nil {
-			data, fi, err = file.ReadFile(name)
+		name, data, fi, err = readBuildFile(name)
+		if err != nil && commandsForFile.edited != nil {
+			// A new file created by a workspace command
+			name, data, fi, err = origName, nil, nil, nil
 		}
 		if err != nil {
 			err = errors.New("file not found or not readable")
@@ -1269,23 +1512,105 @@ func rewrite(opts *Options, commandsForFile commandsForFile) *rewriteResult {
 		}
 	}
 
-	f, err := build.Parse(name, data)
+	f := commandsForFile.edited
+	if f == nil {
+		f, err = parseFile(opts, name, data)
+		if err != nil {
+			return &rewriteResult{file: name, errs: []error{err}}
+		}
+		if f.Type == build.TypeDefault {
+			// Buildozer is unable to infer the file type, fall back to BUILD by default.
+			f.Type = build.TypeBuild
+		}
+		f.WorkspaceRoot, f.Pkg, f.Label = wspace.SplitFilePath(name)
+	}
+
+	changed := commandsForFile.edited != nil
+	newf, errs, ok := executeFileCommands(opts, f, commandsForFile.commands, &records)
+	if !ok {
+		return &rewriteResult{file: name, errs: errs, records: records}
+	}
+	if newf != nil {
+		changed = true
+		f = newf
+	}
+	if !changed {
+		return &rewriteResult{file: name, errs: errs, records: records}
+	}
+	ndata, err := cleanAndBuildify(opts, f)
 	if err != nil {
-		return &rewriteResult{file: name, errs: []error{err}}
+		return &rewriteResult{file: name, errs: []error{fmt.Errorf("running buildifier: %v", err)}, records: records}
 	}
-	if f.Type == build.TypeDefault {
-		// Buildozer is unable to infer the file type, fall back to BUILD by default.
-		f.Type = build.TypeBuild
+
+	if opts.Diff {
+		// Use the paths relative to the workspace root, so that the diff can be
+		// applied with `git apply` or `patch -p1`.
+		diffName := name
+		if f.WorkspaceRoot != "" {
+			diffName = path.Join(f.Pkg, f.Label)
+		}
+		diff := differ.Unified("a/"+diffName, "b/"+diffName, data, ndata)
+		return &rewriteResult{file: name, errs: errs, modified: diff != "", diff: diff, records: records}
+	}
+
+	if opts.Stdout || name == stdinPackageName {
+		opts.OutWriter.Write(ndata)
+		return &rewriteResult{file: name, errs: errs, modified: !bytes.Equal(data, ndata), records: records}
 	}
-	f.WorkspaceRoot, f.Pkg, f.Label = wspace.SplitFilePath(name)
 
+	if bytes.Equal(data, ndata) {
+		return &rewriteResult{file: name, errs: errs, records: records}
+	}
+
+	if opts.Atomic {
+		pending := &pendingWrite{name: name, fi: fi, oldData: data, newData: ndata}
+		return &rewriteResult{file: name, errs: errs, pending: pending, records: records}
+	}
+
+	if err := EditFile(fi, name); err != nil {
+		return &rewriteResult{file: name, errs: []error{err}, records: records}
+	}
+
+	if err := file.WriteFile(name, ndata); err != nil {
+		return &rewriteResult{file: name, errs: []error{err}, records: records}
+	}
+
+	return &rewriteResult{file: name, errs: errs, modified: true, records: records}
+}
+
+// readBuildFile reads a BUILD file. If the file doesn't exist, the other names
+// of BUILD files in the same directory are tried. Returns the name of the file
+// that has been read.
+func readBuildFile(name string) (string, []byte, os.FileInfo, error) {
+	for _, suffix := range BuildFileNames {
+		if strings.HasSuffix(name, "/"+suffix) {
+			name = strings.TrimSuffix(name, suffix)
+			break
+		}
+	}
+	for _, suffix := range BuildFileNames {
+		name = name + suffix
+		data, fi, err := file.ReadFile(name)
+		if err == nil {
+			return name, data, fi, nil
+		}
+		name = strings.TrimSuffix(name, suffix)
+	}
+	data, fi, err := file.ReadFile(name)
+	return name, data, fi, err
+}
+
+// executeFileCommands executes the commands on their targets in a file.
+// Returns the modified file, or nil if it hasn't been modified, and the errors
+// that occurred. If the execution has been stopped because of an error (i.e.
+// Options.KeepGoing is not set), ok is false and the file must not be written.
+func executeFileCommands(opts *Options, f *build.File, commands []commandsForTarget, records *[]*apipb.Output_Record) (newf *build.File, errs []error, ok bool) {
 	vars := map[string]*build.AssignExpr{}
 	if opts.EditVariables {
 		vars = getGlobalVariables(f.Stmt)
 	}
-	var errs []error
 	changed := false
-	for _, cft := range commandsForFile.commands {
+	for _, cft := range commands {
 		_, _, absPkg, rule := InterpretLabelForWorkspaceLocation(opts.RootDir, cft.target)
 		if label := labels.Parse(cft.target); label.Package == stdinPackageName {
 			// Special-case: This is already absolute
@@ -1301,46 +1626,24 @@ func rewrite(opts *Options, commandsForFile commandsForFile) *rewriteResult {
 			cerr := commandError(cft.commands, cft.target, err)
 			errs = append(errs, cerr)
 			if !opts.KeepGoing {
-				return &rewriteResult{file: name, errs: errs, records: records}
+				return nil, errs, false
 			}
 		}
 		targets = filterRules(opts, targets)
 
-		newf, err := executeCommandsInFile(opts, f, cft, targets, &records, vars, absPkg, &errs)
+		newf, err := executeCommandsInFile(opts, f, cft, targets, records, vars, absPkg, &errs)
 		if err != nil {
-			return &rewriteResult{file: name, errs: []error{err}, records: records}
+			return nil, []error{err}, false
 		}
 		if newf != nil {
 			changed = true
 			f = newf
 		}
 	}
-	if !changed {
-		return &rewriteResult{file: name, errs: errs, records: records}
-	}
-	ndata, err := cleanAndBuildify(opts, f)
-	if err != nil {
-		return &rewriteResult{file: name, errs: []error{fmt.Errorf("running buildifier: %v", err)}, records: records}
-	}
-
-	if opts.Stdout || name == stdinPackageName {
-		opts.OutWriter.Write(ndata)
-		return &rewriteResult{file: name, errs: errs, modified: !bytes.Equal(data, ndata), records: records}
-	}
-
-	if bytes.Equal(data, ndata) {
-		return &rewriteResult{file: name, errs: errs, records: records}
-	}
-
-	if err := EditFile(fi, name); err != nil {
-		return &rewriteResult{file: name, errs: []error{err}, records: records}
-	}
-
-	if err := file.WriteFile(name, ndata); err != nil {
-		return &rewriteResult{file: name, errs: []error{err}, records: records}
+	if changed {
+		return f, errs, true
 	}
-
-	return &rewriteResult{file: name, errs: errs, modified: true, records: records}
+	return nil, errs, true
 }
 
 // executeCommandsInFile executes the provided commandsForTarget in the provided build.File.
@@ -1389,6 +1692,14 @@ func executeCommandsInFile(
 	return nil, nil
 }
 
+// parseFile parses a file to be edited, keeping its source if opts.PreserveFormatting is set.
+func parseFile(opts *Options, name string, data []byte) (*build.File, error) {
+	if opts.PreserveFormatting {
+		return build.ParseLossless(name, data)
+	}
+	return build.Parse(name, data)
+}
+
 func cleanAndBuildify(opts *Options, f *build.File) ([]byte, error) {
 	f = RemoveEmptyPackage(f)
 	f = RemoveEmptyUseRepoCalls(f)
@@ -1429,7 +1740,20 @@ func targetExpressionToBuildFiles(rootDir string, target string, respectBazelign
 // ignoredPrefixes are path prefixes to ignore (if a path matches any of these prefixes,
 // it will be skipped along with its subdirectories).
 func findBuildFiles(rootDir string, ignoredPrefixes []string) []string {
-	var buildFiles []string
+	return findFiles(rootDir, ignoredPrefixes, func(name string) bool {
+		for _, buildFileName := range BuildFileNames {
+			if name == buildFileName {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// findFiles returns all files in the subtree of rootDir whose base names match
+// the given function. ignoredPrefixes are handled as in findBuildFiles.
+func findFiles(rootDir string, ignoredPrefixes []string, match func(name string) bool) []string {
+	var files []string
 	searchDirs := []string{rootDir}
 
 	for len(searchDirs) != 0 {
@@ -1451,17 +1775,13 @@ func findBuildFiles(rootDir string, ignoredPrefixes []string) []string {
 
 			if dirFile.IsDir() {
 				searchDirs = append(searchDirs, fullPath)
-			} else {
-				for _, buildFileName := range BuildFileNames {
-					if dirFile.Name() == buildFileName {
-						buildFiles = append(buildFiles, fullPath)
-					}
-				}
+			} else if match(dirFile.Name()) {
+				files = append(files, fullPath)
 			}
 		}
 	}
 
-	return buildFiles
+	return files
 }
 
 // getIgnoredPrefixes returns a list of ignored prefixes from the .bazelignore file in the root directory.
@@ -1594,6 +1914,28 @@ func appendCommandsFromReader(opts *Options, reader io.Reader, commandsByFile ma
 	return nil
 }
 
+// printRecords prints the output of the commands in the format given by the
+// options.
+func printRecords(opts *Options, records []*apipb.Output_Record) {
+	if opts.IsPrintingProto {
+		data, err := proto.Marshal(&apipb.Output{Records: records})
+		if err != nil {
+			log.Fatal("marshaling error: ", err)
+		}
+		fmt.Fprintf(opts.OutWriter, "%s", data)
+	} else if opts.IsPrintingJSON {
+		marshaler := jsonpb.Marshaler{}
+		if err := marshaler.Marshal(opts.OutWriter, &apipb.Output{Records: records}); err != nil {
+			log.Fatal("json marshaling error: ", err)
+		}
+		fmt.Fprintln(opts.OutWriter)
+	} else {
+		for _, record := range records {
+			printRecord(opts.OutWriter, record)
+		}
+	}
+}
+
 func printRecord(writer io.Writer, record *apipb.Output_Record) {
 	fields := record.Fields
 	line := make([]string, len(fields))
@@ -1616,6 +1958,13 @@ func printRecord(writer io.Writer, record *apipb.Output_Record) {
 			}
 		case *apipb.Output_Record_Field_List:
 			line[i] = fmt.Sprintf("[%s]", strings.Join(value.List.Strings, " "))
+		case *apipb.Output_Record_Field_Structured:
+			data, err := json.Marshal(value.Structured.AsInterface())
+			if err != nil {
+				line[i] = "(unknown)"
+			} else {
+				line[i] = string(data)
+			}
 		}
 	}
 
@@ -1646,6 +1995,19 @@ func Buildozer(opts *Options, args []string) int {
 		}
 	}
 
+	edited, workspaceErrs := runWorkspaceCommands(opts, commandsByFile, nil)
+	for _, err := range workspaceErrs {
+		fmt.Fprintf(opts.ErrWriter, "%s\n", err)
+	}
+	if len(workspaceErrs) > 0 && !opts.KeepGoing {
+		return 2
+	}
+	for file := range edited {
+		if _, ok := commandsByFile[file]; !ok {
+			commandsByFile[file] = nil
+		}
+	}
+
 	numFiles := len(commandsByFile)
 	if opts.Parallelism > 0 {
 		runtime.GOMAXPROCS(opts.Parallelism)
@@ -1666,11 +2028,13 @@ func Buildozer(opts *Options, args []string) int {
 	}
 
 	for file, commands := range commandsByFile {
-		data <- commandsForFile{file, commands}
+		data <- commandsForFile{file, commands, edited[file]}
 	}
 	close(data)
 	records := []*apipb.Output_Record{}
-	var hasErrors bool
+	var diffs []*rewriteResult
+	var pending []*rewriteResult
+	hasErrors := len(workspaceErrs) > 0
 	var fileModified bool
 	for i := 0; i < numFiles; i++ {
 		fileResults := <-results
@@ -1682,35 +2046,62 @@ func Buildozer(opts *Options, args []string) int {
 		for _, err := range fileResults.errs {
 			fmt.Fprintf(opts.ErrWriter, "%s: %s\n", fileResults.file, err)
 		}
+		if fileResults.diff != "" {
+			diffs = append(diffs, fileResults)
+		}
+		if fileResults.pending != nil {
+			pending = append(pending, fileResults)
+		}
 		if fileResults.modified && !opts.Quiet {
-			fmt.Fprintf(opts.ErrWriter, "fixed %s\n", fileResults.file)
+			if opts.Diff {
+				fmt.Fprintf(opts.ErrWriter, "would fix %s\n", fileResults.file)
+			} else {
+				fmt.Fprintf(opts.ErrWriter, "fixed %s\n", fileResults.file)
+			}
 		}
 		if fileResults.records != nil {
 			records = append(records, fileResults.records...)
 		}
 	}
 
-	if opts.IsPrintingProto {
-		data, err := proto.Marshal(&apipb.Output{Records: records})
-		if err != nil {
-			log.Fatal("marshaling error: ", err)
-		}
-		fmt.Fprintf(opts.OutWriter, "%s", data)
-	} else if opts.IsPrintingJSON {
-		marshaler := jsonpb.Marshaler{}
-		if err := marshaler.Marshal(opts.OutWriter, &apipb.Output{Records: records}); err != nil {
-			log.Fatal("json marshaling error: ", err)
-		}
-		fmt.Fprintln(opts.OutWriter)
-	} else {
-		for _, record := range records {
-			printRecord(opts.OutWriter, record)
+	if opts.Atomic && len(pending) > 0 {
+		if hasErrors {
+			fmt.Fprintf(opts.ErrWriter, "no files were modified because of the errors above\n")
+		} else {
+			writes := make([]*pendingWrite, len(pending))
+			for i, result := range pending {
+				writes[i] = result.pending
+			}
+			if err := commitFiles(writes); err != nil {
+				fmt.Fprintf(opts.ErrWriter, "error: %s, no files were modified\n", err)
+				hasErrors = true
+			} else {
+				fileModified = true
+				sort.Slice(pending, func(i, j int) bool { return pending[i].file < pending[j].file })
+				for _, result := range pending {
+					if !opts.Quiet {
+						fmt.Fprintf(opts.ErrWriter, "fixed %s\n", result.file)
+					}
+				}
+			}
 		}
 	}
 
+	// Files are processed in parallel, sort the diffs to make the output stable
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].file < diffs[j].file })
+	for _, result := range diffs {
+		fmt.Fprint(opts.OutWriter, result.diff)
+	}
+
+	printRecords(opts, records)
+
 	if hasErrors {
 		return 2
 	}
+	if fileModified && opts.Diff {
+		// Dry run, the files would be modified
+		return 4
+	}
 	if fileModified || opts.Stdout {
 		return 0
 	}
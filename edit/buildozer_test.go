@@ -25,6 +25,7 @@ import (
 	"testing"
 
 	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/tables"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -624,6 +625,72 @@ func TestCmdDictReplaceIfEqual(t *testing.T) {
 	}
 }
 
+func TestCmdBazelDepAdd(t *testing.T) {
+	const input = `module(name = "foo")
+
+bazel_dep(name = "regular", version = "1.0")
+
+bazel_dep(name = "tools", version = "1.0", dev_dependency = True)
+`
+	for _, tc := range []struct {
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			args: []string{"tools", "2.0"},
+			want: `module(name = "foo")
+
+bazel_dep(name = "regular", version = "1.0")
+
+bazel_dep(name = "tools", version = "2.0", dev_dependency = True)
+`,
+		},
+		{
+			args: []string{"dev", "regular", "2.0"},
+			want: `module(name = "foo")
+
+bazel_dep(name = "regular", version = "2.0", dev_dependency = True)
+bazel_dep(name = "tools", version = "1.0", dev_dependency = True)
+`,
+		},
+		{
+			args: []string{"new", "1.0"},
+			want: `module(name = "foo")
+
+bazel_dep(name = "regular", version = "1.0")
+bazel_dep(name = "new", version = "1.0")
+
+bazel_dep(name = "tools", version = "1.0", dev_dependency = True)
+`,
+		},
+		{
+			args:    []string{"dev", "1.0"},
+			wantErr: `bazel_dep_add: expected a module name and a version after "dev"`,
+		},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			f, err := build.ParseModule("MODULE.bazel", []byte(input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err = cmdBazelDepAdd(NewOpts(), CmdEnvironment{File: f, Args: tc.args})
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Errorf("cmdBazelDepAdd() = %v, want error %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("cmdBazelDepAdd() = %v", err)
+			}
+			if got := string(build.Format(f)); got != tc.want {
+				t.Errorf("cmdBazelDepAdd() =\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
 var substituteLoadsTests = []struct {
 	args      []string
 	buildFile string
@@ -742,6 +809,124 @@ func TestCmdSubstitute(t *testing.T) {
 	}
 }
 
+func TestCmdNewAddsLoad(t *testing.T) {
+	defer func(symbolLoadLocations map[string]string, allowedSymbolLoadLocations map[string]map[string]bool) {
+		tables.SymbolLoadLocations = symbolLoadLocations
+		tables.AllowedSymbolLoadLocations = allowedSymbolLoadLocations
+	}(tables.SymbolLoadLocations, tables.AllowedSymbolLoadLocations)
+	tables.SymbolLoadLocations = map[string]string{
+		"java_library": "@rules_java//java:java_library.bzl",
+		"py_library":   "@rules_python//python:defs.bzl",
+	}
+	tables.AllowedSymbolLoadLocations = map[string]map[string]bool{
+		"cc_library": {"@rules_cc//cc:cc_library.bzl": true},
+		"cc_test":    {"@rules_cc//cc:cc_test.bzl": true, "//tools:cc_test.bzl": true},
+	}
+
+	for _, tc := range []struct {
+		name      string
+		args      []string
+		buildFile string
+		expected  string
+	}{
+		{
+			name: "not_loaded",
+			args: []string{"java_library", "foo"},
+			buildFile: `# Comment
+
+cc_library(name = "bar")`,
+			expected: `# Comment
+
+load("@rules_java//java:java_library.bzl", "java_library")
+
+cc_library(name = "bar")
+
+java_library(name = "foo")`,
+		},
+		{
+			name: "already_loaded",
+			args: []string{"java_library", "foo"},
+			buildFile: `load("//tools:java.bzl", "java_library")
+
+java_library(name = "bar")`,
+			expected: `load("//tools:java.bzl", "java_library")
+
+java_library(name = "bar")
+
+java_library(name = "foo")`,
+		},
+		{
+			name: "same_location",
+			args: []string{"py_library", "foo"},
+			buildFile: `load("@rules_python//python:defs.bzl", "py_binary")
+
+py_binary(name = "bar")`,
+			expected: `load("@rules_python//python:defs.bzl", "py_binary", "py_library")
+
+py_binary(name = "bar")
+
+py_library(name = "foo")`,
+		},
+		{
+			name: "defined",
+			args: []string{"py_library", "foo", "before", "bar"},
+			buildFile: `def py_library(**kwargs):
+    pass
+
+py_library(name = "bar")`,
+			expected: `def py_library(**kwargs):
+    pass
+
+py_library(name = "foo")
+
+py_library(name = "bar")`,
+		},
+		{
+			name:      "assigned",
+			args:      []string{"java_library", "foo"},
+			buildFile: `java_library = _java_library`,
+			expected: `java_library = _java_library
+
+java_library(name = "foo")`,
+		},
+		{
+			name:      "allowed_location",
+			args:      []string{"cc_library", "foo"},
+			buildFile: ``,
+			expected: `load("@rules_cc//cc:cc_library.bzl", "cc_library")
+
+cc_library(name = "foo")`,
+		},
+		{
+			name:      "ambiguous_location",
+			args:      []string{"cc_test", "foo"},
+			buildFile: ``,
+			expected:  `cc_test(name = "foo")`,
+		},
+		{
+			name:      "unknown",
+			args:      []string{"genrule", "foo"},
+			buildFile: ``,
+			expected:  `genrule(name = "foo")`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			bld, err := build.Parse("BUILD", []byte(tc.buildFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			bld, err = cmdNew(NewOpts(), CmdEnvironment{File: bld, Args: tc.args})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := strings.TrimSpace(string(build.Format(bld)))
+			if got != tc.expected {
+				t.Errorf("cmdNew(%v):\ngot:\n%s\nexpected:\n%s", tc.args, got, tc.expected)
+			}
+		})
+	}
+}
+
 func TestCmdDictAddSet_missingColon(t *testing.T) {
 	for _, tc := range []struct {
 		name string
@@ -1329,3 +1514,310 @@ func TestSplitOnNonEscaped(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildozerDiff(t *testing.T) {
+	tmp := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmp, "WORKSPACE"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmp, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	input := `cc_library(
+    name = "a",
+    deps = [":b"],
+)
+`
+	buildFile := filepath.Join(tmp, "pkg", "BUILD")
+	if err := os.WriteFile(buildFile, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantDiff string
+	}{
+		{
+			name:     "changes",
+			args:     []string{"add deps :c", "//pkg:a"},
+			wantCode: 4,
+			wantDiff: `--- a/pkg/BUILD
++++ b/pkg/BUILD
+@@ -1,4 +1,7 @@
+ cc_library(
+     name = "a",
+-    deps = [":b"],
++    deps = [
++        ":b",
++        ":c",
++    ],
+ )
+`,
+		},
+		{
+			name:     "no changes",
+			args:     []string{"add deps :b", "//pkg:a"},
+			wantCode: 3,
+			wantDiff: "",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			opts := NewOpts()
+			opts.RootDir = tmp
+			opts.Diff = true
+			opts.Quiet = true
+			opts.OutWriter = &stdout
+			opts.ErrWriter = &stderr
+
+			if code := Buildozer(opts, tc.args); code != tc.wantCode {
+				t.Errorf("Buildozer() = %d, want %d, stderr: %s", code, tc.wantCode, stderr.String())
+			}
+			if diff := cmp.Diff(tc.wantDiff, stdout.String()); diff != "" {
+				t.Errorf("Buildozer() output diff -want +got:\n%s", diff)
+			}
+			data, err := os.ReadFile(buildFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != input {
+				t.Errorf("the BUILD file has been modified in the diff mode:\n%s", data)
+			}
+		})
+	}
+}
+
+func TestBuildozerAtomic(t *testing.T) {
+	input := `cc_library(
+    name = "a",
+)
+`
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		wantCode int
+		want     string
+	}{
+		{
+			name:     "success",
+			args:     []string{"add deps :c", "//pkg1:a", "//pkg2:a"},
+			wantCode: 0,
+			want: `cc_library(
+    name = "a",
+    deps = [":c"],
+)
+`,
+		},
+		{
+			name:     "failure",
+			args:     []string{"add deps :c", "//pkg1:a", "//pkg2:missing"},
+			wantCode: 2,
+			want:     input,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			if err := os.WriteFile(filepath.Join(tmp, "WORKSPACE"), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+			for _, pkg := range []string{"pkg1", "pkg2"} {
+				if err := os.MkdirAll(filepath.Join(tmp, pkg), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(tmp, pkg, "BUILD"), []byte(input), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var stdout, stderr strings.Builder
+			opts := NewOpts()
+			opts.RootDir = tmp
+			opts.Atomic = true
+			opts.OutWriter = &stdout
+			opts.ErrWriter = &stderr
+			if code := Buildozer(opts, tc.args); code != tc.wantCode {
+				t.Errorf("Buildozer() = %d, want %d, stderr: %s", code, tc.wantCode, stderr.String())
+			}
+
+			for _, pkg := range []string{"pkg1", "pkg2"} {
+				data, err := os.ReadFile(filepath.Join(tmp, pkg, "BUILD"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(tc.want, string(data)); diff != "" {
+					t.Errorf("%s/BUILD diff -want +got:\n%s", pkg, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildozerPreserveFormatting(t *testing.T) {
+	input := `# Unformatted but untouched statements are kept as they are.
+load("//tools:defs.bzl",   "cc_library")
+
+cc_library(name = "a", srcs = ["b.cc", "a.cc"])
+
+
+cc_library(
+    name = "b",
+    srcs = ['b.cc'],   # sources
+)
+`
+	other := `cc_library(name="x",srcs=["z.cc","y.cc"])
+
+cc_library(name = "o", deps = ["//:b"])
+`
+	for _, tc := range []struct {
+		name      string
+		preserve  bool
+		args      []string
+		want      string
+		wantOther string
+	}{
+		{
+			name:      "preserve",
+			preserve:  true,
+			args:      []string{"add deps :a", "//:b"},
+			wantOther: other,
+			want: `# Unformatted but untouched statements are kept as they are.
+load("//tools:defs.bzl",   "cc_library")
+
+cc_library(name = "a", srcs = ["b.cc", "a.cc"])
+
+
+cc_library(
+    name = "b",
+    srcs = ['b.cc'],   # sources
+    deps = [":a"],
+)
+`,
+		},
+		{
+			name:      "format",
+			preserve:  false,
+			args:      []string{"add deps :a", "//:b"},
+			wantOther: other,
+			want: `# Unformatted but untouched statements are kept as they are.
+load("//tools:defs.bzl", "cc_library")
+
+cc_library(
+    name = "a",
+    srcs = [
+        "a.cc",
+        "b.cc",
+    ],
+)
+
+cc_library(
+    name = "b",
+    srcs = ["b.cc"],  # sources
+    deps = [":a"],
+)
+`,
+		},
+		{
+			name:     "preserve workspace command",
+			preserve: true,
+			args:     []string{"rename_target c", "//:b"},
+			want: `# Unformatted but untouched statements are kept as they are.
+load("//tools:defs.bzl",   "cc_library")
+
+cc_library(name = "a", srcs = ["b.cc", "a.cc"])
+
+
+cc_library(
+    name = "c",
+    srcs = ['b.cc'],   # sources
+)
+`,
+			wantOther: `cc_library(name="x",srcs=["z.cc","y.cc"])
+
+cc_library(name = "o", deps = ["//:c"])
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			if err := os.WriteFile(filepath.Join(tmp, "WORKSPACE"), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(tmp, "BUILD"), []byte(input), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(filepath.Join(tmp, "other"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(tmp, "other", "BUILD"), []byte(other), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			var stdout, stderr strings.Builder
+			opts := NewOpts()
+			opts.RootDir = tmp
+			opts.PreserveFormatting = tc.preserve
+			opts.OutWriter = &stdout
+			opts.ErrWriter = &stderr
+			if code := Buildozer(opts, tc.args); code != 0 {
+				t.Errorf("Buildozer() = %d, want 0, stderr: %s", code, stderr.String())
+			}
+
+			data, err := os.ReadFile(filepath.Join(tmp, "BUILD"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, string(data)); diff != "" {
+				t.Errorf("BUILD diff -want +got:\n%s", diff)
+			}
+			data, err = os.ReadFile(filepath.Join(tmp, "other", "BUILD"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.wantOther, string(data)); diff != "" {
+				t.Errorf("other/BUILD diff -want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCommitFilesRollback(t *testing.T) {
+	tmp := t.TempDir()
+	first := filepath.Join(tmp, "a")
+	if err := os.WriteFile(first, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A file can't replace a non-empty directory
+	second := filepath.Join(tmp, "b")
+	if err := os.MkdirAll(filepath.Join(second, "c"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := os.Stat(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = commitFiles([]*pendingWrite{
+		{name: second, fi: fi, oldData: []byte("old"), newData: []byte("new")},
+		{name: first, fi: fi, oldData: []byte("old"), newData: []byte("new")},
+	})
+	if err == nil {
+		t.Fatal("commitFiles() succeeded, want an error")
+	}
+	data, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "old" {
+		t.Errorf("the file hasn't been restored, got %q", data)
+	}
+	entries, err := os.ReadDir(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("temporary files haven't been removed: %v", entries)
+	}
+}
@@ -115,7 +115,10 @@ func NewUseRepo(f *build.File, proxies []string) (*build.File, *build.CallExpr)
 	}
 	stmt := append(f.Stmt[:lastUsage+1], append([]build.Expr{useRepo}, f.Stmt[lastUsage+1:]...)...)
 
-	return &build.File{Path: f.Path, Comments: f.Comments, Stmt: stmt, Type: build.TypeModule}, useRepo
+	newF := *f
+	newF.Stmt = stmt
+	newF.Type = build.TypeModule
+	return &newF, useRepo
 }
 
 // AddRepoUsages adds the given repos to the given use_repo calls without introducing duplicate
@@ -182,6 +185,112 @@ func RemoveRepoUsages(useRepos []*build.CallExpr, repos ...string) {
 	}
 }
 
+// BazelDep returns the bazel_dep call for the module with the given name, or nil if there is none.
+func BazelDep(f *build.File, name string) *build.Rule {
+	for _, dep := range f.Rules("bazel_dep") {
+		if dep.AttrString("name") == name {
+			return dep
+		}
+	}
+	return nil
+}
+
+// NewBazelDep inserts and returns a new bazel_dep call for the module with the given name and
+// the given value of the dev_dependency attribute. The call is inserted after the last bazel_dep
+// call with the same value of dev_dependency, or after the last bazel_dep call, or after the
+// module call, or after the leading comments of the file, whichever is found first.
+func NewBazelDep(f *build.File, name string, dev bool) (*build.File, *build.Rule) {
+	insertAfter := -1
+	for i, stmt := range f.Stmt {
+		if _, ok := stmt.(*build.CommentBlock); ok && insertAfter == i-1 {
+			insertAfter = i
+		}
+	}
+	lastDep, lastSameDep := -1, -1
+	for i, stmt := range f.Stmt {
+		call, ok := stmt.(*build.CallExpr)
+		if !ok {
+			continue
+		}
+		ident, ok := call.X.(*build.Ident)
+		if !ok {
+			continue
+		}
+		switch ident.Name {
+		case "module":
+			insertAfter = i
+		case "bazel_dep":
+			lastDep = i
+			isDev := false
+			for _, arg := range call.List {
+				isDev = isDev || parseBooleanKeywordArg(arg, "dev_dependency")
+			}
+			if isDev == dev {
+				lastSameDep = i
+			}
+		}
+	}
+	if lastSameDep != -1 {
+		insertAfter = lastSameDep
+	} else if lastDep != -1 {
+		insertAfter = lastDep
+	}
+
+	call := &build.CallExpr{X: &build.Ident{Name: "bazel_dep"}}
+	dep := &build.Rule{Call: call}
+	dep.SetAttr("name", &build.StringExpr{Value: name})
+	if dev {
+		dep.SetAttr("dev_dependency", &build.Ident{Name: "True"})
+	}
+	stmt := append(f.Stmt[:insertAfter+1:insertAfter+1], append([]build.Expr{call}, f.Stmt[insertAfter+1:]...)...)
+
+	newF := *f
+	newF.Stmt = stmt
+	newF.Type = build.TypeModule
+	return &newF, dep
+}
+
+// Tags returns the tag calls with the given tag name that use one of the given proxies.
+func Tags(f *build.File, proxies []string, tagName string) []*build.CallExpr {
+	proxiesSet := make(map[string]struct{})
+	for _, p := range proxies {
+		proxiesSet[p] = struct{}{}
+	}
+
+	var tags []*build.CallExpr
+	for _, stmt := range f.Stmt {
+		proxy := parseTag(stmt)
+		if _, ok := proxiesSet[proxy]; !ok {
+			continue
+		}
+		call := stmt.(*build.CallExpr)
+		if call.X.(*build.DotExpr).Name == tagName {
+			tags = append(tags, call)
+		}
+	}
+
+	return tags
+}
+
+// NewTag inserts and returns a new tag call with the given tag name after the last usage of any of
+// the given proxies, where a usage is either a use_extension call or a tag definition.
+func NewTag(f *build.File, proxies []string, tagName string) (*build.File, *build.CallExpr) {
+	lastUsage, proxy := lastProxyUsage(f, proxies)
+	if lastUsage == -1 {
+		return f, nil
+	}
+
+	tag := &build.CallExpr{
+		X: &build.DotExpr{X: &build.Ident{Name: proxy}, Name: tagName},
+	}
+	stmt := append(f.Stmt[:lastUsage+1:lastUsage+1], append([]build.Expr{tag}, f.Stmt[lastUsage+1:]...)...)
+
+	newF := *f
+	newF.Stmt = stmt
+	newF.Type = build.TypeModule
+	return &newF, tag
+}
+
 func getLastUseRepo(useRepos []*build.CallExpr) *build.CallExpr {
 	var lastUseRepo *build.CallExpr
 	for _, useRepo := range useRepos {
@@ -547,3 +547,217 @@ use_repo(prox)
 		})
 	}
 }
+
+func TestNewBazelDep(t *testing.T) {
+	for i, tc := range []struct {
+		content         string
+		dev             bool
+		expectedContent string
+	}{
+		{
+			``,
+			false,
+			`bazel_dep(name = "dep")
+`,
+		},
+		{
+			`# Comment
+
+# Another comment
+
+prox = use_extension("@mod//:extensions.bzl", "ext")`,
+			false,
+			`# Comment
+
+# Another comment
+
+bazel_dep(name = "dep")
+
+prox = use_extension("@mod//:extensions.bzl", "ext")
+`,
+		},
+		{
+			`module(name = "foo")
+
+prox = use_extension("@mod//:extensions.bzl", "ext")`,
+			false,
+			`module(name = "foo")
+
+bazel_dep(name = "dep")
+
+prox = use_extension("@mod//:extensions.bzl", "ext")
+`,
+		},
+		{
+			`module(name = "foo")
+
+bazel_dep(name = "bar", version = "1.0")
+bazel_dep(name = "baz", version = "2.0")
+
+prox = use_extension("@mod//:extensions.bzl", "ext")`,
+			false,
+			`module(name = "foo")
+
+bazel_dep(name = "bar", version = "1.0")
+bazel_dep(name = "baz", version = "2.0")
+bazel_dep(name = "dep")
+
+prox = use_extension("@mod//:extensions.bzl", "ext")
+`,
+		},
+		{
+			`module(name = "foo")
+
+bazel_dep(name = "bar", version = "1.0")
+
+bazel_dep(name = "baz", version = "2.0", dev_dependency = True)
+
+bazel_dep(name = "qux", version = "3.0")`,
+			true,
+			`module(name = "foo")
+
+bazel_dep(name = "bar", version = "1.0")
+
+bazel_dep(name = "baz", version = "2.0", dev_dependency = True)
+bazel_dep(name = "dep", dev_dependency = True)
+
+bazel_dep(name = "qux", version = "3.0")
+`,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			f, err := build.ParseModule("MODULE.bazel", []byte(tc.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, dep := NewBazelDep(f, "dep", tc.dev)
+			if actualDep := BazelDep(f, "dep"); actualDep == nil || actualDep.Call != dep.Call {
+				t.Error("want: ", dep.Call, ", got: ", actualDep)
+			}
+			actualContent := string(build.Format(f))
+			if !reflect.DeepEqual(actualContent, tc.expectedContent) {
+				t.Errorf("want:\n%q\ngot:\n%q\n", tc.expectedContent, actualContent)
+			}
+		})
+	}
+}
+
+const tagsFile = `
+prox1 = use_extension("@mod//:extensions.bzl", "ext")
+prox1.install(name = "foo")
+prox1.config(strict = True)
+
+prox2 = use_extension("@mod//:extensions.bzl", "ext")
+prox2.install(name = "bar")
+
+use_repo(prox1, "foo")
+
+prox3 = use_extension("@mod//:extensions.bzl", "ext", dev_dependency = True)
+prox3.install(name = "baz")
+`
+
+func TestTags(t *testing.T) {
+	for i, tc := range []struct {
+		proxies       []string
+		tagName       string
+		expectedStmts []int
+	}{
+		{
+			[]string{"prox1"},
+			"install",
+			[]int{1},
+		},
+		{
+			[]string{"prox1", "prox2"},
+			"install",
+			[]int{1, 4},
+		},
+		{
+			[]string{"prox2", "prox3"},
+			"config",
+			nil,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			f, err := build.ParseModule("MODULE.bazel", []byte(tagsFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var expectedTags []*build.CallExpr
+			for _, stmt := range tc.expectedStmts {
+				expectedTags = append(expectedTags, f.Stmt[stmt].(*build.CallExpr))
+			}
+			actualTags := Tags(f, tc.proxies, tc.tagName)
+			if !reflect.DeepEqual(actualTags, expectedTags) {
+				t.Error("want: ", expectedTags, ", got: ", actualTags)
+			}
+		})
+	}
+}
+
+func TestNewTag(t *testing.T) {
+	for i, tc := range []struct {
+		proxies         []string
+		expectedContent string
+		expectedTag     int
+	}{
+		{
+			[]string{"prox4"},
+			tagsFile[1:],
+			-1,
+		},
+		{
+			[]string{"prox1", "prox2"},
+			`prox1 = use_extension("@mod//:extensions.bzl", "ext")
+prox1.install(name = "foo")
+prox1.config(strict = True)
+
+prox2 = use_extension("@mod//:extensions.bzl", "ext")
+prox2.install(name = "bar")
+prox2.install()
+
+use_repo(prox1, "foo")
+
+prox3 = use_extension("@mod//:extensions.bzl", "ext", dev_dependency = True)
+prox3.install(name = "baz")
+`,
+			5,
+		},
+		{
+			[]string{"prox3"},
+			`prox1 = use_extension("@mod//:extensions.bzl", "ext")
+prox1.install(name = "foo")
+prox1.config(strict = True)
+
+prox2 = use_extension("@mod//:extensions.bzl", "ext")
+prox2.install(name = "bar")
+
+use_repo(prox1, "foo")
+
+prox3 = use_extension("@mod//:extensions.bzl", "ext", dev_dependency = True)
+prox3.install(name = "baz")
+prox3.install()
+`,
+			8,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			f, err := build.ParseModule("MODULE.bazel", []byte(tagsFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, actualNewTag := NewTag(f, tc.proxies, "install")
+			actualContent := string(build.Format(f))
+			if actualNewTag != nil {
+				if !reflect.DeepEqual(actualNewTag, f.Stmt[tc.expectedTag]) {
+					t.Error("want: ", f.Stmt[tc.expectedTag], ", got: ", actualNewTag)
+				}
+			} else if tc.expectedTag != -1 {
+				t.Error("wanted a nil new tag")
+			}
+			if !reflect.DeepEqual(actualContent, tc.expectedContent) {
+				t.Errorf("want:\n%q\ngot:\n%q\n", tc.expectedContent, actualContent)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bzlmod
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/labels"
+	"github.com/bazelbuild/buildtools/tables"
+)
+
+// MigrationNote describes a WORKSPACE statement that couldn't be converted automatically or whose
+// conversion should be checked manually.
+type MigrationNote struct {
+	Line    int
+	Message string
+}
+
+// repoRuleBzlFiles maps the names of the repository rules defined in @bazel_tools to the files
+// defining them.
+var repoRuleBzlFiles = map[string]string{
+	"git_repository":       "@bazel_tools//tools/build_defs/repo:git.bzl",
+	"http_archive":         "@bazel_tools//tools/build_defs/repo:http.bzl",
+	"http_file":            "@bazel_tools//tools/build_defs/repo:http.bzl",
+	"http_jar":             "@bazel_tools//tools/build_defs/repo:http.bzl",
+	"local_repository":     "@bazel_tools//tools/build_defs/repo:local.bzl",
+	"new_git_repository":   "@bazel_tools//tools/build_defs/repo:git.bzl",
+	"new_local_repository": "@bazel_tools//tools/build_defs/repo:local.bzl",
+}
+
+// workspaceMacro describes a well-known WORKSPACE macro.
+type workspaceMacro struct {
+	module    string // The module that provides the repositories created by the macro
+	extension string // The module extension replacing the macro, empty if it's not needed
+}
+
+// workspaceMacros contains the well-known WORKSPACE macros and their Bzlmod replacements.
+var workspaceMacros = map[string]workspaceMacro{
+	"aspect_bazel_lib_dependencies": {"aspect_bazel_lib", ""},
+	"bazel_features_deps":           {"bazel_features", ""},
+	"bazel_skylib_workspace":        {"bazel_skylib", ""},
+	"gazelle_dependencies":          {"gazelle", ""},
+	"go_register_toolchains":        {"rules_go", "go_sdk"},
+	"go_repository":                 {"gazelle", "go_deps"},
+	"go_rules_dependencies":         {"rules_go", ""},
+	"grpc_deps":                     {"grpc", ""},
+	"grpc_extra_deps":               {"grpc", ""},
+	"maven_install":                 {"rules_jvm_external", "maven"},
+	"npm_translate_lock":            {"aspect_rules_js", "npm"},
+	"pip_install":                   {"rules_python", "pip"},
+	"pip_parse":                     {"rules_python", "pip"},
+	"protobuf_deps":                 {"protobuf", ""},
+	"py_repositories":               {"rules_python", ""},
+	"python_register_toolchains":    {"rules_python", "python"},
+	"rules_cc_dependencies":         {"rules_cc", ""},
+	"rules_cc_toolchains":           {"rules_cc", ""},
+	"rules_foreign_cc_dependencies": {"rules_foreign_cc", ""},
+	"rules_java_dependencies":       {"rules_java", ""},
+	"rules_java_toolchains":         {"rules_java", ""},
+	"rules_js_dependencies":         {"aspect_rules_js", ""},
+	"rules_jvm_external_deps":       {"rules_jvm_external", ""},
+	"rules_jvm_external_setup":      {"rules_jvm_external", ""},
+	"rules_pkg_dependencies":        {"rules_pkg", ""},
+	"rules_proto_dependencies":      {"rules_proto", ""},
+	"rules_proto_toolchains":        {"rules_proto", ""},
+	"rules_rust_dependencies":       {"rules_rust", ""},
+	"rules_shell_dependencies":      {"rules_shell", ""},
+	"rules_shell_toolchains":        {"rules_shell", ""},
+	"rust_register_toolchains":      {"rules_rust", "rust"},
+	"stardoc_repositories":          {"stardoc", ""},
+}
+
+// versionRegexp matches version numbers in archive names, URLs and tags, e.g. "rules_go-v0.41.0".
+var versionRegexp = regexp.MustCompile(`(?:^|[^0-9A-Za-z])v?(\d+(?:\.\d+)+)`)
+
+// loadedSymbol is a symbol loaded in a WORKSPACE file.
+type loadedSymbol struct {
+	repo string // The repository of the loaded file
+	name string // The original name of the symbol
+}
+
+// workspaceMigration holds the state of the conversion of a WORKSPACE file.
+type workspaceMigration struct {
+	loads         map[string]loadedSymbol
+	module        *build.CallExpr
+	deps          []build.Expr
+	repos         []build.Expr
+	registrations []build.Expr
+	modules       map[string]bool   // Modules added as bazel_dep
+	convertedRepo map[string]string // WORKSPACE repositories converted to bazel_dep, mapped to modules
+	repoRules     map[string]bool   // Repository rules declared with use_repo_rule
+	notes         []MigrationNote
+}
+
+// MigrateWorkspace converts a WORKSPACE file to a draft MODULE.bazel file. Repositories known to
+// be available as modules (see tables.WorkspaceRepoToModule) are converted to bazel_dep calls,
+// other repository rules from @bazel_tools are declared with use_repo_rule, and toolchain and
+// execution platform registrations are kept. Everything else is described in the returned notes,
+// which are sorted by line.
+func MigrateWorkspace(f *build.File) (*build.File, []MigrationNote) {
+	m := &workspaceMigration{
+		loads:         make(map[string]loadedSymbol),
+		modules:       make(map[string]bool),
+		convertedRepo: make(map[string]string),
+		repoRules:     make(map[string]bool),
+	}
+	for _, stmt := range f.Stmt {
+		load, ok := stmt.(*build.LoadStmt)
+		if !ok {
+			continue
+		}
+		repo := labels.Parse(load.Module.Value).Repository
+		for i, to := range load.To {
+			m.loads[to.Name] = loadedSymbol{repo: repo, name: load.From[i].Name}
+		}
+	}
+
+	for _, stmt := range f.Stmt {
+		switch stmt := stmt.(type) {
+		case *build.LoadStmt, *build.CommentBlock:
+			// Loads are resolved above, comments that aren't attached to statements are dropped.
+		case *build.CallExpr:
+			m.migrateCall(f, stmt)
+		default:
+			m.addNote(stmt, "the statement isn't converted, MODULE.bazel files can only contain function calls and use_extension or use_repo_rule assignments")
+		}
+	}
+
+	var stmts []build.Expr
+	if m.module != nil {
+		stmts = append(stmts, m.module)
+	}
+	stmts = append(stmts, m.deps...)
+	stmts = append(stmts, m.repos...)
+	stmts = append(stmts, m.registrations...)
+
+	sort.SliceStable(m.notes, func(i, j int) bool { return m.notes[i].Line < m.notes[j].Line })
+	return &build.File{Path: "MODULE.bazel", Stmt: stmts, Type: build.TypeModule}, m.notes
+}
+
+func (m *workspaceMigration) addNote(node build.Expr, format string, args ...interface{}) {
+	start, _ := node.Span()
+	m.notes = append(m.notes, MigrationNote{Line: start.Line, Message: fmt.Sprintf(format, args...)})
+}
+
+// repoRule returns the original name of the @bazel_tools repository rule called with the given
+// name in the WORKSPACE file, or an empty string if it's not such a rule.
+func (m *workspaceMigration) repoRule(name string) string {
+	symbol, ok := m.loads[name]
+	if !ok {
+		// local_repository and new_local_repository are also available natively
+		if name == "local_repository" || name == "new_local_repository" {
+			return name
+		}
+		return ""
+	}
+	if symbol.repo != "bazel_tools" || repoRuleBzlFiles[symbol.name] == "" {
+		return ""
+	}
+	return symbol.name
+}
+
+func (m *workspaceMigration) migrateCall(f *build.File, call *build.CallExpr) {
+	ident, ok := call.X.(*build.Ident)
+	if !ok {
+		m.addNote(call, "the call isn't converted")
+		return
+	}
+
+	// maybe(http_archive, name = ...) is equivalent to http_archive(name = ...)
+	if symbol, ok := m.loads[ident.Name]; ok && symbol.repo == "bazel_tools" && symbol.name == "maybe" && len(call.List) > 0 {
+		if rule, ok := call.List[0].(*build.Ident); ok {
+			newCall := *call
+			newCall.X = rule
+			newCall.List = call.List[1:]
+			call, ident = &newCall, rule
+		}
+	}
+
+	switch ident.Name {
+	case "workspace":
+		if name := f.Rule(call).AttrString("name"); name != "" {
+			m.module = &build.CallExpr{X: &build.Ident{Name: "module"}}
+			(&build.Rule{Call: m.module}).SetAttr("name", &build.StringExpr{Value: name})
+		}
+		return
+	case "register_toolchains", "register_execution_platforms":
+		m.registrations = append(m.registrations, call)
+		return
+	case "bind":
+		m.addNote(call, "bind() isn't supported with Bzlmod, use alias targets instead")
+		return
+	}
+
+	if rule := m.repoRule(ident.Name); rule != "" {
+		m.migrateRepo(f, call, rule)
+		return
+	}
+
+	if macro, ok := workspaceMacros[ident.Name]; ok {
+		if macro.extension == "" {
+			m.addNote(call, "%s() isn't needed with Bzlmod, the repositories it creates are managed by the %q module", ident.Name, macro.module)
+		} else {
+			m.addNote(call, "%s() has to be replaced with the %q module extension of the %q module", ident.Name, macro.extension, macro.module)
+		}
+		return
+	}
+	if symbol, ok := m.loads[ident.Name]; ok {
+		if module, ok := m.convertedRepo[symbol.repo]; ok {
+			m.addNote(call, "%s() from @%s isn't needed with Bzlmod if it only declares the dependencies of the %q module, otherwise use one of its module extensions", ident.Name, symbol.repo, module)
+			return
+		}
+	}
+	m.addNote(call, "%s() isn't converted, macros can't be called in MODULE.bazel files, use a module extension instead", ident.Name)
+}
+
+func (m *workspaceMigration) migrateRepo(f *build.File, call *build.CallExpr, rule string) {
+	r := f.Rule(call)
+	name := r.AttrString("name")
+	if name == "" {
+		m.addNote(call, "%s() without a name isn't converted", rule)
+		return
+	}
+
+	if module := workspaceRepoModule(name); module != "" {
+		if m.modules[module] {
+			m.addNote(call, "repository %q isn't converted, a bazel_dep on the %q module already exists", name, module)
+			return
+		}
+		m.modules[module] = true
+		m.convertedRepo[name] = module
+
+		dep := &build.Rule{Call: &build.CallExpr{X: &build.Ident{Name: "bazel_dep"}}}
+		dep.SetAttr("name", &build.StringExpr{Value: module})
+		if version := repoVersion(r); version != "" {
+			dep.SetAttr("version", &build.StringExpr{Value: version})
+		} else {
+			m.addNote(call, "the version of the %q module couldn't be determined from %s(name = %q), please set it", module, rule, name)
+		}
+		if name != module {
+			dep.SetAttr("repo_name", &build.StringExpr{Value: name})
+		}
+		if r.Attr("patches") != nil {
+			m.addNote(call, "the patches of repository %q aren't converted, apply them with single_version_override on the %q module", name, module)
+		}
+		m.deps = append(m.deps, dep.Call)
+		return
+	}
+
+	if !m.repoRules[rule] {
+		m.repoRules[rule] = true
+		m.repos = append(m.repos, &build.AssignExpr{
+			LHS: &build.Ident{Name: rule},
+			Op:  "=",
+			RHS: &build.CallExpr{
+				X:            &build.Ident{Name: "use_repo_rule"},
+				ForceCompact: true,
+				List: []build.Expr{
+					&build.StringExpr{Value: repoRuleBzlFiles[rule]},
+					&build.StringExpr{Value: rule},
+				},
+			},
+		})
+	}
+	newCall := *call
+	newCall.X = &build.Ident{Name: rule}
+	m.repos = append(m.repos, &newCall)
+}
+
+// workspaceRepoModule returns the name of the module corresponding to a WORKSPACE repository, or
+// an empty string if it's unknown.
+func workspaceRepoModule(repo string) string {
+	if module, ok := tables.WorkspaceRepoToModule[repo]; ok {
+		return module
+	}
+	for module, legacyName := range tables.ModuleToLegacyRepoName {
+		if legacyName == repo {
+			return module
+		}
+	}
+	return ""
+}
+
+// repoVersion guesses the version of a repository from its tag, strip_prefix or URLs.
+func repoVersion(r *build.Rule) string {
+	candidates := []string{r.AttrString("tag"), r.AttrString("strip_prefix"), r.AttrString("url")}
+	candidates = append(candidates, r.AttrStrings("urls")...)
+	for _, candidate := range candidates {
+		if match := versionRegexp.FindStringSubmatch(candidate); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
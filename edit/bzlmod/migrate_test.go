@@ -0,0 +1,186 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bzlmod
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/tables"
+)
+
+func TestMigrateWorkspace(t *testing.T) {
+	workspace := `workspace(name = "my_project")
+
+load("@bazel_tools//tools/build_defs/repo:http.bzl", "http_archive", "http_file")
+load("@bazel_tools//tools/build_defs/repo:git.bzl", "git_repository")
+load("@bazel_tools//tools/build_defs/repo:utils.bzl", "maybe")
+
+http_archive(
+    name = "io_bazel_rules_go",
+    sha256 = "278b7ff5a826f3dc10f04feaf0b70d48b68748ccd512d7f98bf442077f043fe3",
+    urls = ["https://github.com/bazelbuild/rules_go/releases/download/v0.41.0/rules_go-v0.41.0.zip"],
+)
+
+load("@io_bazel_rules_go//go:deps.bzl", "go_register_toolchains", "go_rules_dependencies")
+
+go_rules_dependencies()
+
+go_register_toolchains(version = "1.20.5")
+
+maybe(
+    http_archive,
+    name = "bazel_skylib",
+    strip_prefix = "bazel-skylib-1.4.2",
+    urls = ["https://example.com/bazel-skylib.tar.gz"],
+)
+
+git_repository(
+    name = "rules_pkg",
+    commit = "abcdef",
+    remote = "https://github.com/bazelbuild/rules_pkg.git",
+)
+
+http_archive(
+    name = "my_lib",
+    build_file = "//third_party:my_lib.BUILD",
+    urls = ["https://example.com/my_lib-2.0.tar.gz"],
+)
+
+http_file(
+    name = "my_data",
+    urls = ["https://example.com/data.bin"],
+)
+
+local_repository(
+    name = "local_lib",
+    path = "third_party/local_lib",
+)
+
+load("@my_lib//:deps.bzl", "my_lib_deps")
+
+my_lib_deps()
+
+bind(
+    name = "lib",
+    actual = "@my_lib//:lib",
+)
+
+register_toolchains("//toolchains:all")
+
+LIBS = ["a", "b"]
+`
+	expected := `module(name = "my_project")
+
+bazel_dep(name = "rules_go", version = "0.41.0", repo_name = "io_bazel_rules_go")
+bazel_dep(name = "bazel_skylib", version = "1.4.2")
+bazel_dep(name = "rules_pkg")
+
+http_archive = use_repo_rule("@bazel_tools//tools/build_defs/repo:http.bzl", "http_archive")
+
+http_archive(
+    name = "my_lib",
+    build_file = "//third_party:my_lib.BUILD",
+    urls = ["https://example.com/my_lib-2.0.tar.gz"],
+)
+
+http_file = use_repo_rule("@bazel_tools//tools/build_defs/repo:http.bzl", "http_file")
+
+http_file(
+    name = "my_data",
+    urls = ["https://example.com/data.bin"],
+)
+
+local_repository = use_repo_rule("@bazel_tools//tools/build_defs/repo:local.bzl", "local_repository")
+
+local_repository(
+    name = "local_lib",
+    path = "third_party/local_lib",
+)
+
+register_toolchains("//toolchains:all")
+`
+	expectedNotes := []MigrationNote{
+		{15, `go_rules_dependencies() isn't needed with Bzlmod, the repositories it creates are managed by the "rules_go" module`},
+		{17, `go_register_toolchains() has to be replaced with the "go_sdk" module extension of the "rules_go" module`},
+		{26, `the version of the "rules_pkg" module couldn't be determined from git_repository(name = "rules_pkg"), please set it`},
+		{50, `my_lib_deps() isn't converted, macros can't be called in MODULE.bazel files, use a module extension instead`},
+		{52, `bind() isn't supported with Bzlmod, use alias targets instead`},
+		{59, `the statement isn't converted, MODULE.bazel files can only contain function calls and use_extension or use_repo_rule assignments`},
+	}
+
+	f, err := build.ParseWorkspace("WORKSPACE", []byte(workspace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	module, notes := MigrateWorkspace(f)
+	if got := string(build.Format(module)); got != expected {
+		t.Errorf("MigrateWorkspace() =\n%s\nwant:\n%s", got, expected)
+	}
+	if !reflect.DeepEqual(notes, expectedNotes) {
+		t.Errorf("MigrateWorkspace() notes =\n%v\nwant:\n%v", notes, expectedNotes)
+	}
+}
+
+func TestMigrateWorkspaceCustomTable(t *testing.T) {
+	defer func(table map[string]string) { tables.WorkspaceRepoToModule = table }(tables.WorkspaceRepoToModule)
+	tables.WorkspaceRepoToModule = map[string]string{"com_example_foo": "foo"}
+
+	f, err := build.ParseWorkspace("WORKSPACE", []byte(`
+load("@bazel_tools//tools/build_defs/repo:git.bzl", "git_repository")
+
+git_repository(
+    name = "com_example_foo",
+    tag = "v1.2.3",
+    patches = ["//:foo.patch"],
+    remote = "https://example.com/foo.git",
+)
+
+load("@com_example_foo//:deps.bzl", "foo_deps")
+
+foo_deps()
+
+git_repository(
+    name = "foo",
+    tag = "v1.2.4",
+    remote = "https://example.com/foo.git",
+)
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	module, notes := MigrateWorkspace(f)
+	expected := "bazel_dep(name = \"foo\", version = \"1.2.3\", repo_name = \"com_example_foo\")\n\n" +
+		"git_repository = use_repo_rule(\"@bazel_tools//tools/build_defs/repo:git.bzl\", \"git_repository\")\n\n" +
+		"git_repository(\n    name = \"foo\",\n    remote = \"https://example.com/foo.git\",\n    tag = \"v1.2.4\",\n)\n"
+	if got := string(build.Format(module)); got != expected {
+		t.Errorf("MigrateWorkspace() =\n%s\nwant:\n%s", got, expected)
+	}
+	var messages []string
+	for _, note := range notes {
+		messages = append(messages, note.Message)
+	}
+	expectedMessages := []string{
+		`the patches of repository "com_example_foo" aren't converted, apply them with single_version_override on the "foo" module`,
+		`foo_deps() from @com_example_foo isn't needed with Bzlmod if it only declares the dependencies of the "foo" module, otherwise use one of its module extensions`,
+	}
+	if strings.Join(messages, "\n") != strings.Join(expectedMessages, "\n") {
+		t.Errorf("MigrateWorkspace() notes =\n%s\nwant:\n%s", strings.Join(messages, "\n"), strings.Join(expectedMessages, "\n"))
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edit
+
+// Writing the changes of several files at once, for the atomic mode.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// pendingWrite is a change of a file that hasn't been written yet.
+type pendingWrite struct {
+	name    string
+	fi      os.FileInfo
+	oldData []byte
+	newData []byte
+	tmpName string
+}
+
+// commitFiles replaces the contents of all the files or none of them. The new
+// contents are first written to temporary files next to the original files,
+// which are then renamed. If a file can't be replaced, the files that have
+// already been replaced are restored.
+func commitFiles(writes []*pendingWrite) error {
+	sort.Slice(writes, func(i, j int) bool { return writes[i].name < writes[j].name })
+	defer func() {
+		for _, w := range writes {
+			if w.tmpName != "" {
+				os.Remove(w.tmpName)
+			}
+		}
+	}()
+
+	for _, w := range writes {
+		if err := EditFile(w.fi, w.name); err != nil {
+			return fmt.Errorf("%s: %v", w.name, err)
+		}
+		tmpName, err := writeTempFile(w.name, w.newData, w.fi)
+		if err != nil {
+			return fmt.Errorf("%s: %v", w.name, err)
+		}
+		w.tmpName = tmpName
+	}
+
+	for i, w := range writes {
+		if err := os.Rename(w.tmpName, w.name); err != nil {
+			err = fmt.Errorf("%s: %v", w.name, err)
+			if rollbackErr := rollback(writes[:i]); rollbackErr != nil {
+				return fmt.Errorf("%v; restoring the original files: %v", err, rollbackErr)
+			}
+			return err
+		}
+		w.tmpName = ""
+	}
+	return nil
+}
+
+// rollback restores the original contents of files that have been replaced.
+func rollback(writes []*pendingWrite) error {
+	var firstErr error
+	for _, w := range writes {
+		if w.fi == nil {
+			// The file has been created
+			if err := os.Remove(w.name); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("%s: %v", w.name, err)
+			}
+			continue
+		}
+		tmpName, err := writeTempFile(w.name, w.oldData, w.fi)
+		if err == nil {
+			err = os.Rename(tmpName, w.name)
+		}
+		if err != nil {
+			os.Remove(tmpName)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %v", w.name, err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// writeTempFile writes data to a new temporary file in the directory of the
+// file name, with the same permissions as the original file if it exists.
+func writeTempFile(name string, data []byte, fi os.FileInfo) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".buildozer-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		// New files are created with the same permissions as by file.WriteFile
+		perm := os.FileMode(0644)
+		if fi != nil {
+			perm = fi.Mode().Perm()
+		}
+		err = os.Chmod(f.Name(), perm)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -15,6 +15,10 @@ type defaultBuildifier struct{}
 // Runs opts.Buildifier if it's non-empty, otherwise uses built-in formatter.
 // opts.Buildifier is useful to force consistency with other tools that call Buildifier.
 func (b *defaultBuildifier) Buildify(opts *Options, f *build.File) ([]byte, error) {
+	if opts.PreserveFormatting {
+		// The untouched statements are printed as they were, only the edited ones are formatted.
+		return build.FormatWithoutRewriting(f), nil
+	}
 	if opts.Buildifier == "" {
 		// Current AST may be not entirely correct, e.g. it may contain Ident which
 		// value is a chunk of code, like "f(x)". The AST should be printed and
@@ -187,7 +187,9 @@ func RemoveEmptyPackage(f *build.File) *build.File {
 		}
 		all = append(all, stmt)
 	}
-	return &build.File{Path: f.Path, Comments: f.Comments, Stmt: all, Type: f.Type}
+	newF := *f
+	newF.Stmt = all
+	return &newF
 }
 
 func isEmptyPackage(expr build.Expr) bool {
@@ -221,7 +223,9 @@ func RemoveEmptyUseRepoCalls(f *build.File) *build.File {
 		}
 		all = append(all, stmt)
 	}
-	return &build.File{Path: f.Path, Comments: f.Comments, Stmt: all, Type: build.TypeModule}
+	newF := *f
+	newF.Stmt = all
+	return &newF
 }
 
 func isEmptyUseRepoCall(expr build.Expr) bool {
@@ -358,7 +362,9 @@ func DeleteRule(f *build.File, rule *build.Rule) *build.File {
 		}
 		all = append(all, stmt)
 	}
-	return &build.File{Path: f.Path, Comments: f.Comments, Stmt: all, Type: f.Type}
+	newF := *f
+	newF.Stmt = all
+	return &newF
 }
 
 // DeleteRuleByName returns the AST without the rules that have the
@@ -376,7 +382,9 @@ func DeleteRuleByName(f *build.File, name string) *build.File {
 			all = append(all, stmt)
 		}
 	}
-	return &build.File{Path: f.Path, Comments: f.Comments, Stmt: all, Type: f.Type}
+	newF := *f
+	newF.Stmt = all
+	return &newF
 }
 
 // DeleteRuleByKind removes the rules of the specified kind from the AST.
@@ -394,7 +402,9 @@ func DeleteRuleByKind(f *build.File, kind string) *build.File {
 			all = append(all, stmt)
 		}
 	}
-	return &build.File{Path: f.Path, Comments: f.Comments, Stmt: all, Type: f.Type}
+	newF := *f
+	newF.Stmt = all
+	return &newF
 }
 
 // AllLists returns all the lists concatenated in an expression.
@@ -0,0 +1,375 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edit
+
+// Interactive mode of buildozer: the commands are executed on in-memory copies
+// of the files, which are written only when the changes are committed.
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	apipb "github.com/bazelbuild/buildtools/api_proto"
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/differ"
+	"github.com/bazelbuild/buildtools/file"
+	"github.com/bazelbuild/buildtools/wspace"
+)
+
+const interactiveHelp = `Commands are entered in the same format as in command files, e.g.
+  add deps :foo|//pkg:bar
+  set testonly 1|//pkg:bar|//pkg:baz
+The changes are kept in memory and can be managed with the following commands:
+  diff     print the changes that are not committed yet
+  undo     revert the changes of the last command
+  commit   write the changes to the files
+  help     print this message
+  quit     exit, the changes that are not committed are lost
+`
+
+// sessionFile is a file read or modified in an interactive session.
+type sessionFile struct {
+	fi    os.FileInfo // nil if the file doesn't exist on disk
+	saved []byte      // the contents on disk
+	data  []byte      // the current contents
+	f     *build.File // the parsed current contents, nil if not parsed yet
+}
+
+// session is the state of an interactive session.
+type session struct {
+	opts    *Options
+	labels  []string
+	files   map[string]*sessionFile
+	history []map[string][]byte // the previous contents of the files modified by each command
+	failed  bool
+}
+
+// Interactive runs an interactive session: the commands read from in are
+// executed on in-memory copies of the files, the changes are printed after
+// each command and written to the files by the `commit` command. labels are
+// the targets of the commands used with the `*` target, as in command files.
+// Returns the exit code: 2 if some commands have failed, 0 otherwise.
+func Interactive(opts *Options, in io.Reader, labels []string) int {
+	if opts.OutWriter == nil {
+		opts.OutWriter = os.Stdout
+	}
+	if opts.ErrWriter == nil {
+		opts.ErrWriter = os.Stderr
+	}
+	s := &session{opts: opts, labels: labels, files: make(map[string]*sessionFile)}
+
+	r := bufio.NewReader(in)
+	for {
+		fmt.Fprint(opts.ErrWriter, "buildozer> ")
+		line, err := r.ReadString('\n')
+		if line = strings.TrimSpace(line); line != "" && line[0] != '#' {
+			if !s.execute(line) {
+				break
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(opts.ErrWriter)
+			break
+		}
+	}
+
+	if names := s.uncommitted(); len(names) > 0 {
+		fmt.Fprintf(opts.ErrWriter, "discarding the uncommitted changes of %d file(s)\n", len(names))
+	}
+	if s.failed {
+		return 2
+	}
+	return 0
+}
+
+// execute executes a line entered by the user. Returns false if the session
+// has to be terminated.
+func (s *session) execute(line string) bool {
+	switch line {
+	case "quit", "exit":
+		return false
+	case "help":
+		fmt.Fprint(s.opts.OutWriter, interactiveHelp)
+	case "diff":
+		names := s.uncommitted()
+		if len(names) == 0 {
+			fmt.Fprintln(s.opts.ErrWriter, "no changes")
+		}
+		for _, name := range names {
+			fmt.Fprint(s.opts.OutWriter, s.diff(name, s.files[name].saved, s.files[name].data))
+		}
+	case "undo":
+		s.undo()
+	case "commit":
+		s.commit()
+	default:
+		for _, err := range s.run(line) {
+			fmt.Fprintf(s.opts.ErrWriter, "error: %s\n", err)
+			s.failed = true
+		}
+	}
+	return true
+}
+
+// file returns the file with the given name, reading it if needed. The name of
+// the file can be changed as in readBuildFile. If create is set and the file
+// doesn't exist, an empty file is returned.
+func (s *session) file(name string, create bool) (string, *sessionFile, error) {
+	if sf, ok := s.files[name]; ok {
+		return name, sf, nil
+	}
+	realName, data, fi, err := readBuildFile(name)
+	if err == nil {
+		if sf, ok := s.files[realName]; ok {
+			return realName, sf, nil
+		}
+		sf := &sessionFile{fi: fi, saved: data, data: data}
+		s.files[realName] = sf
+		return realName, sf, nil
+	}
+	if !create {
+		return name, nil, errors.New("file not found or not readable")
+	}
+	sf := &sessionFile{}
+	s.files[name] = sf
+	return name, sf, nil
+}
+
+// parse returns the parsed current contents of a file.
+func (sf *sessionFile) parse(opts *Options, name string) (*build.File, error) {
+	if sf.f != nil {
+		return sf.f, nil
+	}
+	f, err := parseFile(opts, name, sf.data)
+	if err != nil {
+		return nil, err
+	}
+	if f.Type == build.TypeDefault {
+		// Buildozer is unable to infer the file type, fall back to BUILD by default.
+		f.Type = build.TypeBuild
+	}
+	f.WorkspaceRoot, f.Pkg, f.Label = wspace.SplitFilePath(name)
+	sf.f = f
+	return f, nil
+}
+
+// run executes a line of buildozer commands. If an error occurs and
+// Options.KeepGoing is not set, none of the files is modified.
+func (s *session) run(line string) []error {
+	args := removeEscapes(splitOnNonEscaped(line, '|', -1), '|')
+	if len(args) > 1 && args[1] == "*" {
+		args = append([]string{args[0]}, s.labels...)
+	}
+	hasCommand, hasTarget := false, false
+	for _, arg := range args {
+		tokens := SplitOnSpaces(arg)
+		if len(tokens) == 0 {
+			return []error{fmt.Errorf("empty command list")}
+		}
+		if cmd, ok := AllCommands[tokens[0]]; ok {
+			if err := commandUsageError(tokens[0], cmd, len(tokens)-1); err != nil {
+				return []error{err}
+			}
+			hasCommand = true
+		} else {
+			hasTarget = true
+		}
+	}
+	if !hasCommand {
+		return []error{fmt.Errorf("unknown command %q, enter \"help\" for help", SplitOnSpaces(args[0])[0])}
+	}
+	if !hasTarget {
+		return []error{fmt.Errorf("no targets")}
+	}
+
+	commandsByFile := make(map[string][]commandsForTarget)
+	if err := appendCommands(s.opts, commandsByFile, args); err != nil {
+		return []error{err}
+	}
+
+	contents := make(map[string][]byte)
+	for name, sf := range s.files {
+		if sf.data != nil {
+			contents[name] = sf.data
+		}
+	}
+	edited, errs := runWorkspaceCommands(s.opts, commandsByFile, contents)
+	if len(errs) > 0 && !s.opts.KeepGoing {
+		return errs
+	}
+	for name := range edited {
+		if _, ok := commandsByFile[name]; !ok {
+			commandsByFile[name] = nil
+		}
+	}
+	names := make([]string, 0, len(commandsByFile))
+	for name := range commandsByFile {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	newData := make(map[string][]byte)
+	var records []*apipb.Output_Record
+	for _, name := range names {
+		f := edited[name]
+		realName, sf, err := s.file(name, f != nil)
+		if err == nil && f == nil {
+			f, err = sf.parse(s.opts, realName)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", name, err))
+			if !s.opts.KeepGoing {
+				break
+			}
+			continue
+		}
+		newf, fileErrs, ok := executeFileCommands(s.opts, f, commandsByFile[name], &records)
+		for _, err := range fileErrs {
+			errs = append(errs, fmt.Errorf("%s: %v", realName, err))
+		}
+		if !ok {
+			break
+		}
+		if newf == nil && edited[name] == nil {
+			continue
+		}
+		if newf != nil {
+			f = newf
+		}
+		ndata, err := cleanAndBuildify(s.opts, f)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: running buildifier: %v", realName, err))
+			continue
+		}
+		newData[realName] = ndata
+	}
+	// The commands may have modified the parsed files
+	for _, sf := range s.files {
+		sf.f = nil
+	}
+	if len(errs) > 0 && !s.opts.KeepGoing {
+		return errs
+	}
+
+	s.apply(newData)
+	printRecords(s.opts, records)
+	return errs
+}
+
+// apply replaces the contents of the files and records their previous contents
+// for undo.
+func (s *session) apply(newData map[string][]byte) {
+	names := make([]string, 0, len(newData))
+	for name := range newData {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	previous := make(map[string][]byte)
+	for _, name := range names {
+		sf := s.files[name]
+		if bytes.Equal(sf.data, newData[name]) {
+			continue
+		}
+		fmt.Fprint(s.opts.OutWriter, s.diff(name, sf.data, newData[name]))
+		previous[name] = sf.data
+		sf.data = newData[name]
+	}
+	if len(previous) > 0 {
+		s.history = append(s.history, previous)
+	}
+}
+
+func (s *session) undo() {
+	if len(s.history) == 0 {
+		fmt.Fprintln(s.opts.ErrWriter, "nothing to undo")
+		return
+	}
+	previous := s.history[len(s.history)-1]
+	s.history = s.history[:len(s.history)-1]
+	names := make([]string, 0, len(previous))
+	for name := range previous {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sf := s.files[name]
+		fmt.Fprint(s.opts.OutWriter, s.diff(name, sf.data, previous[name]))
+		sf.data, sf.f = previous[name], nil
+	}
+}
+
+// commit writes the modified files. Either all files are written or none.
+func (s *session) commit() {
+	names := s.uncommitted()
+	if len(names) == 0 {
+		fmt.Fprintln(s.opts.ErrWriter, "no changes")
+		return
+	}
+	writes := make([]*pendingWrite, len(names))
+	for i, name := range names {
+		sf := s.files[name]
+		writes[i] = &pendingWrite{name: name, fi: sf.fi, oldData: sf.saved, newData: sf.data}
+	}
+	if err := commitFiles(writes); err != nil {
+		fmt.Fprintf(s.opts.ErrWriter, "error: %s, no files were modified\n", err)
+		s.failed = true
+		return
+	}
+	for _, name := range names {
+		sf := s.files[name]
+		sf.saved = sf.data
+		if _, fi, err := file.ReadFile(name); err == nil {
+			sf.fi = fi
+		}
+		if !s.opts.Quiet {
+			fmt.Fprintf(s.opts.ErrWriter, "fixed %s\n", name)
+		}
+	}
+	// The changes can't be undone anymore
+	s.history = nil
+}
+
+// uncommitted returns the sorted names of the files that have been modified
+// and not committed.
+func (s *session) uncommitted() []string {
+	var names []string
+	for name, sf := range s.files {
+		if !bytes.Equal(sf.saved, sf.data) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// diff returns the changes of a file in the unified diff format, with paths
+// relative to the workspace root as in the -diff mode.
+func (s *session) diff(name string, old, new []byte) string {
+	diffName := name
+	if root, pkg, label := wspace.SplitFilePath(name); root != "" {
+		diffName = path.Join(pkg, label)
+	}
+	return differ.Unified("a/"+diffName, "b/"+diffName, old, new)
+}
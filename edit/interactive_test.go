@@ -0,0 +1,148 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runInteractive runs an interactive session in a new workspace and returns
+// the exit code, the standard output and the workspace directory.
+func runInteractive(t *testing.T, files map[string]string, script string) (int, string, string) {
+	t.Helper()
+	tmp := t.TempDir()
+	files["WORKSPACE"] = ""
+	for name, contents := range files {
+		path := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr strings.Builder
+	opts := NewOpts()
+	opts.RootDir = tmp
+	opts.Quiet = true
+	opts.OutWriter = &stdout
+	opts.ErrWriter = &stderr
+	code := Interactive(opts, strings.NewReader(script), []string{"//pkg:lib"})
+	t.Logf("stderr:\n%s", stderr.String())
+	return code, stdout.String(), tmp
+}
+
+func readTestFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestInteractive(t *testing.T) {
+	original := `cc_library(name = "lib")
+`
+	code, stdout, dir := runInteractive(t, map[string]string{"pkg/BUILD": original}, `
+set testonly 1|*
+print testonly|//pkg:lib
+add deps :dep|//pkg:lib
+undo
+commit
+`)
+	if code != 0 {
+		t.Errorf("Interactive() = %d, want 0", code)
+	}
+	wantStdout := `--- a/pkg/BUILD
++++ b/pkg/BUILD
+@@ -1 +1,4 @@
+-cc_library(name = "lib")
++cc_library(
++    name = "lib",
++    testonly = 1,
++)
+1
+--- a/pkg/BUILD
++++ b/pkg/BUILD
+@@ -1,4 +1,5 @@
+ cc_library(
+     name = "lib",
+     testonly = 1,
++    deps = [":dep"],
+ )
+--- a/pkg/BUILD
++++ b/pkg/BUILD
+@@ -1,5 +1,4 @@
+ cc_library(
+     name = "lib",
+     testonly = 1,
+-    deps = [":dep"],
+ )
+`
+	if stdout != wantStdout {
+		t.Errorf("stdout:\n%s\nwant:\n%s", stdout, wantStdout)
+	}
+	want := `cc_library(
+    name = "lib",
+    testonly = 1,
+)
+`
+	if got := readTestFile(t, dir, "pkg/BUILD"); got != want {
+		t.Errorf("pkg/BUILD:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestInteractiveNoCommit(t *testing.T) {
+	original := `cc_library(name = "lib")
+`
+	code, _, dir := runInteractive(t, map[string]string{"pkg/BUILD": original}, `
+set testonly 1|//pkg:lib
+`)
+	if code != 0 {
+		t.Errorf("Interactive() = %d, want 0", code)
+	}
+	if got := readTestFile(t, dir, "pkg/BUILD"); got != original {
+		t.Errorf("pkg/BUILD has been modified:\n%s", got)
+	}
+}
+
+func TestInteractiveErrors(t *testing.T) {
+	files := map[string]string{
+		"a/BUILD": `cc_library(name = "lib")
+`,
+		"b/BUILD": `cc_library(name = "other")
+`,
+	}
+	// The command fails in b/BUILD, a/BUILD must not be modified either
+	code, stdout, _ := runInteractive(t, files, `
+set testonly 1|//a:lib|//b:lib
+add deps|//a:lib
+unknown_command|//a:lib
+diff
+`)
+	if code != 2 {
+		t.Errorf("Interactive() = %d, want 2", code)
+	}
+	if stdout != "" {
+		t.Errorf("stdout:\n%s\nwant nothing", stdout)
+	}
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edit
+
+// Selection of rules by kind, name and attribute values, e.g.
+// "%cc_test[size=large]" or "attr(tags, manual)".
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bazelbuild/buildtools/build"
+)
+
+// ruleSelector selects the rules of a file that match all its conditions.
+type ruleSelector struct {
+	kind       string         // empty for all kinds
+	name       *regexp.Regexp // nil for all names
+	predicates []attrPredicate
+}
+
+// attrPredicate is a condition on the value of an attribute:
+//
+//	[attr]          the attribute is set
+//	[!attr]         the attribute is not set
+//	[attr=value]    the attribute contains the value
+//	[attr!=value]   the attribute doesn't contain the value
+//	[attr~regexp]   the attribute contains a value matching the regexp
+type attrPredicate struct {
+	attr  string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+// parseRuleSelector parses a rule selector. Returns nil if the string is not a
+// selector but possibly a rule name.
+func parseRuleSelector(s string) (*ruleSelector, error) {
+	sel := &ruleSelector{}
+	rest := s
+	switch {
+	case strings.HasPrefix(s, "~"):
+		re, err := regexp.Compile(s[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp in %q: %v", s, err)
+		}
+		sel.name = re
+		return sel, nil
+	case strings.HasPrefix(s, "%"):
+		end := strings.Index(s, "[")
+		if end < 0 {
+			end = len(s)
+		}
+		sel.kind, rest = s[1:end], s[end:]
+	case strings.HasPrefix(s, "attr("):
+		// Find the matching parenthesis, regexps can contain groups
+		end := closingBracket(s[len("attr"):], '(', ')')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid selector %q, expected attr(<attr>, <regexp>)", s)
+		}
+		end += len("attr")
+		attr, value, ok := strings.Cut(s[len("attr("):end], ",")
+		attr = strings.TrimSpace(attr)
+		if !ok || attr == "" || strings.ContainsAny(attr, " \t") {
+			return nil, fmt.Errorf("invalid selector %q, expected attr(<attr>, <regexp>)", s)
+		}
+		p, err := newAttrPredicate(attr, "~", strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		sel.predicates = append(sel.predicates, p)
+		rest = s[end+1:]
+	case strings.HasPrefix(s, "*["), strings.HasPrefix(s, "all["):
+		rest = s[strings.Index(s, "["):]
+	case !strings.HasPrefix(s, "["):
+		return nil, nil
+	}
+
+	for rest != "" {
+		if rest[0] != '[' {
+			return nil, fmt.Errorf("invalid selector %q, expected '[' at %q", s, rest)
+		}
+		// Find the matching bracket, regexps can contain brackets too
+		end := closingBracket(rest, '[', ']')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid selector %q, missing ']'", s)
+		}
+		p, err := parseAttrPredicate(rest[1:end])
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %v", s, err)
+		}
+		sel.predicates = append(sel.predicates, p)
+		rest = rest[end+1:]
+	}
+	return sel, nil
+}
+
+// closingBracket returns the index of the bracket that closes the one at the
+// beginning of s, skipping the nested brackets and the escaped characters, or
+// -1 if it's missing.
+func closingBracket(s string, left, right byte) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case left:
+			depth++
+		case right:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseAttrPredicate parses the contents of a predicate between brackets.
+func parseAttrPredicate(s string) (attrPredicate, error) {
+	if i := strings.IndexAny(s, "=~"); i >= 0 {
+		attr, op := s[:i], s[i:i+1]
+		if op == "=" && strings.HasSuffix(attr, "!") {
+			attr, op = attr[:len(attr)-1], "!="
+		}
+		return newAttrPredicate(strings.TrimSpace(attr), op, strings.TrimSpace(s[i+1:]))
+	}
+	if strings.HasPrefix(s, "!") {
+		return newAttrPredicate(strings.TrimSpace(s[1:]), "!", "")
+	}
+	return newAttrPredicate(strings.TrimSpace(s), "", "")
+}
+
+func newAttrPredicate(attr, op, value string) (attrPredicate, error) {
+	if attr == "" {
+		return attrPredicate{}, fmt.Errorf("missing attribute name")
+	}
+	p := attrPredicate{attr: attr, op: op, value: normalizeAttrValue(value)}
+	if op == "~" {
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return attrPredicate{}, fmt.Errorf("invalid regexp %q: %v", value, err)
+		}
+		p.re = re
+	}
+	return p, nil
+}
+
+// normalizeAttrValue converts booleans to integers, since Bazel accepts both.
+func normalizeAttrValue(value string) string {
+	switch value {
+	case "True":
+		return "1"
+	case "False":
+		return "0"
+	}
+	return value
+}
+
+// attrValues returns the values of the strings, numbers and booleans of an
+// attribute, including the ones in lists and select() expressions.
+func attrValues(e build.Expr) []string {
+	var values []string
+	build.Walk(e, func(x build.Expr, stk []build.Expr) {
+		switch x := x.(type) {
+		case *build.StringExpr:
+			values = append(values, x.Value)
+		case *build.LiteralExpr:
+			values = append(values, x.Token)
+		case *build.Ident:
+			if len(stk) > 0 {
+				if call, ok := stk[len(stk)-1].(*build.CallExpr); ok && call.X == x {
+					// Function names, e.g. select or glob
+					return
+				}
+			}
+			values = append(values, normalizeAttrValue(x.Name))
+		}
+	})
+	return values
+}
+
+func (p attrPredicate) match(r *build.Rule) bool {
+	attr := r.Attr(p.attr)
+	switch p.op {
+	case "":
+		return attr != nil
+	case "!":
+		return attr == nil
+	case "!=":
+		return !attrPredicate{attr: p.attr, op: "=", value: p.value}.match(r)
+	}
+	if attr == nil {
+		return false
+	}
+	for _, value := range attrValues(attr) {
+		if p.op == "=" && value == p.value || p.op == "~" && p.re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (sel *ruleSelector) match(r *build.Rule) bool {
+	if sel.kind != "" && r.Kind() != sel.kind {
+		return false
+	}
+	if sel.name != nil && !sel.name.MatchString(r.Name()) {
+		return false
+	}
+	for _, p := range sel.predicates {
+		if !p.match(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// filter returns the rules of the file selected by the selector.
+func (sel *ruleSelector) filter(f *build.File) []*build.Rule {
+	var result []*build.Rule
+	for _, r := range f.Rules("") {
+		if sel.match(r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bazelbuild/buildtools/build"
+)
+
+const selectorTestFile = `
+java_library(
+    name = "lib",
+    testonly = 1,
+    deps = ["//base:util"],
+)
+
+java_library(
+    name = "prod_lib",
+    tags = ["manual"],
+)
+
+cc_test(
+    name = "small_test",
+    size = "small",
+)
+
+cc_test(
+    name = "large_test",
+    size = "large",
+    tags = [
+        "manual",
+        "no-sandbox",
+    ],
+    testonly = True,
+)
+
+cc_test(
+    name = "other_test",
+    deps = select({
+        ":cond": ["//base:util"],
+        "//conditions:default": [],
+    }),
+)
+`
+
+func TestExpandTargetsSelectors(t *testing.T) {
+	f, err := build.Parse("BUILD", []byte(selectorTestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		selector string
+		want     string
+	}{
+		{"%java_library", "lib prod_lib"},
+		{"%java_library[testonly=1]", "lib"},
+		{"%cc_test[testonly=True]", "large_test"},
+		{"%cc_test[size=large]", "large_test"},
+		{"%cc_test[size!=large]", "small_test other_test"},
+		{"%cc_test[size]", "small_test large_test"},
+		{"%cc_test[!size]", "other_test"},
+		{"*[tags=manual]", "prod_lib large_test"},
+		{"all[tags=manual][testonly]", "large_test"},
+		{"[deps=//base:util]", "lib other_test"},
+		{"[deps~^//base:]", "lib other_test"},
+		{"attr(tags, manual)", "prod_lib large_test"},
+		{"attr(tags, ^no-)", "large_test"},
+		{"attr(tags, manual)[size=large]", "large_test"},
+		{"attr(tags, ^(manual|no-.*)$)", "prod_lib large_test"},
+		{"attr(size, (sm|l)a(ll|rge))[tags~\\)|^no-]", "large_test"},
+		{"~_test$", "small_test large_test other_test"},
+		{"~^(small|other)_", "small_test other_test"},
+		{"[name~[ls][a-z]+_test]", "small_test large_test"},
+		{"%cc_test[size=medium]", ""},
+	} {
+		t.Run(tc.selector, func(t *testing.T) {
+			rules, err := expandTargets(f, tc.selector)
+			if err != nil {
+				t.Fatalf("expandTargets(%q): %v", tc.selector, err)
+			}
+			var names []string
+			for _, r := range rules {
+				names = append(names, r.Name())
+			}
+			if got := strings.Join(names, " "); got != tc.want {
+				t.Errorf("expandTargets(%q) = %q, want %q", tc.selector, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExpandTargetsSelectorErrors(t *testing.T) {
+	f, err := build.Parse("BUILD", []byte(selectorTestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, selector := range []string{
+		"unknown",
+		"%999",
+		"%cc_test[size=large",
+		"%cc_test[size=large]x",
+		"%cc_test[=large]",
+		"attr(tags)",
+		"attr(tags, [)",
+		"attr(tags, (manual)",
+		"attr(, manual)",
+		"~(",
+	} {
+		if rules, err := expandTargets(f, selector); err == nil {
+			t.Errorf("expandTargets(%q) = %d rules, want an error", selector, len(rules))
+		}
+	}
+}
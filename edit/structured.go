@@ -0,0 +1,218 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edit
+
+// Conversion of attribute values to JSON-like structures, for the `print`
+// command with the -structured_values flag.
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bazelbuild/buildtools/build"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// structuredValue converts an expression to a structured value:
+//
+//   - strings, numbers, booleans and None are converted to the JSON values,
+//   - lists and tuples are converted to lists, dicts with string keys to objects,
+//   - select() expressions are converted to {"$select": {condition: value}},
+//   - concatenations of lists or strings are evaluated, other concatenations
+//     are converted to {"$concat": [values]},
+//   - variables defined in the file are replaced by their values,
+//   - anything else is converted to {"$expr": "<source code>"}.
+//
+// The keys of the objects that represent dicts and start with "$" are escaped
+// with another "$", so that they can't be mistaken for the markers above.
+func structuredValue(expr build.Expr, vars map[string]*build.AssignExpr) *structpb.Value {
+	return (&structuredConverter{vars: vars, resolving: make(map[string]bool)}).convert(expr)
+}
+
+// The keys of the objects that represent expressions that can't be converted
+// to JSON values.
+const (
+	selectKey = "$select"
+	concatKey = "$concat"
+	exprKey   = "$expr"
+)
+
+type structuredConverter struct {
+	vars      map[string]*build.AssignExpr
+	resolving map[string]bool // the variables being resolved, to detect cycles
+}
+
+func (c *structuredConverter) convert(expr build.Expr) *structpb.Value {
+	switch expr := expr.(type) {
+	case *build.StringExpr:
+		return structpb.NewStringValue(expr.Value)
+	case *build.LiteralExpr:
+		if n, err := strconv.ParseInt(expr.Token, 0, 64); err == nil {
+			return structpb.NewNumberValue(float64(n))
+		}
+		if n, err := strconv.ParseFloat(expr.Token, 64); err == nil {
+			return structpb.NewNumberValue(n)
+		}
+	case *build.UnaryExpr:
+		if n, ok := c.convert(expr.X).GetKind().(*structpb.Value_NumberValue); ok && expr.Op == "-" {
+			return structpb.NewNumberValue(-n.NumberValue)
+		}
+	case *build.Ident:
+		switch expr.Name {
+		case "True":
+			return structpb.NewBoolValue(true)
+		case "False":
+			return structpb.NewBoolValue(false)
+		case "None":
+			return structpb.NewNullValue()
+		}
+		if assign, ok := c.vars[expr.Name]; ok && assign.Op == "=" && !c.resolving[expr.Name] {
+			c.resolving[expr.Name] = true
+			defer delete(c.resolving, expr.Name)
+			return c.convert(assign.RHS)
+		}
+	case *build.ListExpr:
+		return c.list(expr.List)
+	case *build.TupleExpr:
+		return c.list(expr.List)
+	case *build.DictExpr:
+		if v := c.dict(expr); v != nil {
+			return v
+		}
+	case *build.ParenExpr:
+		return c.convert(expr.X)
+	case *build.CallExpr:
+		if ident, ok := expr.X.(*build.Ident); ok && ident.Name == "select" && len(expr.List) > 0 {
+			if v := c.selectValue(expr); v != nil {
+				return v
+			}
+		}
+	case *build.BinaryExpr:
+		if expr.Op == "+" {
+			return c.concat(expr)
+		}
+	}
+	return c.object(exprKey, structpb.NewStringValue(build.FormatString(expr)))
+}
+
+func (c *structuredConverter) object(key string, value *structpb.Value) *structpb.Value {
+	return structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{key: value}})
+}
+
+func (c *structuredConverter) list(exprs []build.Expr) *structpb.Value {
+	values := make([]*structpb.Value, len(exprs))
+	for i, e := range exprs {
+		values[i] = c.convert(e)
+	}
+	return structpb.NewListValue(&structpb.ListValue{Values: values})
+}
+
+// dict converts a dict to an object, returns nil if its keys are not strings.
+func (c *structuredConverter) dict(dict *build.DictExpr) *structpb.Value {
+	fields := make(map[string]*structpb.Value)
+	for _, kv := range dict.List {
+		key := c.convert(kv.Key)
+		str, ok := key.GetKind().(*structpb.Value_StringValue)
+		if !ok {
+			return nil
+		}
+		name := str.StringValue
+		if strings.HasPrefix(name, "$") {
+			name = "$" + name
+		}
+		fields[name] = c.convert(kv.Value)
+	}
+	return structpb.NewStructValue(&structpb.Struct{Fields: fields})
+}
+
+// selectValue converts a select() expression, returns nil if its arguments
+// are not understood.
+func (c *structuredConverter) selectValue(call *build.CallExpr) *structpb.Value {
+	fields := make(map[string]*structpb.Value)
+	for i, arg := range call.List {
+		if i == 0 {
+			branches := c.convert(arg)
+			if _, ok := branches.GetKind().(*structpb.Value_StructValue); !ok {
+				return nil
+			}
+			fields[selectKey] = branches
+			continue
+		}
+		assign, ok := arg.(*build.AssignExpr)
+		if !ok {
+			return nil
+		}
+		key, ok := assign.LHS.(*build.Ident)
+		if !ok {
+			return nil
+		}
+		fields[key.Name] = c.convert(assign.RHS)
+	}
+	if fields[selectKey] == nil {
+		return nil
+	}
+	return structpb.NewStructValue(&structpb.Struct{Fields: fields})
+}
+
+// concat evaluates a concatenation of lists or strings, or converts it to
+// {"$concat": [values]} if some values are only known during the analysis,
+// e.g. select() expressions.
+func (c *structuredConverter) concat(expr *build.BinaryExpr) *structpb.Value {
+	var operands []*structpb.Value
+	var collect func(e build.Expr)
+	collect = func(e build.Expr) {
+		if bin, ok := e.(*build.BinaryExpr); ok && bin.Op == "+" {
+			collect(bin.X)
+			collect(bin.Y)
+			return
+		}
+		v := c.convert(e)
+		if _, ok := e.(*build.Ident); ok {
+			if concat, ok := v.GetStructValue().GetFields()[concatKey]; ok {
+				// A variable that is a concatenation itself
+				operands = append(operands, concat.GetListValue().GetValues()...)
+				return
+			}
+		}
+		operands = append(operands, v)
+	}
+	collect(expr)
+
+	// Merge the adjacent operands of the same type
+	var merged []*structpb.Value
+	for _, v := range operands {
+		if len(merged) > 0 {
+			last := merged[len(merged)-1]
+			if l1, l2 := last.GetListValue(), v.GetListValue(); l1 != nil && l2 != nil {
+				values := append(append([]*structpb.Value(nil), l1.Values...), l2.Values...)
+				merged[len(merged)-1] = structpb.NewListValue(&structpb.ListValue{Values: values})
+				continue
+			}
+			s1, ok1 := last.GetKind().(*structpb.Value_StringValue)
+			s2, ok2 := v.GetKind().(*structpb.Value_StringValue)
+			if ok1 && ok2 {
+				merged[len(merged)-1] = structpb.NewStringValue(s1.StringValue + s2.StringValue)
+				continue
+			}
+		}
+		merged = append(merged, v)
+	}
+	if len(merged) == 1 {
+		return merged[0]
+	}
+	return c.object(concatKey, structpb.NewListValue(&structpb.ListValue{Values: merged}))
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bazelbuild/buildtools/build"
+)
+
+func TestStructuredValue(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		want string
+	}{
+		{`"foo"`, `"foo"`},
+		{`42`, `42`},
+		{`-1`, `-1`},
+		{`0x10`, `16`},
+		{`True`, `true`},
+		{`None`, `null`},
+		{`["a", 1, False]`, `["a",1,false]`},
+		{`("a", "b")`, `["a","b"]`},
+		{`{"a": ["b"], "c": "d"}`, `{"a":["b"],"c":"d"}`},
+		{`{1: "a"}`, `{"$expr":"{1: \"a\"}"}`},
+		{`["a"] + ["b"] + LIST`, `["a","b","c","d"]`},
+		{`"foo" + "bar"`, `"foobar"`},
+		{
+			`select({":cond": ["a"], "//conditions:default": []})`,
+			`{"$select":{"//conditions:default":[],":cond":["a"]}}`,
+		},
+		{
+			`select({":cond": ["a"]}, no_match_error = "error")`,
+			`{"$select":{":cond":["a"]},"no_match_error":"error"}`,
+		},
+		{
+			`["a"] + select({":cond": ["b"]}) + ["c"] + ["d"]`,
+			`{"$concat":[["a"],{"$select":{":cond":["b"]}},["c","d"]]}`,
+		},
+		{`["x"] + CONCAT`, `{"$concat":[["x","a"],{"$select":{":cond":["b"]}}]}`},
+		{`NESTED`, `{"k":["c","d"]}`},
+		{`{"select": ["a"], "$expr": "b"}`, `{"$$expr":"b","select":["a"]}`},
+		{`CYCLE`, `{"$expr":"CYCLE"}`},
+		{`UNKNOWN`, `{"$expr":"UNKNOWN"}`},
+		{`APPENDED`, `{"$expr":"APPENDED"}`},
+		{`glob(["*.cc"])`, `{"$expr":"glob([\"*.cc\"])"}`},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			f, err := build.ParseBuild("BUILD", []byte(`
+LIST = ["c", "d"]
+CONCAT = ["a"] + select({":cond": ["b"]})
+NESTED = {"k": LIST}
+CYCLE = CYCLE
+APPENDED = []
+APPENDED += ["a"]
+x = `+tc.expr))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assign := f.Stmt[len(f.Stmt)-1].(*build.AssignExpr)
+			data, err := json.Marshal(structuredValue(assign.RHS, getGlobalVariables(f.Stmt)).AsInterface())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(data); got != tc.want {
+				t.Errorf("structuredValue(%s) = %s, want %s", tc.expr, got, tc.want)
+			}
+		})
+	}
+}
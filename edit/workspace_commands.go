@@ -0,0 +1,683 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edit
+
+// Buildozer commands that modify several files of the workspace, e.g. to move
+// a target and update all references to it.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/file"
+	"github.com/bazelbuild/buildtools/labels"
+	"github.com/bazelbuild/buildtools/wspace"
+)
+
+// workspaceCommandFn executes a workspace command on a target declared in the
+// given BUILD file.
+type workspaceCommandFn func(opts *Options, ws *workspace, buildFile, target string, args []string) error
+
+// workspaceCommands are executed sequentially on the whole workspace, before
+// the other commands that are executed on each file independently. They must
+// also be registered in AllCommands.
+var workspaceCommands = map[string]workspaceCommandFn{
+	"move_target":   cmdMoveTarget,
+	"rename_target": cmdRenameTarget,
+}
+
+// cmdWorkspace is the entry in AllCommands for the workspace commands, which
+// can't be executed on a single file.
+func cmdWorkspace(opts *Options, env CmdEnvironment) (*build.File, error) {
+	return nil, fmt.Errorf("the command modifies several files and can only be executed on files in a workspace")
+}
+
+// workspace is an in-memory view of the BUILD files read and modified by the
+// workspace commands.
+type workspace struct {
+	opts     *Options
+	root     string
+	files    map[string]*build.File
+	modified map[string]bool
+}
+
+func newWorkspace(opts *Options) (*workspace, error) {
+	root, _ := wspace.FindWorkspaceRoot(opts.RootDir)
+	if root == "" {
+		return nil, fmt.Errorf("workspace root not found")
+	}
+	return &workspace{
+		opts:     opts,
+		root:     root,
+		files:    make(map[string]*build.File),
+		modified: make(map[string]bool),
+	}, nil
+}
+
+// file returns the parsed BUILD file, including the changes made by the
+// previous workspace commands.
+func (ws *workspace) file(name string) (*build.File, error) {
+	if f, ok := ws.files[name]; ok {
+		return f, nil
+	}
+	data, _, err := file.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return ws.parse(name, data)
+}
+
+// parse parses the contents of a BUILD file and adds it to the workspace.
+func (ws *workspace) parse(name string, data []byte) (*build.File, error) {
+	f, err := parseFile(ws.opts, name, data)
+	if err != nil {
+		return nil, err
+	}
+	if f.Type == build.TypeDefault {
+		f.Type = build.TypeBuild
+	}
+	f.WorkspaceRoot, f.Pkg, f.Label = ws.root, ws.pkg(name), filepath.Base(name)
+	ws.files[name] = f
+	return f, nil
+}
+
+// pkg returns the package of a BUILD file.
+func (ws *workspace) pkg(name string) string {
+	rel, err := filepath.Rel(ws.root, filepath.Dir(name))
+	if err != nil || rel == "." {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// packageFile returns the name and the contents of the BUILD file of a package.
+// If the directory of the package doesn't contain a BUILD file, a new file
+// with the given base name is created.
+func (ws *workspace) packageFile(pkg, baseName string) (string, *build.File, error) {
+	dir := filepath.Join(ws.root, filepath.FromSlash(pkg))
+	for _, name := range BuildFileNames {
+		name = filepath.Join(dir, name)
+		if _, ok := ws.files[name]; ok || wspace.IsRegularFile(name) {
+			f, err := ws.file(name)
+			return name, f, err
+		}
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return "", nil, fmt.Errorf("directory %s doesn't exist", dir)
+	}
+	name := filepath.Join(dir, baseName)
+	f := &build.File{Path: name, Type: build.TypeBuild, WorkspaceRoot: ws.root, Pkg: pkg, Label: baseName}
+	ws.files[name] = f
+	ws.modified[name] = true
+	return name, f, nil
+}
+
+// referenceFiles returns the names of the files that can refer to targets of
+// the workspace: all BUILD and .bzl files of the workspace, including the ones
+// created by workspace commands, and the BUILD files of the external
+// repositories declared in the WORKSPACE file. The latter are also returned as
+// a set.
+func (ws *workspace) referenceFiles(opts *Options) ([]string, map[string]bool) {
+	var ignoredPrefixes []string
+	if opts.RespectBazelignore {
+		ignoredPrefixes = getIgnoredPrefixes(ws.root)
+	}
+	names := findFiles(ws.root, ignoredPrefixes, func(name string) bool {
+		for _, buildFileName := range BuildFileNames {
+			if name == buildFileName {
+				return true
+			}
+		}
+		return strings.HasSuffix(name, ".bzl")
+	})
+	external := make(map[string]bool)
+	if repoFiles, err := wspace.FindRepoBuildFiles(ws.root); err == nil {
+		for _, name := range repoFiles {
+			if wspace.IsRegularFile(name) {
+				names = append(names, name)
+				external[name] = true
+			}
+		}
+	}
+	for name := range ws.files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	unique := names[:0]
+	for i, name := range names {
+		if i == 0 || name != names[i-1] {
+			unique = append(unique, name)
+		}
+	}
+	return unique, external
+}
+
+// runWorkspaceCommands removes the workspace commands from commandsByFile and
+// executes them. The contents map overrides the contents of the files on disk,
+// it can be nil. Returns the files modified by the commands.
+func runWorkspaceCommands(opts *Options, commandsByFile map[string][]commandsForTarget, contents map[string][]byte) (map[string]*build.File, []error) {
+	type workspaceCommand struct {
+		buildFile string
+		target    string
+		cmd       command
+	}
+	var commands []workspaceCommand
+
+	names := make([]string, 0, len(commandsByFile))
+	for name := range commandsByFile {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var remaining []commandsForTarget
+		for _, cft := range commandsByFile[name] {
+			var other []command
+			for _, cmd := range cft.commands {
+				if _, ok := workspaceCommands[cmd.tokens[0]]; ok {
+					commands = append(commands, workspaceCommand{name, cft.target, cmd})
+				} else {
+					other = append(other, cmd)
+				}
+			}
+			if len(other) > 0 {
+				remaining = append(remaining, commandsForTarget{cft.target, other})
+			}
+		}
+		if len(remaining) > 0 {
+			commandsByFile[name] = remaining
+		} else {
+			delete(commandsByFile, name)
+		}
+	}
+	if len(commands) == 0 {
+		return nil, nil
+	}
+
+	ws, err := newWorkspace(opts)
+	if err != nil {
+		return nil, []error{err}
+	}
+	for name, data := range contents {
+		if _, err := ws.parse(name, data); err != nil {
+			return nil, []error{err}
+		}
+	}
+	var errs []error
+	for _, c := range commands {
+		if c.buildFile == stdinPackageName {
+			errs = append(errs, commandError([]command{c.cmd}, c.target, fmt.Errorf("can't be executed on the standard input")))
+		} else if err := workspaceCommands[c.cmd.tokens[0]](opts, ws, c.buildFile, c.target, c.cmd.tokens[1:]); err != nil {
+			errs = append(errs, commandError([]command{c.cmd}, c.target, err))
+		} else {
+			continue
+		}
+		if !opts.KeepGoing {
+			return nil, errs
+		}
+	}
+
+	modified := make(map[string]*build.File)
+	for name := range ws.modified {
+		modified[name] = ws.files[name]
+	}
+	return modified, errs
+}
+
+// isLabelAttr checks whether the values of an attribute are labels that refer
+// to targets.
+func isLabelAttr(kind, attr string) bool {
+	return ContainsLabels(kind, attr) || attr == "visibility"
+}
+
+// labelAttrStrings returns the string literals of the attributes of a rule that
+// contain labels, including the keys and values of select() expressions.
+// Returns an error if the labels are generated by glob().
+func labelAttrStrings(r *build.Rule) ([]*build.StringExpr, error) {
+	var result []*build.StringExpr
+	var err error
+	for _, attr := range r.AttrKeys() {
+		if !isLabelAttr(r.Kind(), attr) {
+			continue
+		}
+		build.Walk(r.Attr(attr), func(x build.Expr, stk []build.Expr) {
+			if call, ok := x.(*build.CallExpr); ok {
+				if ident, ok := call.X.(*build.Ident); ok && ident.Name == "glob" {
+					err = fmt.Errorf("attribute %q uses glob()", attr)
+				}
+			}
+			str, ok := x.(*build.StringExpr)
+			if !ok {
+				return
+			}
+			for _, e := range stk {
+				if call, ok := e.(*build.CallExpr); ok {
+					if ident, ok := call.X.(*build.Ident); ok && ident.Name == "glob" {
+						return
+					}
+				}
+			}
+			result = append(result, str)
+		})
+	}
+	return result, err
+}
+
+// ruleTargets returns the names of the targets declared by a rule: the rule
+// itself and its output files.
+func ruleTargets(r *build.Rule) map[string]bool {
+	targets := map[string]bool{r.Name(): true}
+	for _, str := range AllStrings(r.Attr("out")) {
+		targets[str.Value] = true
+	}
+	for _, list := range AllLists(r.Attr("outs")) {
+		for _, item := range list.List {
+			if str, ok := item.(*build.StringExpr); ok {
+				targets[str.Value] = true
+			}
+		}
+	}
+	return targets
+}
+
+// relocateLabel converts a label used in the package oldPkg so that it refers
+// to the same target when used in the package newPkg. Files of oldPkg that are
+// located in the directory of newPkg become files of newPkg.
+func relocateLabel(value, oldPkg, newPkg string) string {
+	if strings.HasPrefix(value, "@") || strings.HasPrefix(value, "//") {
+		return ShortenLabel(value, newPkg)
+	}
+	target := strings.TrimPrefix(value, ":")
+	if path := pathJoin(oldPkg, target); strings.HasPrefix(newPkg, pathJoin(oldPkg, "")) && strings.HasPrefix(path, newPkg+"/") {
+		// The file is in a subdirectory of the old package which belongs to
+		// the new package
+		return strings.TrimPrefix(path, newPkg+"/")
+	}
+	return ShortenLabel("//"+oldPkg+":"+target, newPkg)
+}
+
+// relocateLoad converts the module of a load statement used in the package
+// oldPkg so that it can be loaded from the package newPkg.
+func relocateLoad(module, oldPkg, newPkg string) string {
+	if strings.HasPrefix(module, "@") || strings.HasPrefix(module, "//") {
+		if label := labels.Parse(module); label.Repository == "" && label.Package == newPkg {
+			return ":" + label.Target
+		}
+		return module
+	}
+	if oldPkg == newPkg {
+		return module
+	}
+	return "//" + oldPkg + ":" + strings.TrimPrefix(module, ":")
+}
+
+func pathJoin(pkg, name string) string {
+	if pkg == "" {
+		return name
+	}
+	return pkg + "/" + name
+}
+
+// removeLoadedSymbols removes the given symbols from the load statements of a
+// file, and the load statements that don't load anything anymore.
+func removeLoadedSymbols(f *build.File, symbols map[string]bool) {
+	var all []build.Expr
+	for _, stmt := range f.Stmt {
+		load, ok := stmt.(*build.LoadStmt)
+		if !ok {
+			all = append(all, stmt)
+			continue
+		}
+		var from, to []*build.Ident
+		for i := range load.To {
+			if !symbols[load.To[i].Name] {
+				from = append(from, load.From[i])
+				to = append(to, load.To[i])
+			}
+		}
+		if len(to) == 0 {
+			continue
+		}
+		load.From, load.To = from, to
+		all = append(all, load)
+	}
+	f.Stmt = all
+}
+
+// updateReferences replaces the labels of the targets of the package oldPkg in
+// the workspace with labels of the package newPkg, the names of the targets
+// are given by the renames map. Absolute labels are replaced in all string
+// literals of BUILD and .bzl files, e.g. in the arguments of macros. Relative
+// labels are only replaced in BUILD files, labels without a colon only in the
+// attributes known to contain labels. The BUILD files of external repositories
+// can only refer to the workspace with labels like "@//pkg:target".
+func updateReferences(opts *Options, ws *workspace, oldPkg, newPkg string, renames map[string]string) {
+	names, external := ws.referenceFiles(opts)
+	for _, name := range names {
+		f, err := ws.file(name)
+		if err != nil {
+			// Files that can't be parsed are skipped
+			continue
+		}
+		isBuild := f.Type == build.TypeBuild && !external[name]
+		bare := make(map[*build.StringExpr]bool)
+		if isBuild {
+			for _, r := range f.Rules("") {
+				strs, _ := labelAttrStrings(r)
+				for _, str := range strs {
+					bare[str] = true
+				}
+			}
+		}
+
+		for _, stmt := range f.Stmt {
+			if _, ok := stmt.(*build.LoadStmt); ok {
+				continue
+			}
+			build.Walk(stmt, func(x build.Expr, stk []build.Expr) {
+				str, ok := x.(*build.StringExpr)
+				if !ok {
+					return
+				}
+				switch value := str.Value; {
+				case strings.HasPrefix(value, "@"):
+					if !strings.HasPrefix(strings.TrimLeft(value, "@"), "//") {
+						// Another repository
+						return
+					}
+				case external[name]:
+					return
+				case strings.HasPrefix(value, "//"):
+				case !isBuild:
+					// Relative labels in .bzl files depend on where they are used
+					return
+				case !strings.HasPrefix(value, ":") && !bare[str]:
+					return
+				}
+				for oldName, newName := range renames {
+					if labels.Equal(str.Value, "//"+oldPkg+":"+oldName, f.Pkg) {
+						str.Value = formatReference(str.Value, labels.Label{Package: newPkg, Target: newName}, f.Pkg)
+						ws.modified[name] = true
+						return
+					}
+				}
+			})
+		}
+	}
+}
+
+// formatReference formats a label that replaces the reference value used in
+// the package pkg, in the same form as value if possible.
+func formatReference(value string, label labels.Label, pkg string) string {
+	switch {
+	case strings.HasPrefix(value, "@"):
+		return value[:strings.Index(value, "//")] + label.Format()
+	case strings.HasPrefix(value, "//") && labels.Parse(value).Package == pkg:
+		// Absolute labels are kept if they are used on purpose
+		return label.Format()
+	}
+	short := ShortenLabel(label.Format(), pkg)
+	if !strings.HasPrefix(value, ":") && !strings.HasPrefix(value, "//") {
+		return strings.TrimPrefix(short, ":")
+	}
+	return short
+}
+
+// packageDefaultAttrs maps the attributes of package() to the attributes of
+// the rules whose default value they set, and gives the value of the
+// attributes in a package that doesn't set them.
+var packageDefaultAttrs = []struct {
+	pkgAttr, ruleAttr, implicit string
+}{
+	{"default_visibility", "visibility", `["//visibility:private"]`},
+	{"default_testonly", "testonly", "False"},
+	{"default_applicable_licenses", "applicable_licenses", "[]"},
+	{"licenses", "licenses", "[]"},
+}
+
+// packageDefaults returns the default values of rule attributes set by the
+// package() declaration of a BUILD file, or by a call to licenses(), indexed
+// by rule attribute.
+func packageDefaults(f *build.File) map[string]build.Expr {
+	defaults := make(map[string]build.Expr)
+	if pkg := ExistingPackageDeclaration(f); pkg != nil {
+		for _, attr := range packageDefaultAttrs {
+			if value := pkg.Attr(attr.pkgAttr); value != nil {
+				defaults[attr.ruleAttr] = value
+			}
+		}
+	}
+	for _, stmt := range f.Stmt {
+		if call, ok := ExprToRule(stmt, "licenses"); ok && len(call.Call.List) == 1 {
+			defaults["licenses"] = call.Call.List[0]
+		}
+	}
+	return defaults
+}
+
+// keepPackageDefaults sets the attributes of a rule moved to another package
+// whose defaults differ between the two packages, so that the rule keeps the
+// values it had in its old package. Attributes set by the rule are unchanged.
+func keepPackageDefaults(r *build.Rule, oldDefaults, newDefaults map[string]build.Expr) error {
+	for _, attr := range packageDefaultAttrs {
+		if r.Attr(attr.ruleAttr) != nil {
+			continue
+		}
+		value := attr.implicit
+		if old, ok := oldDefaults[attr.ruleAttr]; ok {
+			value = build.FormatString(old)
+		}
+		newValue := attr.implicit
+		if expr, ok := newDefaults[attr.ruleAttr]; ok {
+			newValue = build.FormatString(expr)
+		}
+		if value == newValue {
+			continue
+		}
+		ast, err := build.ParseBuild("", []byte(value))
+		if err != nil || len(ast.Stmt) != 1 {
+			return fmt.Errorf("could not parse the default value of %q: %s", attr.ruleAttr, value)
+		}
+		r.SetAttr(attr.ruleAttr, ast.Stmt[0])
+	}
+	return nil
+}
+
+// cmdMoveTarget moves rules to another package, and updates all references to
+// them in the workspace.
+func cmdMoveTarget(opts *Options, ws *workspace, buildFile, target string, args []string) error {
+	newPkg := strings.TrimSuffix(strings.TrimPrefix(args[0], "//"), "/")
+	if strings.ContainsAny(newPkg, ":@") {
+		return fmt.Errorf("invalid package %q", args[0])
+	}
+	src, err := ws.file(buildFile)
+	if err != nil {
+		return err
+	}
+	oldPkg := src.Pkg
+	if newPkg == oldPkg {
+		return fmt.Errorf("the target is already in the package //%s", newPkg)
+	}
+	_, _, _, ruleName := InterpretLabelForWorkspaceLocation(opts.RootDir, target)
+	rules, err := expandTargets(src, ruleName)
+	if err != nil {
+		return err
+	}
+	rules = filterRules(opts, rules)
+	_, dest, err := ws.packageFile(newPkg, filepath.Base(buildFile))
+	if err != nil {
+		return err
+	}
+	srcDefaults, destDefaults := packageDefaults(src), packageDefaults(dest)
+
+	for _, r := range rules {
+		if r.Kind() == "package" {
+			return fmt.Errorf("the package declaration can't be moved")
+		}
+		if r.Name() == "" {
+			return fmt.Errorf("the rule on line %d has no name", r.Call.Pos.Line)
+		}
+		if FindRuleByName(dest, r.Name()) != nil {
+			return fmt.Errorf("target %q already exists in the package //%s", r.Name(), newPkg)
+		}
+		if err := keepPackageDefaults(r, srcDefaults, destDefaults); err != nil {
+			return fmt.Errorf("rule %q can't be moved: %v", r.Name(), err)
+		}
+		strs, err := labelAttrStrings(r)
+		if err != nil {
+			return fmt.Errorf("rule %q can't be moved: %v", r.Name(), err)
+		}
+
+		for _, str := range strs {
+			str.Value = relocateLabel(str.Value, oldPkg, newPkg)
+		}
+
+		// Copy the load statements needed by the rule
+		used := UsedSymbols(r.Call)
+		moved := make(map[string]bool)
+		for _, stmt := range src.Stmt {
+			load, ok := stmt.(*build.LoadStmt)
+			if !ok {
+				continue
+			}
+			for i, to := range load.To {
+				if used[to.Name] {
+					module := relocateLoad(load.Module.Value, oldPkg, newPkg)
+					dest.Stmt = InsertLoad(dest.Stmt, module, []string{load.From[i].Name}, []string{to.Name})
+					moved[to.Name] = true
+				}
+			}
+		}
+
+		src.Stmt = DeleteRule(src, r).Stmt
+		stillUsed := UsedSymbols(src)
+		for name := range moved {
+			if stillUsed[name] {
+				delete(moved, name)
+			}
+		}
+		removeLoadedSymbols(src, moved)
+		dest.Stmt = append(dest.Stmt, r.Call)
+
+		renames := make(map[string]string)
+		for name := range ruleTargets(r) {
+			renames[name] = name
+		}
+		updateReferences(opts, ws, oldPkg, newPkg, renames)
+	}
+	ws.modified[buildFile] = true
+	ws.modified[dest.Path] = true
+	return nil
+}
+
+// cmdRenameTarget renames a rule, and updates all references to it in the
+// workspace. The rule is either the target of the command or, if two arguments
+// are given, the old label, which must belong to the package of the target. If
+// the new label belongs to another package, the rule is moved there first.
+func cmdRenameTarget(opts *Options, ws *workspace, buildFile, target string, args []string) error {
+	f, err := ws.file(buildFile)
+	if err != nil {
+		return err
+	}
+	var r *build.Rule
+	if len(args) == 2 {
+		if r, err = findRuleByLabel(f, args[0]); err != nil {
+			return err
+		}
+	} else {
+		_, _, _, ruleName := InterpretLabelForWorkspaceLocation(opts.RootDir, target)
+		rules, err := expandTargets(f, ruleName)
+		if err != nil {
+			return err
+		}
+		rules = filterRules(opts, rules)
+		if len(rules) != 1 {
+			return fmt.Errorf("exactly one rule can be renamed, found %d", len(rules))
+		}
+		r = rules[0]
+	}
+	name, ok := r.Attr("name").(*build.StringExpr)
+	if !ok {
+		return fmt.Errorf("the rule on line %d has no literal name", r.Call.Pos.Line)
+	}
+	newLabel := labels.ParseRelative(args[len(args)-1], f.Pkg)
+	if newLabel.Repository != "" {
+		return fmt.Errorf("%q belongs to another repository", args[len(args)-1])
+	}
+	if newLabel.Package == f.Pkg {
+		return renameRule(opts, ws, buildFile, f, name, newLabel.Target)
+	}
+
+	// Check the new name before moving the rule to keep the workspace unchanged on errors
+	destName, dest, err := ws.packageFile(newLabel.Package, filepath.Base(buildFile))
+	if err != nil {
+		return err
+	}
+	if err := checkTargetName(dest, newLabel.Target); err != nil {
+		return err
+	}
+	oldName := name.Value
+	if err := cmdMoveTarget(opts, ws, buildFile, "//"+f.Pkg+":"+oldName, []string{"//" + newLabel.Package}); err != nil {
+		return err
+	}
+	return renameRule(opts, ws, destName, dest, name, newLabel.Target)
+}
+
+// findRuleByLabel returns the rule of a BUILD file with the given label.
+func findRuleByLabel(f *build.File, label string) (*build.Rule, error) {
+	if parsed := labels.ParseRelative(label, f.Pkg); parsed.Repository != "" || parsed.Package != f.Pkg {
+		return nil, fmt.Errorf("%q is not in the package //%s", label, f.Pkg)
+	}
+	for _, r := range f.Rules("") {
+		if r.Name() != "" && labels.Equal(label, "//"+f.Pkg+":"+r.Name(), f.Pkg) {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("rule %q not found", label)
+}
+
+// checkTargetName returns an error if a target with the given name already
+// exists in the package of a BUILD file.
+func checkTargetName(f *build.File, name string) error {
+	for _, other := range f.Rules("") {
+		if ruleTargets(other)[name] {
+			return fmt.Errorf("target %q already exists in the package //%s", name, f.Pkg)
+		}
+	}
+	return nil
+}
+
+// renameRule sets the name of a rule of the BUILD file f and updates all
+// references to it in the workspace.
+func renameRule(opts *Options, ws *workspace, buildFile string, f *build.File, name *build.StringExpr, newName string) error {
+	oldName := name.Value
+	if oldName == newName {
+		return nil
+	}
+	if err := checkTargetName(f, newName); err != nil {
+		return err
+	}
+
+	name.Value = newName
+	ws.modified[buildFile] = true
+	updateReferences(opts, ws, f.Pkg, f.Pkg, map[string]string{oldName: newName})
+	return nil
+}
@@ -0,0 +1,518 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// runWorkspaceTest creates a workspace with the given files, runs buildozer
+// and checks the exit code and the expected contents of the files. A file
+// expected to be empty must not exist.
+func runWorkspaceTest(t *testing.T, files map[string]string, args []string, wantCode int, want map[string]string) {
+	t.Helper()
+	tmp := t.TempDir()
+	if _, ok := files["WORKSPACE"]; !ok {
+		files["WORKSPACE"] = ""
+	}
+	for name, contents := range files {
+		path := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(strings.TrimLeft(contents, "\n")), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr strings.Builder
+	opts := NewOpts()
+	opts.RootDir = tmp
+	opts.Quiet = true
+	opts.OutWriter = &stdout
+	opts.ErrWriter = &stderr
+	if code := Buildozer(opts, args); code != wantCode {
+		t.Errorf("Buildozer() = %d, want %d, stderr: %s", code, wantCode, stderr.String())
+	}
+
+	for name, contents := range want {
+		data, err := os.ReadFile(filepath.Join(tmp, filepath.FromSlash(name)))
+		if contents == "" {
+			if err == nil {
+				t.Errorf("%s exists, want it not to exist", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if diff := cmp.Diff(strings.TrimLeft(contents, "\n"), string(data)); diff != "" {
+			t.Errorf("%s diff -want +got:\n%s", name, diff)
+		}
+	}
+}
+
+func TestMoveTarget(t *testing.T) {
+	runWorkspaceTest(t, map[string]string{
+		"old/BUILD": `
+load("//tools:defs.bzl", "my_library")
+load(":local.bzl", "local_rule", "macro")
+
+my_library(
+    name = "lib",
+    srcs = [
+        "lib.cc",
+        "sub/helper.cc",
+    ],
+    visibility = [":__pkg__"],
+    deps = [
+        ":base",
+        "//other",
+    ] + select({
+        ":cond": [":gen.h"],
+        "//conditions:default": [],
+    }),
+)
+
+macro(name = "base")
+
+genrule(
+    name = "gen",
+    outs = ["gen.h"],
+)
+
+cc_binary(
+    name = "bin",
+    deps = [":lib"],
+)
+
+local_rule(name = "x")
+`,
+		"old/sub/helper.cc": "",
+		"other/BUILD": `
+cc_library(
+    name = "other",
+    deps = [
+        "//old:lib",
+        "//old:base",
+    ],
+)
+`,
+	}, []string{"move_target //old/sub", "//old:lib", "//old:base"}, 0, map[string]string{
+		"old/BUILD": `
+load(":local.bzl", "local_rule")
+
+genrule(
+    name = "gen",
+    outs = ["gen.h"],
+)
+
+cc_binary(
+    name = "bin",
+    deps = ["//old/sub:lib"],
+)
+
+local_rule(name = "x")
+`,
+		"old/sub/BUILD": `
+load("//old:local.bzl", "macro")
+load("//tools:defs.bzl", "my_library")
+
+my_library(
+    name = "lib",
+    srcs = [
+        "helper.cc",
+        "//old:lib.cc",
+    ],
+    visibility = ["//old:__pkg__"],
+    deps = [
+        ":base",
+        "//other",
+    ] + select({
+        "//old:cond": ["//old:gen.h"],
+        "//conditions:default": [],
+    }),
+)
+
+macro(name = "base")
+`,
+		"other/BUILD": `
+cc_library(
+    name = "other",
+    deps = [
+        "//old/sub:base",
+        "//old/sub:lib",
+    ],
+)
+`,
+	})
+}
+
+func TestMoveTargetPackageDefaults(t *testing.T) {
+	runWorkspaceTest(t, map[string]string{
+		"a/BUILD": `
+package(
+    default_testonly = True,
+    default_visibility = ["//visibility:public"],
+)
+
+licenses(["notice"])
+
+cc_library(name = "lib")
+
+cc_library(
+    name = "private",
+    visibility = ["//visibility:private"],
+)
+`,
+		"b/BUILD": `
+package(default_testonly = True)
+`,
+	}, []string{"move_target //b", "//a:lib", "//a:private"}, 0, map[string]string{
+		"b/BUILD": `
+package(default_testonly = True)
+
+cc_library(
+    name = "lib",
+    licenses = ["notice"],
+    visibility = ["//visibility:public"],
+)
+
+cc_library(
+    name = "private",
+    licenses = ["notice"],
+    visibility = ["//visibility:private"],
+)
+`,
+	})
+
+	runWorkspaceTest(t, map[string]string{
+		"a/BUILD": `
+cc_library(name = "lib")
+`,
+		"b/BUILD": `
+package(
+    default_testonly = True,
+    default_visibility = [":__subpackages__"],
+)
+`,
+	}, []string{"move_target //b", "//a:lib"}, 0, map[string]string{
+		"b/BUILD": `
+package(
+    default_testonly = True,
+    default_visibility = [":__subpackages__"],
+)
+
+cc_library(
+    name = "lib",
+    testonly = False,
+    visibility = ["//visibility:private"],
+)
+`,
+	})
+}
+
+func TestMoveTargetErrors(t *testing.T) {
+	files := func() map[string]string {
+		return map[string]string{
+			"old/BUILD": `
+cc_library(name = "lib")
+
+cc_library(
+    name = "globbed",
+    srcs = glob(["*.cc"]),
+)
+`,
+			"new/BUILD": `
+cc_library(name = "lib")
+`,
+			"other/BUILD": `
+cc_library(
+    name = "other",
+    deps = ["//old:lib"],
+)
+`,
+		}
+	}
+	unchanged := files()
+
+	for _, tc := range []struct {
+		name string
+		args []string
+	}{
+		{"existing target", []string{"move_target //new", "//old:lib"}},
+		{"glob", []string{"move_target //new", "//old:globbed"}},
+		{"missing directory", []string{"move_target //missing", "//old:lib"}},
+		{"same package", []string{"move_target //old", "//old:lib"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			runWorkspaceTest(t, files(), tc.args, 2, unchanged)
+		})
+	}
+}
+
+func TestRenameTarget(t *testing.T) {
+	runWorkspaceTest(t, map[string]string{
+		"WORKSPACE": `
+new_local_repository(
+    name = "ext",
+    build_file = "//third_party:ext.BUILD",
+)
+`,
+		"pkg/BUILD": `
+load(":macros.bzl", "my_macro")
+
+cc_library(
+    name = "old",
+    srcs = ["old.cc"],
+)
+
+cc_binary(
+    name = "bin",
+    srcs = ["main.cc"],
+    deps = [
+        "old",
+        "//pkg:old",
+    ] + select({
+        ":cond": [":old"],
+        "//conditions:default": [],
+    }),
+)
+
+my_macro(
+    name = "macro",
+    extra = [":old"],
+    tags = ["old"],
+)
+`,
+		"pkg/macros.bzl": `
+DEFAULT_DEPS = ["//pkg:old"]
+
+def my_macro(name, extra = [], tags = []):
+    native.filegroup(name = name, srcs = extra + [":old"], tags = tags)
+`,
+		"other/BUILD": `
+cc_library(
+    name = "other",
+    deps = [
+        "//pkg:old",
+        "//pkg:old_2",
+        ":old",
+    ],
+)
+`,
+		"third_party/ext.BUILD": `
+cc_library(
+    name = "ext",
+    deps = [
+        "@//pkg:old",
+        "//pkg:old",
+    ],
+)
+`,
+	}, []string{"rename_target new", "//pkg:old"}, 0, map[string]string{
+		"pkg/BUILD": `
+load(":macros.bzl", "my_macro")
+
+cc_library(
+    name = "new",
+    srcs = ["old.cc"],
+)
+
+cc_binary(
+    name = "bin",
+    srcs = ["main.cc"],
+    deps = [
+        "new",
+        "//pkg:new",
+    ] + select({
+        ":cond": [":new"],
+        "//conditions:default": [],
+    }),
+)
+
+my_macro(
+    name = "macro",
+    extra = [":new"],
+    tags = ["old"],
+)
+`,
+		"pkg/macros.bzl": `
+DEFAULT_DEPS = ["//pkg:new"]
+
+def my_macro(name, extra = [], tags = []):
+    native.filegroup(name = name, srcs = extra + [":old"], tags = tags)
+`,
+		"other/BUILD": `
+cc_library(
+    name = "other",
+    deps = [
+        ":old",
+        "//pkg:new",
+        "//pkg:old_2",
+    ],
+)
+`,
+		"third_party/ext.BUILD": `
+cc_library(
+    name = "ext",
+    deps = [
+        "//pkg:old",
+        "@//pkg:new",
+    ],
+)
+`,
+	})
+}
+
+func TestRenameTargetLabels(t *testing.T) {
+	runWorkspaceTest(t, map[string]string{
+		"a/BUILD": `
+cc_library(name = "a")
+
+cc_binary(
+    name = "bin",
+    deps = [
+        "a",
+        ":a",
+        "//a",
+        "//a:a",
+        "@//a:a",
+    ],
+)
+`,
+		"other/BUILD": `
+cc_library(
+    name = "other",
+    deps = [
+        "//a",
+        "@//a:a",
+        "//a:ab",
+    ],
+)
+`,
+	}, []string{"rename_target //a a_lib", "//a:__pkg__"}, 0, map[string]string{
+		"a/BUILD": `
+cc_library(name = "a_lib")
+
+cc_binary(
+    name = "bin",
+    deps = [
+        "a_lib",
+        ":a_lib",
+        "//a:a_lib",
+        "@//a:a_lib",
+    ],
+)
+`,
+		"other/BUILD": `
+cc_library(
+    name = "other",
+    deps = [
+        "//a:a_lib",
+        "//a:ab",
+        "@//a:a_lib",
+    ],
+)
+`,
+	})
+}
+
+func TestRenameTargetToAnotherPackage(t *testing.T) {
+	runWorkspaceTest(t, map[string]string{
+		"pkg/BUILD": `
+cc_library(
+    name = "old",
+    srcs = ["old.cc"],
+)
+
+cc_binary(
+    name = "bin",
+    deps = [":old"],
+)
+`,
+		"other/BUILD": `
+cc_library(
+    name = "other",
+    deps = ["//pkg:old"],
+)
+`,
+	}, []string{"rename_target //pkg:old //other:new", "//pkg:__pkg__"}, 0, map[string]string{
+		"pkg/BUILD": `
+cc_binary(
+    name = "bin",
+    deps = ["//other:new"],
+)
+`,
+		"other/BUILD": `
+cc_library(
+    name = "other",
+    deps = [":new"],
+)
+
+cc_library(
+    name = "new",
+    srcs = ["//pkg:old.cc"],
+)
+`,
+	})
+}
+
+func TestRenameTargetErrors(t *testing.T) {
+	files := func() map[string]string {
+		return map[string]string{
+			"pkg/BUILD": `
+cc_library(name = "old")
+
+genrule(
+    name = "gen",
+    outs = ["out.h"],
+)
+`,
+			"other/BUILD": `
+cc_library(
+    name = "other",
+    deps = ["//pkg:old"],
+)
+`,
+		}
+	}
+	unchanged := files()
+
+	for _, tc := range []struct {
+		name string
+		args []string
+	}{
+		{"existing target", []string{"rename_target gen", "//pkg:old"}},
+		{"existing file", []string{"rename_target out.h", "//pkg:old"}},
+		{"existing target in another package", []string{"rename_target //other:other", "//pkg:old"}},
+		{"another repository", []string{"rename_target @repo//pkg:new", "//pkg:old"}},
+		{"several rules", []string{"rename_target new", "//pkg:*"}},
+		{"old label in another package", []string{"rename_target //other:other new", "//pkg:__pkg__"}},
+		{"old label not found", []string{"rename_target //pkg:missing new", "//pkg:__pkg__"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			runWorkspaceTest(t, files(), tc.args, 2, unchanged)
+		})
+	}
+}
@@ -8,3 +8,7 @@ require (
 	go.starlark.net v0.0.0-20210223155950-e043a3d3c984
 	google.golang.org/protobuf v1.33.0
 )
+
+// golang.org/x/sys/unix is imported by go.starlark.net/starlark, the interpreter
+// of the custom lint warnings (warn/custom).
+require golang.org/x/sys v0.0.0-20200930185726-fdedc70b468f // indirect
@@ -32,6 +32,8 @@ type Definitions struct {
 	StripLabelLeadingSlashes        bool
 	ShortenAbsoluteLabelsToRelative bool
 	AllowedSymbolLoadLocations      map[string][]string
+	SymbolLoadLocations             map[string]string
+	WorkspaceRepoToModule           map[string]string
 }
 
 // ParseJSONDefinitions reads and parses JSON table definitions from file.
@@ -60,5 +62,17 @@ func ParseAndUpdateJSONDefinitions(file string, merge bool) error {
 	} else {
 		OverrideTables(definitions.IsLabelArg, definitions.LabelDenylist, definitions.IsListArg, definitions.IsSortableListArg, definitions.SortableDenylist, definitions.SortableAllowlist, definitions.NamePriority, definitions.StripLabelLeadingSlashes, definitions.ShortenAbsoluteLabelsToRelative, definitions.AllowedSymbolLoadLocations)
 	}
+	updateTable(&SymbolLoadLocations, definitions.SymbolLoadLocations, merge)
+	updateTable(&WorkspaceRepoToModule, definitions.WorkspaceRepoToModule, merge)
 	return nil
 }
+
+// updateTable merges the values into a table or replaces the table with them.
+func updateTable(table *map[string]string, values map[string]string, merge bool) {
+	if !merge {
+		*table = map[string]string{}
+	}
+	for k, v := range values {
+		(*table)[k] = v
+	}
+}
@@ -38,8 +38,44 @@ func TestParseJSONDefinitions(t *testing.T) {
 		NamePriority:               map[string]int{"name": -1},
 		StripLabelLeadingSlashes:   true,
 		AllowedSymbolLoadLocations: map[string][]string{"genrule": {"//tools/bazel:genrule.bzl"}},
+		SymbolLoadLocations:        map[string]string{"genrule": "//tools/bazel:genrule.bzl"},
+		WorkspaceRepoToModule:      map[string]string{"com_example_foo": "foo"},
 	}
 	if !reflect.DeepEqual(expected, definitions) {
 		t.Errorf("ParseJSONDefinitions(simple_tables.json) = %v; want %v", definitions, expected)
 	}
 }
+
+func TestParseAndUpdateJSONDefinitions(t *testing.T) {
+	isLabelArg, labelDenylist, isListArg, isSortableListArg := IsLabelArg, LabelDenylist, IsListArg, IsSortableListArg
+	sortableDenylist, sortableAllowlist, namePriority := SortableDenylist, SortableAllowlist, NamePriority
+	stripLabelLeadingSlashes, shortenAbsoluteLabelsToRelative := StripLabelLeadingSlashes, ShortenAbsoluteLabelsToRelative
+	allowedSymbolLoadLocations, symbolLoadLocations, workspaceRepoToModule := AllowedSymbolLoadLocations, SymbolLoadLocations, WorkspaceRepoToModule
+	defer func() {
+		IsLabelArg, LabelDenylist, IsListArg, IsSortableListArg = isLabelArg, labelDenylist, isListArg, isSortableListArg
+		SortableDenylist, SortableAllowlist, NamePriority = sortableDenylist, sortableAllowlist, namePriority
+		StripLabelLeadingSlashes, ShortenAbsoluteLabelsToRelative = stripLabelLeadingSlashes, shortenAbsoluteLabelsToRelative
+		AllowedSymbolLoadLocations, SymbolLoadLocations, WorkspaceRepoToModule = allowedSymbolLoadLocations, symbolLoadLocations, workspaceRepoToModule
+	}()
+
+	testdata := os.Getenv("TEST_SRCDIR") + "/" + os.Getenv("TEST_WORKSPACE") + "/tables/testdata"
+	if err := ParseAndUpdateJSONDefinitions(testdata+"/simple_tables.json", true); err != nil {
+		t.Fatal(err)
+	}
+	if got := WorkspaceRepoToModule["com_example_foo"]; got != "foo" {
+		t.Errorf("merged WorkspaceRepoToModule[com_example_foo] = %q, want foo", got)
+	}
+	if got := WorkspaceRepoToModule["com_google_protobuf"]; got != "protobuf" {
+		t.Errorf("merged WorkspaceRepoToModule[com_google_protobuf] = %q, want protobuf", got)
+	}
+
+	if err := ParseAndUpdateJSONDefinitions(testdata+"/simple_tables.json", false); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"com_example_foo": "foo"}; !reflect.DeepEqual(WorkspaceRepoToModule, want) {
+		t.Errorf("overridden WorkspaceRepoToModule = %v, want %v", WorkspaceRepoToModule, want)
+	}
+	if want := map[string]string{"genrule": "//tools/bazel:genrule.bzl"}; !reflect.DeepEqual(SymbolLoadLocations, want) {
+		t.Errorf("overridden SymbolLoadLocations = %v, want %v", SymbolLoadLocations, want)
+	}
+}
@@ -256,6 +256,53 @@ var ModuleToLegacyRepoName = map[string]string{
 	"protobuf": "com_google_protobuf",
 }
 
+// WorkspaceRepoToModule contains the mapping from WORKSPACE repository names to the names of
+// the corresponding Bazel modules. It's used to convert WORKSPACE files to MODULE.bazel files.
+var WorkspaceRepoToModule = map[string]string{
+	"apple_support":                    "apple_support",
+	"aspect_bazel_lib":                 "aspect_bazel_lib",
+	"aspect_rules_js":                  "aspect_rules_js",
+	"bazel_features":                   "bazel_features",
+	"bazel_gazelle":                    "gazelle",
+	"bazel_skylib":                     "bazel_skylib",
+	"boringssl":                        "boringssl",
+	"build_bazel_apple_support":        "apple_support",
+	"build_bazel_rules_apple":          "rules_apple",
+	"build_bazel_rules_nodejs":         "rules_nodejs",
+	"build_bazel_rules_swift":          "rules_swift",
+	"com_github_bazelbuild_buildtools": "buildtools",
+	"com_github_gflags_gflags":         "gflags",
+	"com_github_google_benchmark":      "google_benchmark",
+	"com_github_google_glog":           "glog",
+	"com_github_grpc_grpc":             "grpc",
+	"com_google_absl":                  "abseil-cpp",
+	"com_google_googleapis":            "googleapis",
+	"com_google_googletest":            "googletest",
+	"com_google_protobuf":              "protobuf",
+	"com_googlesource_code_re2":        "re2",
+	"io_bazel_rules_go":                "rules_go",
+	"io_bazel_rules_kotlin":            "rules_kotlin",
+	"io_bazel_rules_scala":             "rules_scala",
+	"io_bazel_stardoc":                 "stardoc",
+	"platforms":                        "platforms",
+	"rules_android":                    "rules_android",
+	"rules_cc":                         "rules_cc",
+	"rules_foreign_cc":                 "rules_foreign_cc",
+	"rules_java":                       "rules_java",
+	"rules_jvm_external":               "rules_jvm_external",
+	"rules_license":                    "rules_license",
+	"rules_nodejs":                     "rules_nodejs",
+	"rules_oci":                        "rules_oci",
+	"rules_pkg":                        "rules_pkg",
+	"rules_proto":                      "rules_proto",
+	"rules_python":                     "rules_python",
+	"rules_rust":                       "rules_rust",
+	"rules_shell":                      "rules_shell",
+	"rules_testing":                    "rules_testing",
+	"stardoc":                          "stardoc",
+	"zlib":                             "zlib",
+}
+
 // IsModuleOverride contains the names of all Bzlmod module overrides available in MODULE.bazel.
 var IsModuleOverride = map[string]bool{
 	"archive_override":          true,
@@ -268,6 +315,11 @@ var IsModuleOverride = map[string]bool{
 // AllowedSymbolLoadLocations contains locations for loading rules that are allowed to be used.
 var AllowedSymbolLoadLocations = map[string]map[string]bool{}
 
+// SymbolLoadLocations contains the locations of the .bzl files to load symbols
+// from. It's used by buildozer to add the load statements for the rules it
+// creates.
+var SymbolLoadLocations = map[string]string{}
+
 // OverrideTables allows a user of the build package to override the special-case rules. The user-provided tables replace the built-in tables.
 func OverrideTables(labelArg, denylist, listArg, sortableListArg, sortDenylist, sortAllowlist map[string]bool, namePriority map[string]int, stripLabelLeadingSlashes, shortenAbsoluteLabelsToRelative bool, symbolLoadLocation map[string][]string) {
 	IsLabelArg = labelArg
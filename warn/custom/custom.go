@@ -0,0 +1,290 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package custom loads project-specific warnings defined in Starlark files.
+//
+// A file declares warnings by calling the builtin warning() function:
+//
+//	def _test_tags(file):
+//	    return [
+//	        finding(rule, "Tests should have tags", set_attr(rule, "tags", '["small"]'))
+//	        for rule in file.rules
+//	        if rule.kind.endswith("_test") and not rule.attr("tags")
+//	    ]
+//
+//	warning(name = "test-tags", implementation = _test_tags)
+//
+// The implementation receives a read-only view of the file and returns a list
+// of findings created by the builtin finding() function.
+package custom
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/warn"
+	"go.starlark.net/starlark"
+)
+
+// Warning is a custom warning defined in a Starlark file.
+type Warning struct {
+	// Name is the warning category
+	Name string
+	// URL is the documentation link, defaults to the path of the Starlark file
+	URL string
+
+	filename       string
+	implementation starlark.Callable
+}
+
+// findingValue is a finding returned by the implementation of a warning.
+type findingValue struct {
+	finding *warn.LinterFinding
+}
+
+func (v *findingValue) String() string        { return fmt.Sprintf("<finding %q>", v.finding.Message) }
+func (v *findingValue) Type() string          { return "finding" }
+func (v *findingValue) Freeze()               {}
+func (v *findingValue) Truth() starlark.Bool  { return starlark.True }
+func (v *findingValue) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: finding") }
+
+// replacementValue is a suggested fix of a finding.
+type replacementValue struct {
+	replacement warn.LinterReplacement
+}
+
+func (v *replacementValue) String() string       { return "<replacement>" }
+func (v *replacementValue) Type() string         { return "replacement" }
+func (v *replacementValue) Freeze()              {}
+func (v *replacementValue) Truth() starlark.Bool { return starlark.True }
+func (v *replacementValue) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: replacement")
+}
+
+// LoadFile loads the warnings declared in a Starlark file.
+func LoadFile(filename string) ([]*Warning, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return Load(filename, data)
+}
+
+// Load loads the warnings declared in Starlark source code.
+func Load(filename string, data []byte) ([]*Warning, error) {
+	var warnings []*Warning
+	names := make(map[string]bool)
+
+	declare := func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		w := &Warning{filename: filename}
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "name", &w.Name, "implementation", &w.implementation, "url?", &w.URL); err != nil {
+			return nil, err
+		}
+		if w.Name == "" {
+			return nil, fmt.Errorf("%s: empty warning name", fn.Name())
+		}
+		if names[w.Name] {
+			return nil, fmt.Errorf("%s: warning %q is already declared", fn.Name(), w.Name)
+		}
+		names[w.Name] = true
+		if w.URL == "" {
+			w.URL = filename
+		}
+		warnings = append(warnings, w)
+		return starlark.None, nil
+	}
+
+	predeclared := starlark.StringDict{
+		"warning":  starlark.NewBuiltin("warning", declare),
+		"finding":  starlark.NewBuiltin("finding", newFinding),
+		"replace":  starlark.NewBuiltin("replace", newReplacement),
+		"set_attr": starlark.NewBuiltin("set_attr", newSetAttrReplacement),
+	}
+	thread := newThread(filename)
+	if _, err := starlark.ExecFile(thread, filename, data, predeclared); err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
+
+func newThread(name string) *starlark.Thread {
+	return &starlark.Thread{
+		Name: name,
+		Print: func(thread *starlark.Thread, msg string) {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", thread.Name, msg)
+		},
+	}
+}
+
+// Register adds the warnings to the list of available warnings. Custom warnings
+// are enabled by default.
+func Register(warnings ...*Warning) error {
+	for _, w := range warnings {
+		if err := warn.Register(w.Name, warn.FileCheck(w.check), warn.Options{URL: w.URL}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check runs the implementation of the warning on a file.
+func (w *Warning) check(f *build.File) []*warn.LinterFinding {
+	thread := newThread(w.filename)
+	result, err := starlark.Call(thread, w.implementation, starlark.Tuple{newFileValue(f)}, nil)
+	if err != nil {
+		log.Printf("%s: custom warning %q failed: %v", f.DisplayPath(), w.Name, err)
+		return nil
+	}
+	if result == starlark.None {
+		return nil
+	}
+
+	iterable, ok := result.(starlark.Iterable)
+	if !ok {
+		log.Printf("%s: custom warning %q returned %s, want a list of findings", f.DisplayPath(), w.Name, result.Type())
+		return nil
+	}
+	var findings []*warn.LinterFinding
+	iter := iterable.Iterate()
+	defer iter.Done()
+	var x starlark.Value
+	for iter.Next(&x) {
+		v, ok := x.(*findingValue)
+		if !ok {
+			log.Printf("%s: custom warning %q returned %s, want a finding", f.DisplayPath(), w.Name, x.Type())
+			continue
+		}
+		finding := *v.finding
+		findings = append(findings, &finding)
+	}
+	return findings
+}
+
+// newFinding implements finding(node, message, replacement = None). The
+// replacement can be a single replacement or a list of replacements.
+func newFinding(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var n starlark.Value
+	var message string
+	var replacement starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "node", &n, "message", &message, "replacement?", &replacement); err != nil {
+		return nil, err
+	}
+
+	finding := &warn.LinterFinding{Message: message}
+	if n == starlark.None {
+		// The finding is reported for the whole file
+		finding.Start = build.Position{Line: 1, LineRune: 1}
+		finding.End = finding.Start
+	} else {
+		nn, err := toNode(fn.Name(), n)
+		if err != nil {
+			return nil, err
+		}
+		finding.Start, finding.End = nn.expr.Span()
+	}
+
+	switch r := replacement.(type) {
+	case starlark.NoneType:
+	case *replacementValue:
+		finding.Replacement = append(finding.Replacement, r.replacement)
+	case *starlark.List:
+		for i := 0; i < r.Len(); i++ {
+			rv, ok := r.Index(i).(*replacementValue)
+			if !ok {
+				return nil, fmt.Errorf("%s: got %s in the list of replacements, want replacement", fn.Name(), r.Index(i).Type())
+			}
+			finding.Replacement = append(finding.Replacement, rv.replacement)
+		}
+	default:
+		return nil, fmt.Errorf("%s: got %s for replacement, want replacement or list", fn.Name(), replacement.Type())
+	}
+	return &findingValue{finding}, nil
+}
+
+// newReplacement implements replace(node, code): a fix that replaces a node with
+// the given Starlark code.
+func newReplacement(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var n starlark.Value
+	var code string
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "node", &n, "code", &code); err != nil {
+		return nil, err
+	}
+	nn, err := toNode(fn.Name(), n)
+	if err != nil {
+		return nil, err
+	}
+	if nn.slot == nil {
+		return nil, fmt.Errorf("%s: the node can't be replaced", fn.Name())
+	}
+	expr, err := parseExpr(code)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+	return &replacementValue{warn.LinterReplacement{Old: nn.slot, New: expr}}, nil
+}
+
+// newSetAttrReplacement implements set_attr(rule, name, code): a fix that sets
+// an attribute of a rule to the given Starlark code.
+func newSetAttrReplacement(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var rule *ruleValue
+	var name, code string
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "rule", &rule, "name", &name, "code", &code); err != nil {
+		return nil, err
+	}
+	expr, err := parseExpr(code)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+
+	// Copy the call so that the original rule isn't modified
+	call := *rule.rule.Call
+	call.List = make([]build.Expr, len(rule.rule.Call.List))
+	for i, arg := range rule.rule.Call.List {
+		if as, ok := arg.(*build.AssignExpr); ok {
+			copied := *as
+			arg = &copied
+		}
+		call.List[i] = arg
+	}
+	(&build.Rule{Call: &call}).SetAttr(name, expr)
+	return &replacementValue{warn.LinterReplacement{Old: rule.slot, New: &call}}, nil
+}
+
+func toNode(fnname string, v starlark.Value) (*node, error) {
+	switch v := v.(type) {
+	case *exprValue:
+		return &v.node, nil
+	case *ruleValue:
+		return &v.node, nil
+	case *loadValue:
+		return &v.node, nil
+	}
+	return nil, fmt.Errorf("%s: got %s, want node, rule, or load", fnname, v.Type())
+}
+
+// parseExpr parses a single Starlark expression.
+func parseExpr(code string) (build.Expr, error) {
+	f, err := build.ParseDefault("", []byte(code))
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Stmt) != 1 {
+		return nil, fmt.Errorf("want a single expression, got %q", code)
+	}
+	return f.Stmt[0], nil
+}
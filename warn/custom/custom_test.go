@@ -0,0 +1,161 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custom
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/warn"
+)
+
+const testWarnings = `
+def _test_tags(file):
+    return [
+        finding(rule, "Test %s should have tags" % rule.name, set_attr(rule, "tags", '["small"]'))
+        for rule in file.rules
+        if rule.kind.endswith("_test") and not rule.attr("tags")
+    ]
+
+def _no_old_deps(file):
+    findings = []
+    for rule in file.rules:
+        deps = rule.attr_node("deps")
+        if deps and "//old" in deps.value:
+            new_deps = [d for d in deps.value if d != "//old"] + ["//new"]
+            findings.append(finding(deps, "Use //new instead of //old", replace(deps, repr(new_deps))))
+    for l in file.loads:
+        if l.module == ":old.bzl":
+            findings.append(finding(l, "Don't load %s" % ", ".join(sorted(l.symbols))))
+    return findings
+
+warning(name = "custom-test-tags", implementation = _test_tags)
+warning(name = "custom-no-old-deps", implementation = _no_old_deps, url = "https://example.com/old")
+`
+
+func TestCustomWarnings(t *testing.T) {
+	warnings, err := Load("warnings.star", []byte(testWarnings))
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("got %d warnings, want 2", len(warnings))
+	}
+	if err := Register(warnings...); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+	if err := Register(warnings[0]); err == nil {
+		t.Errorf("Register() for a duplicate warning: got no error")
+	}
+	for _, list := range [][]string{warn.AllWarnings, warn.DefaultWarnings} {
+		if !contains(list, "custom-test-tags") || !contains(list, "custom-no-old-deps") {
+			t.Errorf("custom warnings are not registered: %v", list)
+		}
+	}
+
+	input := `load(":old.bzl", "a", "b")
+
+cc_test(name = "a")
+
+cc_test(
+    name = "b",
+    tags = ["large"],
+)
+
+cc_library(
+    name = "c",
+    deps = ["//old", "//other"],
+)
+
+# buildifier: disable=custom-test-tags
+cc_test(name = "d")
+`
+	f, err := build.ParseBuild("BUILD", []byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	categories := []string{"custom-no-old-deps", "custom-test-tags"}
+
+	var got []string
+	for _, finding := range warn.FileWarnings(f, categories, nil, warn.ModeWarn, nil) {
+		got = append(got, fmt.Sprintf("%d: %s: %s (%s)", finding.Start.Line, finding.Category, finding.Message, finding.URL))
+	}
+	want := []string{
+		"1: custom-no-old-deps: Don't load a, b (https://example.com/old)",
+		"3: custom-test-tags: Test a should have tags (warnings.star)",
+		"12: custom-no-old-deps: Use //new instead of //old (https://example.com/old)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	warn.FixWarnings(f, categories, false, nil)
+	wantFixed := `load(":old.bzl", "a", "b")
+
+cc_test(
+    name = "a",
+    tags = ["small"],
+)
+
+cc_test(
+    name = "b",
+    tags = ["large"],
+)
+
+cc_library(
+    name = "c",
+    deps = [
+        "//new",
+        "//other",
+    ],
+)
+
+# buildifier: disable=custom-test-tags
+cc_test(name = "d")
+`
+	if fixed := string(build.Format(f)); fixed != wantFixed {
+		t.Errorf("fixed file:\n%s\nwant:\n%s", fixed, wantFixed)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	for _, tc := range []struct {
+		code    string
+		wantErr string
+	}{
+		{"warning(name = 'foo')", "missing argument for implementation"},
+		{"warning(name = '', implementation = len)", "empty warning name"},
+		{"warning(name = 'foo', implementation = len)\nwarning(name = 'foo', implementation = len)", `warning "foo" is already declared`},
+		{"foo(", "got end of file"},
+	} {
+		_, err := Load("test.star", []byte(tc.code))
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("Load(%q) = %v, want error containing %q", tc.code, err, tc.wantErr)
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
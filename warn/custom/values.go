@@ -0,0 +1,273 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custom
+
+// Read-only Starlark views of the syntax tree passed to the custom warnings.
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/bazelbuild/buildtools/build"
+	"go.starlark.net/starlark"
+)
+
+// node is a syntax tree node that can be reported by a finding. If slot is
+// set, the node can also be replaced.
+type node struct {
+	expr build.Expr
+	slot *build.Expr
+}
+
+func (n *node) line() starlark.Int {
+	start, _ := n.expr.Span()
+	return starlark.MakeInt(start.Line)
+}
+
+// exprValue is a generic node, e.g. the value of an attribute.
+type exprValue struct {
+	node
+}
+
+func (v *exprValue) String() string        { return fmt.Sprintf("<node %s>", build.FormatString(v.expr)) }
+func (v *exprValue) Type() string          { return "node" }
+func (v *exprValue) Freeze()               {}
+func (v *exprValue) Truth() starlark.Bool  { return starlark.True }
+func (v *exprValue) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: node") }
+
+func (v *exprValue) AttrNames() []string { return []string{"line", "source", "value"} }
+
+func (v *exprValue) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "line":
+		return v.line(), nil
+	case "source":
+		return starlark.String(build.FormatString(v.expr)), nil
+	case "value":
+		return literalValue(v.expr), nil
+	}
+	return nil, nil
+}
+
+// ruleValue is a call of a rule or a macro on the top level of a file.
+type ruleValue struct {
+	node
+	rule *build.Rule
+}
+
+func (v *ruleValue) String() string        { return fmt.Sprintf("<rule %s>", v.rule.Name()) }
+func (v *ruleValue) Type() string          { return "rule" }
+func (v *ruleValue) Freeze()               {}
+func (v *ruleValue) Truth() starlark.Bool  { return starlark.True }
+func (v *ruleValue) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: rule") }
+
+func (v *ruleValue) AttrNames() []string {
+	return []string{"attr", "attr_names", "attr_node", "kind", "line", "name", "source"}
+}
+
+func (v *ruleValue) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "kind":
+		return starlark.String(v.rule.Kind()), nil
+	case "name":
+		return starlark.String(v.rule.Name()), nil
+	case "line":
+		return v.line(), nil
+	case "source":
+		return starlark.String(build.FormatString(v.expr)), nil
+	case "attr_names":
+		return stringList(v.rule.AttrKeys()), nil
+	case "attr":
+		return starlark.NewBuiltin("attr", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var key string
+			if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &key); err != nil {
+				return nil, err
+			}
+			value := v.rule.Attr(key)
+			if value == nil {
+				return starlark.None, nil
+			}
+			return literalValue(value), nil
+		}), nil
+	case "attr_node":
+		return starlark.NewBuiltin("attr_node", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var key string
+			if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &key); err != nil {
+				return nil, err
+			}
+			as := v.rule.AttrDefn(key)
+			if as == nil {
+				return starlark.None, nil
+			}
+			return &exprValue{node{expr: as.RHS, slot: &as.RHS}}, nil
+		}), nil
+	}
+	return nil, nil
+}
+
+// loadValue is a load statement.
+type loadValue struct {
+	node
+	load *build.LoadStmt
+}
+
+func (v *loadValue) String() string        { return fmt.Sprintf("<load %s>", v.load.Module.Value) }
+func (v *loadValue) Type() string          { return "load" }
+func (v *loadValue) Freeze()               {}
+func (v *loadValue) Truth() starlark.Bool  { return starlark.True }
+func (v *loadValue) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: load") }
+
+func (v *loadValue) AttrNames() []string { return []string{"line", "module", "source", "symbols"} }
+
+func (v *loadValue) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "line":
+		return v.line(), nil
+	case "module":
+		return starlark.String(v.load.Module.Value), nil
+	case "source":
+		return starlark.String(build.FormatString(v.expr)), nil
+	case "symbols":
+		// Maps local names to the original names
+		symbols := starlark.NewDict(len(v.load.To))
+		for i := range v.load.To {
+			symbols.SetKey(starlark.String(v.load.To[i].Name), starlark.String(v.load.From[i].Name))
+		}
+		symbols.Freeze()
+		return symbols, nil
+	}
+	return nil, nil
+}
+
+// fileValue is the file being checked.
+type fileValue struct {
+	file  *build.File
+	rules *starlark.List
+	loads *starlark.List
+}
+
+func newFileValue(f *build.File) *fileValue {
+	var rules, loads []starlark.Value
+	for i, stmt := range f.Stmt {
+		switch stmt := stmt.(type) {
+		case *build.CallExpr:
+			rules = append(rules, &ruleValue{
+				node: node{expr: stmt, slot: &f.Stmt[i]},
+				rule: &build.Rule{Call: stmt},
+			})
+		case *build.LoadStmt:
+			loads = append(loads, &loadValue{
+				node: node{expr: stmt, slot: &f.Stmt[i]},
+				load: stmt,
+			})
+		}
+	}
+	v := &fileValue{
+		file:  f,
+		rules: starlark.NewList(rules),
+		loads: starlark.NewList(loads),
+	}
+	v.rules.Freeze()
+	v.loads.Freeze()
+	return v
+}
+
+func (v *fileValue) String() string        { return fmt.Sprintf("<file %s>", v.file.DisplayPath()) }
+func (v *fileValue) Type() string          { return "file" }
+func (v *fileValue) Freeze()               {}
+func (v *fileValue) Truth() starlark.Bool  { return starlark.True }
+func (v *fileValue) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: file") }
+
+func (v *fileValue) AttrNames() []string {
+	return []string{"label", "loads", "path", "pkg", "rules", "type"}
+}
+
+func (v *fileValue) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "path":
+		return starlark.String(v.file.Path), nil
+	case "pkg":
+		return starlark.String(v.file.Pkg), nil
+	case "label":
+		return starlark.String(v.file.Label), nil
+	case "type":
+		return starlark.String(v.file.Type.String()), nil
+	case "rules":
+		return v.rules, nil
+	case "loads":
+		return v.loads, nil
+	}
+	return nil, nil
+}
+
+// literalValue converts a literal expression into a Starlark value. Returns None
+// if the expression is not a literal.
+func literalValue(expr build.Expr) starlark.Value {
+	switch expr := expr.(type) {
+	case *build.StringExpr:
+		return starlark.String(expr.Value)
+	case *build.LiteralExpr:
+		if i, err := strconv.ParseInt(expr.Token, 0, 64); err == nil {
+			return starlark.MakeInt64(i)
+		}
+	case *build.Ident:
+		switch expr.Name {
+		case "True":
+			return starlark.True
+		case "False":
+			return starlark.False
+		}
+	case *build.ListExpr:
+		var elems []starlark.Value
+		for _, e := range expr.List {
+			elems = append(elems, literalValue(e))
+		}
+		list := starlark.NewList(elems)
+		list.Freeze()
+		return list
+	case *build.TupleExpr:
+		var elems starlark.Tuple
+		for _, e := range expr.List {
+			elems = append(elems, literalValue(e))
+		}
+		return elems
+	case *build.DictExpr:
+		dict := starlark.NewDict(len(expr.List))
+		for _, kv := range expr.List {
+			key := literalValue(kv.Key)
+			if _, err := key.Hash(); err != nil {
+				return starlark.None
+			}
+			dict.SetKey(key, literalValue(kv.Value))
+		}
+		dict.Freeze()
+		return dict
+	}
+	return starlark.None
+}
+
+func stringList(values []string) *starlark.List {
+	sort.Strings(values)
+	var elems []starlark.Value
+	for _, v := range values {
+		elems = append(elems, starlark.String(v))
+	}
+	list := starlark.NewList(elems)
+	list.Freeze()
+	return list
+}
@@ -18,13 +18,20 @@ package warn
 
 import (
 	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/wspace"
 )
 
 // FileReader is a class that can read an arbitrary Starlark file
 // from the repository and cache the results.
 type FileReader struct {
-	cache    map[string]*build.File
-	readFile func(string) ([]byte, error)
+	cache     map[string]*build.File
+	readFile  func(string) ([]byte, error)
+	listFiles func() ([]string, error)
+	files     []string
+	// checks whether a file or a directory exists, see SetPathChecker
+	pathExists func(string) bool
+	// the BUILD files of external repositories per workspace root, see wspace.FindRepoBuildFiles
+	repoFiles map[string]map[string]string
 }
 
 // NewFileReader creates and initializes a FileReader instance with a
@@ -75,3 +82,57 @@ func (fr *FileReader) GetFile(pkg, label string) *build.File {
 	fr.cache[filename] = file
 	return file
 }
+
+// SetFileLister sets a function that returns the paths of all BUILD and .bzl
+// files of the repository relative to the workspace root (OS-independent, with
+// forward slashes). It's needed by the warnings that inspect the whole
+// repository, they are not reported if it's not set.
+func (fr *FileReader) SetFileLister(listFiles func() ([]string, error)) {
+	fr.listFiles = listFiles
+	fr.files = nil
+}
+
+// ListFiles returns the paths of all BUILD and .bzl files of the repository,
+// or nil if they can't be listed.
+func (fr *FileReader) ListFiles() []string {
+	if fr.files != nil || fr.listFiles == nil {
+		return fr.files
+	}
+	files, err := fr.listFiles()
+	if err != nil {
+		return nil
+	}
+	fr.files = append([]string{}, files...)
+	return fr.files
+}
+
+// SetPathChecker sets a function that checks whether a file or a directory
+// exists in the repository, given its path relative to the workspace root
+// (OS-independent, with forward slashes). If it's not set, only the files that
+// can be read by the readFile function exist.
+func (fr *FileReader) SetPathChecker(pathExists func(string) bool) {
+	fr.pathExists = pathExists
+}
+
+// PathExists checks whether a file or a directory exists in the repository.
+func (fr *FileReader) PathExists(path string) bool {
+	if fr.pathExists != nil {
+		return fr.pathExists(path)
+	}
+	_, err := fr.readFile(path)
+	return err == nil
+}
+
+// repoBuildFiles returns the paths of the BUILD files of the external repositories
+// defined in the WORKSPACE file of a workspace, see wspace.FindRepoBuildFiles.
+func (fr *FileReader) repoBuildFiles(workspaceRoot string) map[string]string {
+	if files, ok := fr.repoFiles[workspaceRoot]; ok {
+		return files
+	}
+	if fr.repoFiles == nil {
+		fr.repoFiles = make(map[string]map[string]string)
+	}
+	files, _ := wspace.FindRepoBuildFiles(workspaceRoot)
+	fr.repoFiles[workspaceRoot] = files
+	return files
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warn
+
+// Public API for registering additional warnings, e.g. in a custom buildifier
+// binary that imports this package.
+
+import (
+	"fmt"
+
+	"github.com/bazelbuild/buildtools/build"
+)
+
+// FileCheck is a warning function that runs on the whole file.
+type FileCheck = func(f *build.File) []*LinterFinding
+
+// MultiFileCheck is a warning function that runs on the whole file and may read
+// other files of the repository using the FileReader. The FileReader can be nil
+// if the workspace root is unknown.
+type MultiFileCheck = func(f *build.File, fileReader *FileReader) []*LinterFinding
+
+// RuleCheck is a warning function that runs on each top-level call in BUILD
+// files. It may return nil if there's nothing to report.
+type RuleCheck = func(call *build.CallExpr, pkg string) *LinterFinding
+
+// Options configures a registered warning.
+type Options struct {
+	// DisabledByDefault excludes the warning from the default warnings set,
+	// it can still be enabled with the --warnings flag.
+	DisabledByDefault bool
+	// URL is the documentation link for the findings that don't set their own.
+	// The findings have no link if it's empty.
+	URL string
+}
+
+// registeredWarnings are the names of the warnings added with Register, which
+// aren't documented in WARNINGS.md.
+var registeredWarnings = make(map[string]bool)
+
+// Register adds a warning to the list of available warnings. The check must
+// be a FileCheck, a MultiFileCheck, or a RuleCheck. The findings of registered
+// warnings respect the `# buildifier: disable=<name>` comments and can be fixed
+// automatically if they contain replacements, same as the built-in warnings.
+//
+// Register is not safe for concurrent use and should be called before any
+// files are linted, e.g. from an init function.
+func Register(name string, check interface{}, opts Options) error {
+	if name == "" {
+		return fmt.Errorf("empty warning name")
+	}
+	if _, ok := FileWarningMap[name]; ok {
+		return fmt.Errorf("warning %q is already defined", name)
+	}
+	if _, ok := MultiFileWarningMap[name]; ok {
+		return fmt.Errorf("warning %q is already defined", name)
+	}
+	if _, ok := RuleWarningMap[name]; ok {
+		return fmt.Errorf("warning %q is already defined", name)
+	}
+
+	switch check := check.(type) {
+	case FileCheck:
+		FileWarningMap[name] = func(f *build.File) []*LinterFinding {
+			return setURL(check(f), opts.URL)
+		}
+	case MultiFileCheck:
+		MultiFileWarningMap[name] = func(f *build.File, fileReader *FileReader) []*LinterFinding {
+			return setURL(check(f, fileReader), opts.URL)
+		}
+	case RuleCheck:
+		RuleWarningMap[name] = func(call *build.CallExpr, pkg string) *LinterFinding {
+			finding := check(call, pkg)
+			if finding != nil && finding.URL == "" {
+				finding.URL = opts.URL
+			}
+			return finding
+		}
+	default:
+		return fmt.Errorf("unsupported check type %T for warning %q", check, name)
+	}
+
+	registeredWarnings[name] = true
+	if opts.DisabledByDefault {
+		nonDefaultWarnings[name] = true
+	}
+	AllWarnings = collectAllWarnings()
+	DefaultWarnings = collectDefaultWarnings()
+	return nil
+}
+
+// setURL sets the URL of the findings that don't have one.
+func setURL(findings []*LinterFinding, url string) []*LinterFinding {
+	for _, f := range findings {
+		if f.URL == "" {
+			f.URL = url
+		}
+	}
+	return findings
+}
+
+// NewLinterFinding creates a finding for a node. The replacements, if any, are
+// used to fix the finding automatically.
+func NewLinterFinding(node build.Expr, message string, replacement ...LinterReplacement) *LinterFinding {
+	return makeLinterFinding(node, message, replacement...)
+}
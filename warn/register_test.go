@@ -0,0 +1,142 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warn
+
+import (
+	"testing"
+
+	"github.com/bazelbuild/buildtools/build"
+)
+
+// unregister removes registered warnings so that they don't affect other tests.
+func unregister(names ...string) {
+	for _, name := range names {
+		delete(FileWarningMap, name)
+		delete(MultiFileWarningMap, name)
+		delete(RuleWarningMap, name)
+		delete(nonDefaultWarnings, name)
+		delete(registeredWarnings, name)
+	}
+	AllWarnings = collectAllWarnings()
+	DefaultWarnings = collectDefaultWarnings()
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRegister(t *testing.T) {
+	defer unregister("test-file", "test-multi-file", "test-rule")
+
+	fileCheck := func(f *build.File) []*LinterFinding {
+		var findings []*LinterFinding
+		for i, stmt := range f.Stmt {
+			if ident, ok := stmt.(*build.Ident); ok && ident.Name == "foo" {
+				findings = append(findings, NewLinterFinding(stmt, "Use bar instead of foo",
+					LinterReplacement{&f.Stmt[i], &build.Ident{Name: "bar"}}))
+			}
+		}
+		return findings
+	}
+	if err := Register("test-file", fileCheck, Options{URL: "https://example.com/file"}); err != nil {
+		t.Fatalf("Register(test-file) = %v", err)
+	}
+
+	multiFileCheck := MultiFileCheck(func(f *build.File, fileReader *FileReader) []*LinterFinding {
+		if fileReader == nil || fileReader.GetFile("", "README") != nil {
+			return nil
+		}
+		return []*LinterFinding{{Start: build.Position{Line: 1}, End: build.Position{Line: 1}, Message: "No README", URL: "https://example.com/readme"}}
+	})
+	if err := Register("test-multi-file", multiFileCheck, Options{DisabledByDefault: true}); err != nil {
+		t.Fatalf("Register(test-multi-file) = %v", err)
+	}
+
+	ruleCheck := func(call *build.CallExpr, pkg string) *LinterFinding {
+		if rule := (&build.Rule{Call: call}); rule.Kind() == "cc_test" && rule.Attr("size") == nil {
+			return NewLinterFinding(call, "Tests should have size")
+		}
+		return nil
+	}
+	if err := Register("test-rule", ruleCheck, Options{}); err != nil {
+		t.Fatalf("Register(test-rule) = %v", err)
+	}
+
+	if err := Register("test-rule", ruleCheck, Options{}); err == nil {
+		t.Errorf("Register() for a duplicate warning: got no error")
+	}
+	if err := Register("integer-division", fileCheck, Options{}); err == nil {
+		t.Errorf("Register() for a built-in warning: got no error")
+	}
+	if err := Register("test-invalid", func() {}, Options{}); err == nil {
+		t.Errorf("Register() for an unsupported function type: got no error")
+	}
+
+	for _, name := range []string{"test-file", "test-multi-file", "test-rule"} {
+		if !contains(AllWarnings, name) {
+			t.Errorf("AllWarnings doesn't contain %q", name)
+		}
+		if wantDefault := name != "test-multi-file"; contains(DefaultWarnings, name) != wantDefault {
+			t.Errorf("DefaultWarnings contains %q: got %t, want %t", name, !wantDefault, wantDefault)
+		}
+	}
+
+	checkFindingsAndFix(t, "test-file", `
+foo
+# buildifier: disable=test-file
+foo
+`, `
+bar
+# buildifier: disable=test-file
+foo
+`, []string{
+		":1: Use bar instead of foo",
+	}, scopeEverywhere)
+
+	checkFindings(t, "test-rule", `
+cc_test(name = "a")
+cc_test(name = "b", size = "small")
+`, []string{
+		":1: Tests should have size",
+	}, scopeBuild)
+
+	defer setUpFileReader(nil)()
+	checkFindings(t, "test-multi-file", `
+foo()
+`, []string{
+		":1: No README",
+	}, scopeEverywhere)
+
+	for _, tc := range []struct {
+		category string
+		url      string
+	}{
+		{"test-file", "https://example.com/file"},
+		{"test-multi-file", "https://example.com/readme"},
+		{"test-rule", ""},
+	} {
+		findings := getFindings(tc.category, "foo\ncc_test(name = 'a')", build.TypeBuild)
+		if len(findings) != 1 || findings[0].URL != tc.url {
+			t.Errorf("%s: unexpected findings %+v, want URL %q", tc.category, findings, tc.url)
+		}
+	}
+}
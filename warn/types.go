@@ -61,6 +61,52 @@ func (t Type) String() string {
 
 var intRegexp = regexp.MustCompile(`^([0-9]+|0[Xx][0-9A-Fa-f]+|0[Oo][0-7]+)$`)
 
+// annotationType returns the type described by a type annotation, e.g. List for `list[str]`.
+// Returns Unknown for types that can't be described by Type, including unions such as
+// `list | None`.
+func annotationType(annotation build.Expr) Type {
+	switch annotation := annotation.(type) {
+	case *build.Ident:
+		switch annotation.Name {
+		case "bool":
+			return Bool
+		case "ctx":
+			return Ctx
+		case "depset":
+			return Depset
+		case "dict":
+			return Dict
+		case "float":
+			return Float
+		case "int":
+			return Int
+		case "list":
+			return List
+		case "None":
+			return None
+		case "str":
+			return String
+		}
+	case *build.IndexExpr:
+		// Generic types, e.g. `list[str]`
+		return annotationType(annotation.X)
+	}
+	return Unknown
+}
+
+// paramAnnotation returns the type annotation of a function parameter, or nil if it's not annotated.
+// Annotations of `*args` and `**kwargs` are ignored because they describe the items of the
+// parameters rather than the parameters themselves.
+func paramAnnotation(param build.Expr) build.Expr {
+	if assign, ok := param.(*build.AssignExpr); ok {
+		param = assign.LHS
+	}
+	if typed, ok := param.(*build.TypedIdent); ok {
+		return typed.Type
+	}
+	return nil
+}
+
 // DetectTypes tries to infer the type of expressions in the current file, using basic heuristics.
 //
 // Warning: the types inferred by the function might change in the future, as we update the
@@ -102,6 +148,13 @@ func DetectTypes(f *build.File) map[build.Expr]Type {
 			}
 		case *build.CallExpr:
 			if ident, ok := (node.X).(*build.Ident); ok {
+				if binding := env.Get(ident.Name); binding != nil && binding.Kind == bzlenv.Function {
+					// A function defined in the file, use its return type annotation
+					if def, ok := binding.Definition.(*build.DefStmt); ok && def.Type != nil {
+						nodeType = annotationType(def.Type)
+					}
+					return
+				}
 				switch ident.Name {
 				case "bool":
 					nodeType = Bool
@@ -141,11 +194,18 @@ func DetectTypes(f *build.File) map[build.Expr]Type {
 				}
 			}
 			binding := env.Get(node.Name)
-			if binding != nil {
-				if t, ok := variables[binding.ID]; ok {
-					nodeType = t
+			if binding == nil {
+				return
+			}
+			if binding.Kind == bzlenv.Parameter {
+				if annotation := paramAnnotation(binding.Definition); annotation != nil {
+					nodeType = annotationType(annotation)
+					return
 				}
 			}
+			if t, ok := variables[binding.ID]; ok {
+				nodeType = t
+			}
 		case *build.DotExpr:
 			if result[node.X] == Ctx && node.Name == "actions" {
 				nodeType = CtxActions
@@ -172,6 +232,14 @@ func DetectTypes(f *build.File) map[build.Expr]Type {
 			}
 		case *build.AssignExpr:
 			t, ok := result[node.RHS]
+			lhs := node.LHS
+			if typed, isTyped := lhs.(*build.TypedIdent); isTyped {
+				// The annotated type takes precedence over the inferred one
+				lhs = typed.Ident
+				if annotated := annotationType(typed.Type); annotated != Unknown {
+					t, ok = annotated, true
+				}
+			}
 			if !ok {
 				return
 			}
@@ -179,7 +247,7 @@ func DetectTypes(f *build.File) map[build.Expr]Type {
 				// If the right hand side is not a string, the left hand side can still be a string
 				return
 			}
-			ident, ok := (node.LHS).(*build.Ident)
+			ident, ok := lhs.(*build.Ident)
 			if !ok {
 				return
 			}
@@ -193,6 +261,22 @@ func DetectTypes(f *build.File) map[build.Expr]Type {
 	var expr build.Expr = f
 	walk(&expr, bzlenv.NewEnvironment())
 
+	// Type annotations aren't values, forget the types detected inside them
+	build.Walk(f, func(expr build.Expr, stack []build.Expr) {
+		var annotation build.Expr
+		switch expr := expr.(type) {
+		case *build.TypedIdent:
+			annotation = expr.Type
+		case *build.DefStmt:
+			annotation = expr.Type
+		}
+		if annotation != nil {
+			build.Walk(annotation, func(x build.Expr, stack []build.Expr) {
+				delete(result, x)
+			})
+		}
+	})
+
 	return result
 }
 
@@ -260,3 +260,51 @@ def foobar(foo, bar):
     ctx.actions.args()
 `)
 }
+
+func TestTypeAnnotations(t *testing.T) {
+	checkTypes(t, `
+def f(a: list[str], b: dict = {}, c: str | None = None, *args: int, **kwargs: dict) -> depset:
+    x = a
+    y = b
+    z = c
+    w = args
+    return depset()
+
+def g():
+    pass
+
+n: int = foo()
+m: bool
+d = f([])
+e = g()
+`, `
+def f(list:<a>: list[str], dict:<b>: dict = dict:<{}>, c: str | None = none:<None>, *args: int, **kwargs: dict) -> depset:
+    x = list:<a>
+    y = dict:<b>
+    z = c
+    w = args
+    return depset:<depset()>
+
+def g():
+    pass
+
+n: int = foo()
+m: bool
+d = depset:<f(list:<[]>)>
+e = g()
+`)
+}
+
+func TestTypeAnnotationsOfVariables(t *testing.T) {
+	checkTypes(t, `
+a: list = foo()
+b: str | None = foo()
+c = a
+d = b
+`, `
+a: list = foo()
+b: str | None = foo()
+c = list:<a>
+d = b
+`)
+}
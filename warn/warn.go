@@ -83,7 +83,7 @@ func docURL(cat string) string {
 
 // makeFinding creates a Finding object
 func makeFinding(f *build.File, start, end build.Position, cat, url, msg string, actionable bool, autoFixable bool, fix *Replacement) *Finding {
-	if url == "" {
+	if url == "" && !registeredWarnings[cat] {
 		url = docURL(cat)
 	}
 	return &Finding{
@@ -133,8 +133,11 @@ var FileWarningMap = map[string]func(f *build.File) []*LinterFinding{
 	"depset-items":                  depsetItemsWarning,
 	"depset-iteration":              depsetIterationWarning,
 	"depset-union":                  depsetUnionWarning,
+	"dev-dependency-mismatch":       devDependencyMismatchWarning,
+	"dev-dependency-registration":   devDependencyRegistrationWarning,
 	"dict-method-named-arg":         dictMethodNamedArgWarning,
 	"dict-concatenation":            dictionaryConcatenationWarning,
+	"duplicate-bazel-dep":           duplicateBazelDepWarning,
 	"duplicated-name":               duplicatedNameWarning,
 	"external-path":                 externalPathWarning,
 	"filetype":                      fileTypeWarning,
@@ -164,15 +167,19 @@ var FileWarningMap = map[string]func(f *build.File) []*LinterFinding{
 	"skylark-comment":               skylarkCommentWarning,
 	"skylark-docstring":             skylarkDocstringWarning,
 	"string-iteration":              stringIterationWarning,
+	"type-annotation-mismatch":      typeAnnotationMismatchWarning,
 	"uninitialized":                 uninitializedVariableWarning,
 	"unreachable":                   unreachableStatementWarning,
+	"unsorted-bazel-deps":           unsortedBazelDepsWarning,
 	"unsorted-dict-items":           unsortedDictItemsWarning,
+	"unused-extension":              unusedExtensionWarning,
 	"unused-variable":               unusedVariableWarning,
 }
 
 // MultiFileWarningMap lists the warnings that run on the whole file, but may use other files.
 var MultiFileWarningMap = map[string]func(f *build.File, fileReader *FileReader) []*LinterFinding{
 	"bzl-visibility":                     bzlVisibilityWarning,
+	"dangling-label":                     danglingLabelWarning,
 	"deprecated-function":                deprecatedFunctionWarning,
 	"git-repository":                     nativeGitRepositoryWarning,
 	"http-archive":                       nativeHTTPArchiveWarning,
@@ -218,14 +225,21 @@ var MultiFileWarningMap = map[string]func(f *build.File, fileReader *FileReader)
 	"native-sh-binary":                   NativeShellRulesWarning("sh_binary"),
 	"native-sh-library":                  NativeShellRulesWarning("sh_library"),
 	"native-sh-test":                     NativeShellRulesWarning("sh_test"),
+	"non-root-override":                  nonRootOverrideWarning,
 	"positional-args":                    positionalArgumentsWarning,
+	"target-visibility":                  targetVisibilityWarning,
 	"unnamed-macro":                      unnamedMacroWarning,
+	"use-repo":                           useRepoWarning,
 }
 
 // nonDefaultWarnings contains warnings that are enabled by default because they're not applicable
 // for all files and cause too much diff noise when applied.
 var nonDefaultWarnings = map[string]bool{
+	"dangling-label":      true, // reads the BUILD files of all dependencies
+	"target-visibility":   true, // reads the BUILD files of all dependencies
+	"unsorted-bazel-deps": true, // bazel_dep calls should be sorted
 	"unsorted-dict-items": true, // dict items should be sorted
+	"use-repo":            true, // reads all BUILD and .bzl files of the module
 }
 
 // fileWarningWrapper is a wrapper that converts a file warning function to a generic function.
@@ -434,6 +448,71 @@ func FixWarnings(f *build.File, enabledWarnings []string, verbose bool, fileRead
 	}
 }
 
+// A FixConflict is reported when the suggested fixes of two findings overlap.
+// Only the fix of the Applied finding is applied.
+type FixConflict struct {
+	Applied *Finding
+	Skipped *Finding
+}
+
+// overlaps checks whether two replacements modify the same part of a file.
+// Insertions at the same position are also considered overlapping because
+// the order in which they should be applied is undefined.
+func (r *Replacement) overlaps(other *Replacement) bool {
+	if r.Start == other.Start {
+		return true
+	}
+	return r.Start < other.End && other.Start < r.End
+}
+
+// ApplyReplacements applies the suggested fixes of the findings to contents,
+// which must be the same contents the fixes were calculated for. The fixes are
+// applied in the order of the findings, a fix that overlaps with an already
+// applied one is skipped and reported as a conflict.
+func ApplyReplacements(contents []byte, findings []*Finding) ([]byte, []FixConflict) {
+	var applied []*Finding
+	var conflicts []FixConflict
+	for _, finding := range findings {
+		r := finding.Replacement
+		if r == nil || (r.Start == r.End && r.Content == "") {
+			continue
+		}
+		conflicting := false
+		for _, other := range applied {
+			if r.overlaps(other.Replacement) {
+				conflicts = append(conflicts, FixConflict{Applied: other, Skipped: finding})
+				conflicting = true
+				break
+			}
+		}
+		if !conflicting {
+			applied = append(applied, finding)
+		}
+	}
+
+	sort.Slice(applied, func(i, j int) bool { return applied[i].Replacement.Start < applied[j].Replacement.Start })
+	var result []byte
+	offset := 0
+	for _, finding := range applied {
+		r := finding.Replacement
+		result = append(result, contents[offset:r.Start]...)
+		result = append(result, r.Content...)
+		offset = r.End
+	}
+	result = append(result, contents[offset:]...)
+	return result, conflicts
+}
+
+// FixWarningsIndependently calculates the fix for each warning that can be fixed
+// automatically independently of other fixes, and applies the fixes that don't
+// overlap. Unlike FixWarnings it doesn't modify the file but returns the new
+// formatted file contents and the list of conflicting fixes.
+func FixWarningsIndependently(f *build.File, enabledWarnings []string, fileReader *FileReader) ([]byte, []FixConflict) {
+	formatted := build.Format(f)
+	findings := FileWarnings(f, enabledWarnings, &formatted, ModeSuggest, fileReader)
+	return ApplyReplacements(formatted, findings)
+}
+
 func collectAllWarnings() []string {
 	var result []string
 	// Collect list of all warnings.
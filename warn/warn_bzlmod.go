@@ -0,0 +1,717 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Warnings for MODULE.bazel files
+
+package warn
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/labels"
+	"github.com/bazelbuild/buildtools/tables"
+)
+
+// builtinRepos are the repositories that are visible in every module.
+var builtinRepos = map[string]bool{
+	"":                      true, // The main repository, e.g. "@//pkg:target"
+	"bazel_tools":           true,
+	"local_config_platform": true,
+}
+
+// moduleFiles returns the MODULE.bazel file and the segments it includes.
+func moduleFiles(f *build.File, fileReader *FileReader) []*build.File {
+	files := []*build.File{f}
+	seen := map[string]bool{path.Join(f.Pkg, f.Label): true}
+	for i := 0; i < len(files); i++ {
+		for _, stmt := range files[i].Stmt {
+			call, ok := stmt.(*build.CallExpr)
+			if !ok || len(call.List) != 1 {
+				continue
+			}
+			if ident, ok := call.X.(*build.Ident); !ok || ident.Name != "include" {
+				continue
+			}
+			str, ok := call.List[0].(*build.StringExpr)
+			if !ok {
+				continue
+			}
+			l := labels.Parse(str.Value)
+			pkg := path.Join(f.Pkg, l.Package)
+			if pkg == "." {
+				pkg = ""
+			}
+			if name := path.Join(pkg, l.Target); !seen[name] {
+				seen[name] = true
+				if segment := fileReader.GetFile(pkg, l.Target); segment != nil {
+					files = append(files, segment)
+				}
+			}
+		}
+	}
+	return files
+}
+
+// declaredRepos returns the apparent names of the repositories declared in
+// the module files, i.e. the module itself, its bazel_dep's, the repositories
+// imported with use_repo and the ones created with use_repo_rule.
+func declaredRepos(files []*build.File) map[string]bool {
+	repos := make(map[string]bool)
+	for _, f := range files {
+		repoRules := make(map[string]bool)
+		for _, stmt := range f.Stmt {
+			if assign, ok := stmt.(*build.AssignExpr); ok {
+				if lhs, ok := assign.LHS.(*build.Ident); ok {
+					if call, ok := assign.RHS.(*build.CallExpr); ok {
+						if ident, ok := call.X.(*build.Ident); ok && ident.Name == "use_repo_rule" {
+							repoRules[lhs.Name] = true
+						}
+					}
+				}
+				continue
+			}
+			call, ok := stmt.(*build.CallExpr)
+			if !ok {
+				continue
+			}
+			ident, ok := call.X.(*build.Ident)
+			if !ok {
+				continue
+			}
+			rule := f.Rule(call)
+			switch {
+			case ident.Name == "module" || ident.Name == "bazel_dep":
+				if repoName := rule.AttrString("repo_name"); repoName != "" {
+					repos[repoName] = true
+				} else if name := rule.AttrString("name"); name != "" {
+					repos[name] = true
+				}
+			case ident.Name == "use_repo":
+				for _, arg := range call.List {
+					if name := useRepoArgName(arg); name != "" {
+						repos[name] = true
+					}
+				}
+			case repoRules[ident.Name]:
+				if name := rule.AttrString("name"); name != "" {
+					repos[name] = true
+				}
+			}
+		}
+	}
+	return repos
+}
+
+// useRepoArgName returns the apparent name of the repository imported by an
+// argument of a use_repo call, or an empty string if it's not a repository.
+func useRepoArgName(arg build.Expr) string {
+	switch arg := arg.(type) {
+	case *build.StringExpr:
+		// use_repo(ext, "repo")
+		return arg.Value
+	case *build.AssignExpr:
+		// use_repo(ext, my_repo = "repo")
+		if lhs, ok := arg.LHS.(*build.Ident); ok {
+			return lhs.Name
+		}
+	}
+	return ""
+}
+
+// referencedRepos adds the apparent names of the repositories referenced in
+// labels in a file to repos, mapped to the first file referencing them.
+func referencedRepos(f *build.File, repos map[string]string) {
+	build.Walk(f, func(expr build.Expr, stack []build.Expr) {
+		str, ok := expr.(*build.StringExpr)
+		if !ok {
+			return
+		}
+		repo, ok := labelRepo(str.Value)
+		if !ok {
+			return
+		}
+		if _, ok := repos[repo]; !ok {
+			repos[repo] = path.Join(f.Pkg, f.Label)
+		}
+	})
+}
+
+// labelRepo returns the apparent repository name of a label like "@repo//pkg:target".
+func labelRepo(label string) (string, bool) {
+	if !strings.HasPrefix(label, "@") || strings.HasPrefix(label, "@@") {
+		return "", false
+	}
+	i := strings.Index(label, "//")
+	if i < 0 || !isRepoName(label[1:i]) {
+		return "", false
+	}
+	return label[1:i], true
+}
+
+func isRepoName(name string) bool {
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("_-.~+", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+func useRepoWarning(f *build.File, fileReader *FileReader) []*LinterFinding {
+	if f.Type != build.TypeModule || f.Label != "MODULE.bazel" || fileReader == nil {
+		return nil
+	}
+	files := fileReader.ListFiles()
+	if files == nil {
+		return nil
+	}
+
+	modFiles := moduleFiles(f, fileReader)
+	referenced := make(map[string]string)
+	for _, modFile := range modFiles {
+		referencedRepos(modFile, referenced)
+	}
+	for _, name := range files {
+		if f.Pkg != "" && !strings.HasPrefix(name, f.Pkg+"/") {
+			continue
+		}
+		pkg, label := path.Split(name)
+		if file := fileReader.GetFile(strings.TrimSuffix(pkg, "/"), label); file != nil {
+			referencedRepos(file, referenced)
+		}
+	}
+
+	var findings []*LinterFinding
+	for stmtIndex, stmt := range f.Stmt {
+		call, ok := stmt.(*build.CallExpr)
+		if !ok || len(call.List) == 0 {
+			continue
+		}
+		if ident, ok := call.X.(*build.Ident); !ok || ident.Name != "use_repo" {
+			continue
+		}
+
+		// Copy the use_repo call to provide a replacement if needed
+		newCall := *call
+		newCall.List = []build.Expr{call.List[0]}
+		var useRepoFindings []*LinterFinding
+		for _, arg := range call.List[1:] {
+			name := useRepoArgName(arg)
+			if _, ok := referenced[name]; ok || name == "" {
+				newCall.List = append(newCall.List, arg)
+				continue
+			}
+			useRepoFindings = append(useRepoFindings, makeLinterFinding(arg,
+				fmt.Sprintf("Repository %q is imported with use_repo but isn't referenced in the BUILD and .bzl files of the module. Please remove it.", name)))
+		}
+		if len(useRepoFindings) == 0 {
+			continue
+		}
+
+		var newStmt build.Expr = &newCall
+		if len(newCall.List) == 1 {
+			// If there are no imported repositories left remove the entire use_repo call
+			newStmt = nil
+		}
+		replacement := LinterReplacement{&f.Stmt[stmtIndex], newStmt}
+		for _, finding := range useRepoFindings {
+			finding.Replacement = []LinterReplacement{replacement}
+		}
+		findings = append(findings, useRepoFindings...)
+	}
+
+	declared := declaredRepos(modFiles)
+	var missing []string
+	for repo := range referenced {
+		if !declared[repo] && !builtinRepos[repo] {
+			missing = append(missing, repo)
+		}
+	}
+	sort.Strings(missing)
+	for _, repo := range missing {
+		message := fmt.Sprintf("Repository \"@%s\" is referenced in %q but isn't imported with bazel_dep or use_repo.", repo, referenced[repo])
+		if len(f.Stmt) == 0 {
+			findings = append(findings, &LinterFinding{
+				Start:   build.Position{Line: 1, LineRune: 1},
+				End:     build.Position{Line: 1, LineRune: 1},
+				Message: message,
+			})
+			continue
+		}
+		findings = append(findings, makeLinterFinding(f.Stmt[0], message))
+	}
+	return findings
+}
+
+// isDevDependency reports whether a call has a dev_dependency argument that isn't False.
+func isDevDependency(call *build.CallExpr) bool {
+	_, _, param := getParam(call.List, "dev_dependency")
+	if param == nil {
+		return false
+	}
+	ident, ok := param.RHS.(*build.Ident)
+	// Assume that a more complex value evaluates to True
+	return !ok || ident.Name != "False"
+}
+
+// extensionProxies returns the extension proxies defined in a MODULE.bazel file, i.e. the names of
+// variables to which the result of a use_extension call is assigned, mapped to the calls.
+func extensionProxies(f *build.File) map[string]*build.CallExpr {
+	proxies := make(map[string]*build.CallExpr)
+	for _, stmt := range f.Stmt {
+		assign, ok := stmt.(*build.AssignExpr)
+		if !ok {
+			continue
+		}
+		lhs, ok := assign.LHS.(*build.Ident)
+		if !ok {
+			continue
+		}
+		if call, ok := isFunctionCall(assign.RHS, "use_extension"); ok {
+			proxies[lhs.Name] = call
+		}
+	}
+	return proxies
+}
+
+func duplicateBazelDepWarning(f *build.File) []*LinterFinding {
+	if f.Type != build.TypeModule {
+		return nil
+	}
+
+	var findings []*LinterFinding
+	deps := make(map[string]int) // map from module name to statement index
+	for i, stmt := range f.Stmt {
+		call, ok := isFunctionCall(stmt, "bazel_dep")
+		if !ok {
+			continue
+		}
+		name := f.Rule(call).AttrString("name")
+		if name == "" {
+			continue
+		}
+		first, ok := deps[name]
+		if !ok {
+			deps[name] = i
+			continue
+		}
+		start, _ := f.Stmt[first].Span()
+		finding := makeLinterFinding(call, fmt.Sprintf(
+			`A bazel_dep on module %q was already found on line %d. Bazel doesn't allow duplicate bazel_dep calls, please remove one of them.`,
+			name, start.Line))
+		if build.FormatString(call) == build.FormatString(f.Stmt[first]) {
+			// Identical calls can be removed safely
+			finding.Replacement = []LinterReplacement{{&f.Stmt[i], nil}}
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+func nonRootOverrideWarning(f *build.File, fileReader *FileReader) []*LinterFinding {
+	if f.Type != build.TypeModule || f.Label != "MODULE.bazel" || f.Pkg == "" || fileReader == nil {
+		return nil
+	}
+
+	// The module is used as a dependency if the root module overrides it with its path
+	root := fileReader.GetFile("", "MODULE.bazel")
+	if root == nil {
+		return nil
+	}
+	isDependency := false
+	for _, stmt := range root.Stmt {
+		if call, ok := isFunctionCall(stmt, "local_path_override"); ok {
+			if path.Clean(root.Rule(call).AttrString("path")) == path.Clean(f.Pkg) {
+				isDependency = true
+			}
+		}
+	}
+	if !isDependency {
+		return nil
+	}
+
+	var findings []*LinterFinding
+	for _, stmt := range f.Stmt {
+		call, ok := stmt.(*build.CallExpr)
+		if !ok {
+			continue
+		}
+		if ident, ok := call.X.(*build.Ident); ok && tables.IsModuleOverride[ident.Name] {
+			findings = append(findings, makeLinterFinding(call, fmt.Sprintf(
+				`The module in %q is a dependency of the root module, its %s is ignored. Overrides only take effect in the root module, please move it there.`,
+				f.Pkg, ident.Name)))
+		}
+	}
+	return findings
+}
+
+func unusedExtensionWarning(f *build.File) []*LinterFinding {
+	if f.Type != build.TypeModule {
+		return nil
+	}
+
+	proxies := extensionProxies(f)
+	if len(proxies) == 0 {
+		return nil
+	}
+	used := make(map[string]bool)
+	for _, stmt := range f.Stmt {
+		if assign, ok := stmt.(*build.AssignExpr); ok {
+			// Skip the assigned names
+			stmt = assign.RHS
+		}
+		build.Walk(stmt, func(expr build.Expr, stack []build.Expr) {
+			if ident, ok := expr.(*build.Ident); ok {
+				used[ident.Name] = true
+			}
+		})
+	}
+
+	var findings []*LinterFinding
+	for i, stmt := range f.Stmt {
+		assign, ok := stmt.(*build.AssignExpr)
+		if !ok {
+			continue
+		}
+		lhs, ok := assign.LHS.(*build.Ident)
+		if !ok || proxies[lhs.Name] == nil || used[lhs.Name] {
+			continue
+		}
+		findings = append(findings, makeLinterFinding(assign, fmt.Sprintf(
+			`Extension proxy %q is never used: it has no tags and no repositories are imported from it with use_repo. Please remove it.`, lhs.Name),
+			LinterReplacement{&f.Stmt[i], nil}))
+	}
+	return findings
+}
+
+// extensionUsage identifies the usage of a module extension created by a use_extension call: the
+// .bzl file and the name of the extension, as in "//:extensions.bzl%ext". Returns an empty string
+// for isolated usages, which are independent of the other usages of the extension, and for calls
+// whose arguments aren't string literals.
+func extensionUsage(f *build.File, call *build.CallExpr) string {
+	if _, _, isolate := getParam(call.List, "isolate"); isolate != nil {
+		if ident, ok := isolate.RHS.(*build.Ident); !ok || ident.Name != "False" {
+			return ""
+		}
+	}
+	rule := f.Rule(call)
+	bzlFile, name := rule.AttrString("extension_bzl_file"), rule.AttrString("extension_name")
+	for i, arg := range call.List {
+		str, ok := arg.(*build.StringExpr)
+		if !ok {
+			continue
+		}
+		switch i {
+		case 0:
+			bzlFile = str.Value
+		case 1:
+			name = str.Value
+		}
+	}
+	if bzlFile == "" || name == "" {
+		return ""
+	}
+	return bzlFile + "%" + name
+}
+
+// useRepoArgRepo returns the name of the repository created by the extension that is imported by
+// an argument of use_repo, or an empty string.
+func useRepoArgRepo(arg build.Expr) string {
+	switch arg := arg.(type) {
+	case *build.StringExpr:
+		// use_repo(ext, "repo")
+		return arg.Value
+	case *build.AssignExpr:
+		// use_repo(ext, my_repo = "repo")
+		if str, ok := arg.RHS.(*build.StringExpr); ok {
+			return str.Value
+		}
+	}
+	return ""
+}
+
+func devDependencyMismatchWarning(f *build.File) []*LinterFinding {
+	if f.Type != build.TypeModule {
+		return nil
+	}
+
+	proxies := extensionProxies(f)
+	usages := make(map[string]string) // map from proxy name to extension usage
+	for name, call := range proxies {
+		if usage := extensionUsage(f, call); usage != "" {
+			usages[name] = usage
+		}
+	}
+	if len(usages) == 0 {
+		return nil
+	}
+
+	// Repositories created by tags and imported with use_repo, indexed by extension usage
+	devTagRepos := make(map[string]map[string]string) // map from repository to dev proxy
+	regularTagRepos := make(map[string]map[string]bool)
+	regularImports := make(map[string]map[string]bool)
+	add := func(m map[string]map[string]bool, usage, repo string) {
+		if m[usage] == nil {
+			m[usage] = make(map[string]bool)
+		}
+		m[usage][repo] = true
+	}
+	var useRepos []*build.CallExpr
+	for _, stmt := range f.Stmt {
+		call, ok := stmt.(*build.CallExpr)
+		if !ok {
+			continue
+		}
+		if dot, ok := call.X.(*build.DotExpr); ok {
+			// A tag: proxy.tag(name = "repo", ...)
+			proxy, ok := dot.X.(*build.Ident)
+			if !ok || usages[proxy.Name] == "" {
+				continue
+			}
+			repo := f.Rule(call).AttrString("name")
+			if repo == "" {
+				continue
+			}
+			usage := usages[proxy.Name]
+			if !isDevDependency(proxies[proxy.Name]) {
+				add(regularTagRepos, usage, repo)
+			} else if _, ok := devTagRepos[usage][repo]; !ok {
+				if devTagRepos[usage] == nil {
+					devTagRepos[usage] = make(map[string]string)
+				}
+				devTagRepos[usage][repo] = proxy.Name
+			}
+			continue
+		}
+		if _, ok := isFunctionCall(call, "use_repo"); !ok || len(call.List) == 0 {
+			continue
+		}
+		proxy, ok := call.List[0].(*build.Ident)
+		if !ok || usages[proxy.Name] == "" {
+			continue
+		}
+		useRepos = append(useRepos, call)
+		if !isDevDependency(proxies[proxy.Name]) {
+			for _, arg := range call.List[1:] {
+				if repo := useRepoArgRepo(arg); repo != "" {
+					add(regularImports, usages[proxy.Name], repo)
+				}
+			}
+		}
+	}
+
+	var findings []*LinterFinding
+	for _, call := range useRepos {
+		proxy := call.List[0].(*build.Ident).Name
+		usage := usages[proxy]
+		dev := isDevDependency(proxies[proxy])
+		for _, arg := range call.List[1:] {
+			repo := useRepoArgRepo(arg)
+			if repo == "" {
+				continue
+			}
+			// The repository only exists in the root module if it's only created by dev tags
+			devProxy, devOnly := devTagRepos[usage][repo]
+			devOnly = devOnly && !regularTagRepos[usage][repo]
+			if dev && !devOnly && regularImports[usage][repo] {
+				findings = append(findings, makeLinterFinding(arg, fmt.Sprintf(
+					`Repository %q of the extension %s is imported both as a regular and as a dev dependency. `+
+						`The regular import is enough, please remove it from the use_repo call of the dev proxy %q.`, repo, usage, proxy)))
+			} else if !dev && devOnly {
+				findings = append(findings, makeLinterFinding(arg, fmt.Sprintf(
+					`Repository %q is created by a tag of the dev proxy %q, but it's imported on the regular proxy %q. `+
+						`The repository doesn't exist when the module isn't the root module, please import it on %q.`, repo, devProxy, proxy, devProxy)))
+			}
+		}
+	}
+	return findings
+}
+
+func devDependencyRegistrationWarning(f *build.File) []*LinterFinding {
+	if f.Type != build.TypeModule {
+		return nil
+	}
+
+	proxies := extensionProxies(f)
+	devRepos := make(map[string]bool)
+	regularRepos := make(map[string]bool)
+	addRepo := func(repo string, dev bool) {
+		if dev {
+			devRepos[repo] = true
+		} else {
+			regularRepos[repo] = true
+		}
+	}
+	for _, stmt := range f.Stmt {
+		call, ok := stmt.(*build.CallExpr)
+		if !ok {
+			continue
+		}
+		ident, ok := call.X.(*build.Ident)
+		if !ok {
+			continue
+		}
+		rule := f.Rule(call)
+		switch ident.Name {
+		case "module":
+			if repoName := rule.AttrString("repo_name"); repoName != "" {
+				addRepo(repoName, false)
+			} else if name := rule.AttrString("name"); name != "" {
+				addRepo(name, false)
+			}
+		case "bazel_dep":
+			if repoName := rule.AttrString("repo_name"); repoName != "" {
+				addRepo(repoName, isDevDependency(call))
+			} else if name := rule.AttrString("name"); name != "" {
+				addRepo(name, isDevDependency(call))
+			}
+		case "use_repo":
+			if len(call.List) == 0 {
+				continue
+			}
+			proxy, ok := call.List[0].(*build.Ident)
+			if !ok || proxies[proxy.Name] == nil {
+				continue
+			}
+			for _, arg := range call.List[1:] {
+				if name := useRepoArgName(arg); name != "" {
+					addRepo(name, isDevDependency(proxies[proxy.Name]))
+				}
+			}
+		}
+	}
+
+	var findings []*LinterFinding
+	for _, stmt := range f.Stmt {
+		call, ok := stmt.(*build.CallExpr)
+		if !ok || isDevDependency(call) {
+			continue
+		}
+		ident, ok := call.X.(*build.Ident)
+		if !ok || (ident.Name != "register_toolchains" && ident.Name != "register_execution_platforms") {
+			continue
+		}
+		for _, arg := range call.List {
+			str, ok := arg.(*build.StringExpr)
+			if !ok {
+				continue
+			}
+			if repo, ok := labelRepo(str.Value); ok && devRepos[repo] && !regularRepos[repo] {
+				findings = append(findings, makeLinterFinding(str, fmt.Sprintf(
+					`Repository "@%s" is only imported as a dev dependency, but it's used in %s without "dev_dependency = True". `+
+						`The registration will fail when the module isn't the root module.`, repo, ident.Name)))
+			}
+		}
+	}
+	return findings
+}
+
+// bazelDepBlocks returns the blocks of consecutive bazel_dep calls with the same value of the
+// dev_dependency attribute that aren't separated by comments, as lists of statement indices.
+// A bazel_dep followed by an override isn't part of any block, as it can't be moved.
+func bazelDepBlocks(f *build.File) [][]int {
+	var blocks [][]int
+	var block []int
+	for i, stmt := range f.Stmt {
+		call, ok := isFunctionCall(stmt, "bazel_dep")
+		if !ok || i+1 < len(f.Stmt) && isModuleOverrideCall(f.Stmt[i+1]) {
+			if len(block) > 1 {
+				blocks = append(blocks, block)
+			}
+			block = nil
+			continue
+		}
+		if len(block) > 0 {
+			prev := f.Stmt[block[len(block)-1]].(*build.CallExpr)
+			_, prevEnd := prev.Span()
+			start, _ := call.Span()
+			if isDevDependency(prev) != isDevDependency(call) || len(call.Comments.Before) > 0 || len(prev.Comments.After) > 0 || start.Line-prevEnd.Line > 1 {
+				if len(block) > 1 {
+					blocks = append(blocks, block)
+				}
+				block = nil
+			}
+		}
+		block = append(block, i)
+	}
+	if len(block) > 1 {
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func isModuleOverrideCall(stmt build.Expr) bool {
+	call, ok := stmt.(*build.CallExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := call.X.(*build.Ident)
+	return ok && tables.IsModuleOverride[ident.Name]
+}
+
+func unsortedBazelDepsWarning(f *build.File) []*LinterFinding {
+	if f.Type != build.TypeModule {
+		return nil
+	}
+
+	var findings []*LinterFinding
+	for _, block := range bazelDepBlocks(f) {
+		deps := make([]*build.CallExpr, len(block))
+		for i, index := range block {
+			deps[i] = f.Stmt[index].(*build.CallExpr)
+		}
+		sorted := append([]*build.CallExpr{}, deps...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return f.Rule(sorted[i]).AttrString("name") < f.Rule(sorted[j]).AttrString("name")
+		})
+
+		unsorted := -1
+		for i := range deps {
+			if deps[i] != sorted[i] {
+				unsorted = i
+				break
+			}
+		}
+		if unsorted == -1 {
+			continue
+		}
+
+		// The comments before the block stay in place
+		if sorted[0] != deps[0] {
+			first, moved := *sorted[0], *deps[0]
+			first.Comments.Before, moved.Comments.Before = deps[0].Comments.Before, nil
+			for i := range sorted {
+				if sorted[i] == deps[0] {
+					sorted[i] = &moved
+				}
+			}
+			sorted[0] = &first
+		}
+		var replacements []LinterReplacement
+		for i, index := range block {
+			replacements = append(replacements, LinterReplacement{&f.Stmt[index], sorted[i]})
+		}
+		findings = append(findings, makeLinterFinding(deps[unsorted], fmt.Sprintf(
+			`The bazel_dep calls are not sorted by module name, %q should go before %q.`,
+			f.Rule(sorted[unsorted]).AttrString("name"), f.Rule(deps[unsorted]).AttrString("name")),
+			replacements...))
+	}
+	return findings
+}
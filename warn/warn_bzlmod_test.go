@@ -0,0 +1,345 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warn
+
+import (
+	"sort"
+	"testing"
+)
+
+// setUpModuleFiles sets up testFileReader with the given files, which are also
+// listed as the BUILD and .bzl files of the repository.
+func setUpModuleFiles(files map[string]string) (cleanup func()) {
+	cleanup = setUpFileReader(files)
+	testFileReader.SetFileLister(func() ([]string, error) {
+		var names []string
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, nil
+	})
+	return cleanup
+}
+
+func TestUseRepo(t *testing.T) {
+	defer setUpModuleFiles(map[string]string{
+		"test/package/BUILD": `
+cc_library(
+    name = "lib",
+    deps = [
+        "@abseil-cpp//absl/strings",
+        "@@canonical~repo//:lib",
+        "@bazel_tools//tools/cpp:toolchain",
+        "@//other:lib",
+        "@mylib//:lib",
+    ],
+)
+`,
+		"test/package/defs.bzl": `
+load("@rules_go//go:def.bzl", "go_library")
+
+DEPS = ["@maven//:junit_junit", "@missing//:lib"]
+`,
+		"test/package/third_party/BUILD": `
+alias(name = "guava", actual = "@maven//:com_google_guava_guava")
+`,
+		"test/package/extra.MODULE.bazel": `
+http_archive = use_repo_rule("@bazel_tools//tools/build_defs/repo:http.bzl", "http_archive")
+http_archive(name = "mylib")
+`,
+		"other/BUILD": `
+cc_library(name = "lib", deps = ["@outside//:lib"])
+`,
+	})()
+
+	checkFindingsAndFix(t, "use-repo", `
+module(name = "foo")
+
+include("//:extra.MODULE.bazel")
+
+bazel_dep(name = "abseil-cpp", version = "20240116.0")
+bazel_dep(name = "rules_go", version = "0.41.0", repo_name = "io_bazel_rules_go")
+bazel_dep(name = "rules_jvm_external", version = "6.0")
+
+maven = use_extension("@rules_jvm_external//:extensions.bzl", "maven")
+use_repo(maven, "maven", "unused_maven")
+
+toolchains = use_extension("//:extensions.bzl", "toolchains")
+use_repo(toolchains, "toolchain_repo", my_alias = "aliased_repo")
+
+register_toolchains("@toolchain_repo//:all")
+`, `
+module(name = "foo")
+
+include("//:extra.MODULE.bazel")
+
+bazel_dep(name = "abseil-cpp", version = "20240116.0")
+bazel_dep(name = "rules_go", version = "0.41.0", repo_name = "io_bazel_rules_go")
+bazel_dep(name = "rules_jvm_external", version = "6.0")
+
+maven = use_extension("@rules_jvm_external//:extensions.bzl", "maven")
+use_repo(maven, "maven")
+
+toolchains = use_extension("//:extensions.bzl", "toolchains")
+use_repo(toolchains, "toolchain_repo")
+
+register_toolchains("@toolchain_repo//:all")
+`, []string{
+		`:1: Repository "@missing" is referenced in "test/package/defs.bzl" but isn't imported with bazel_dep or use_repo.`,
+		`:1: Repository "@rules_go" is referenced in "test/package/defs.bzl" but isn't imported with bazel_dep or use_repo.`,
+		`:10: Repository "unused_maven" is imported with use_repo but isn't referenced in the BUILD and .bzl files of the module.`,
+		`:13: Repository "my_alias" is imported with use_repo but isn't referenced in the BUILD and .bzl files of the module.`,
+	}, scopeModule)
+}
+
+func TestUseRepoRemovesCall(t *testing.T) {
+	defer setUpModuleFiles(map[string]string{
+		"test/package/BUILD": `
+cc_library(name = "lib")
+`,
+	})()
+
+	checkFindingsAndFix(t, "use-repo", `
+ext = use_extension("//:extensions.bzl", "ext")
+use_repo(ext, "foo", "bar")
+`, `
+ext = use_extension("//:extensions.bzl", "ext")
+`, []string{
+		`:2: Repository "foo" is imported with use_repo but isn't referenced`,
+		`:2: Repository "bar" is imported with use_repo but isn't referenced`,
+	}, scopeModule)
+}
+
+func TestUseRepoWithoutFileLister(t *testing.T) {
+	defer setUpFileReader(map[string]string{
+		"test/package/BUILD": `
+cc_library(name = "lib", deps = ["@missing//:lib"])
+`,
+	})()
+
+	checkFindings(t, "use-repo", `
+ext = use_extension("//:extensions.bzl", "ext")
+use_repo(ext, "foo")
+`, []string{}, scopeModule)
+}
+
+func TestDuplicateBazelDep(t *testing.T) {
+	checkFindingsAndFix(t, "duplicate-bazel-dep", `
+bazel_dep(name = "rules_go", version = "0.41.0")
+bazel_dep(name = "rules_cc", version = "0.0.9")
+bazel_dep(name = "rules_go", version = "0.41.0")
+
+bazel_dep(name = "rules_cc", version = "0.0.10", dev_dependency = True)
+`, `
+bazel_dep(name = "rules_go", version = "0.41.0")
+bazel_dep(name = "rules_cc", version = "0.0.9")
+
+bazel_dep(name = "rules_cc", version = "0.0.10", dev_dependency = True)
+`, []string{
+		`:3: A bazel_dep on module "rules_go" was already found on line 1.`,
+		`:5: A bazel_dep on module "rules_cc" was already found on line 2.`,
+	}, scopeModule)
+}
+
+func TestNonRootOverride(t *testing.T) {
+	defer setUpFileReader(map[string]string{
+		"MODULE.bazel": `
+module(name = "root")
+
+bazel_dep(name = "foo")
+local_path_override(
+    module_name = "foo",
+    path = "./test/package/",
+)
+`,
+	})()
+
+	checkFindings(t, "non-root-override", `
+module(name = "foo")
+
+bazel_dep(name = "bar", version = "1.0")
+single_version_override(
+    module_name = "bar",
+    version = "1.1",
+)
+
+git_override(
+    module_name = "baz",
+    commit = "abcdef",
+    remote = "https://example.com/baz.git",
+)
+`, []string{
+		`:4: The module in "test/package" is a dependency of the root module, its single_version_override is ignored.`,
+		`:9: The module in "test/package" is a dependency of the root module, its git_override is ignored.`,
+	}, scopeModule)
+}
+
+func TestNonRootOverrideInRootModule(t *testing.T) {
+	defer setUpFileReader(map[string]string{
+		"MODULE.bazel": `
+module(name = "root")
+
+bazel_dep(name = "foo")
+local_path_override(
+    module_name = "foo",
+    path = "foo",
+)
+`,
+	})()
+
+	checkFindings(t, "non-root-override", `
+single_version_override(
+    module_name = "bar",
+    version = "1.1",
+)
+`, []string{}, scopeModule)
+}
+
+func TestUnusedExtension(t *testing.T) {
+	checkFindingsAndFix(t, "unused-extension", `
+go_sdk = use_extension("@rules_go//go:extensions.bzl", "go_sdk")
+go_sdk.download(version = "1.21.0")
+
+maven = use_extension("@rules_jvm_external//:extensions.bzl", "maven")
+use_repo(maven, "maven")
+
+pip = use_extension("@rules_python//python/extensions:pip.bzl", "pip")
+
+non_module_deps = use_extension("//:extensions.bzl", "non_module_deps")
+override_repo(non_module_deps, foo = "bar")
+
+unused = use_extension("//:extensions.bzl", "unused", dev_dependency = True)
+`, `
+go_sdk = use_extension("@rules_go//go:extensions.bzl", "go_sdk")
+go_sdk.download(version = "1.21.0")
+
+maven = use_extension("@rules_jvm_external//:extensions.bzl", "maven")
+use_repo(maven, "maven")
+
+non_module_deps = use_extension("//:extensions.bzl", "non_module_deps")
+override_repo(non_module_deps, foo = "bar")
+`, []string{
+		`:7: Extension proxy "pip" is never used`,
+		`:12: Extension proxy "unused" is never used`,
+	}, scopeModule)
+}
+
+func TestDevDependencyMismatch(t *testing.T) {
+	checkFindings(t, "dev-dependency-mismatch", `
+module(name = "foo", version = "1.0")
+
+toolchains = use_extension("//:extensions.bzl", "toolchains")
+toolchains.toolchain(name = "toolchain_repo")
+use_repo(toolchains, "toolchain_repo", "test_repo", "shared_repo", my_dev_repo = "dev_repo")
+
+dev_toolchains = use_extension("//:extensions.bzl", "toolchains", dev_dependency = True)
+dev_toolchains.toolchain(name = "test_repo")
+dev_toolchains.toolchain(name = "dev_repo")
+dev_toolchains.toolchain(name = "shared_repo")
+use_repo(dev_toolchains, "toolchain_repo", "test_repo")
+
+toolchains.toolchain(name = "shared_repo")
+
+isolated = use_extension("//:extensions.bzl", "toolchains", dev_dependency = True, isolate = True)
+isolated.toolchain(name = "isolated_repo")
+use_repo(isolated, "toolchain_repo", "isolated_repo")
+
+other = use_extension("//:other.bzl", "toolchains", dev_dependency = True)
+other.toolchain(name = "other_repo")
+use_repo(other, "toolchain_repo", "other_repo")
+
+use_repo(toolchains, "isolated_repo", "other_repo")
+`, []string{
+		`:5: Repository "test_repo" is created by a tag of the dev proxy "dev_toolchains", but it's imported on the regular proxy "toolchains".`,
+		`:5: Repository "dev_repo" is created by a tag of the dev proxy "dev_toolchains", but it's imported on the regular proxy "toolchains".`,
+		`:11: Repository "toolchain_repo" of the extension //:extensions.bzl%toolchains is imported both as a regular and as a dev dependency.`,
+	}, scopeModule)
+}
+
+func TestDevDependencyRegistration(t *testing.T) {
+	checkFindings(t, "dev-dependency-registration", `
+module(name = "foo", version = "1.0")
+
+bazel_dep(name = "rules_go", version = "0.41.0")
+
+bazel_dep(name = "rules_testing", version = "0.5.0", dev_dependency = True)
+
+toolchains = use_extension("//:extensions.bzl", "toolchains")
+use_repo(toolchains, "toolchain_repo")
+
+dev_toolchains = use_extension("//:extensions.bzl", "toolchains", dev_dependency = True)
+use_repo(dev_toolchains, "dev_toolchain_repo", "toolchain_repo")
+
+register_toolchains(
+    "@toolchain_repo//:all",
+    "@dev_toolchain_repo//:all",
+    "@rules_go//go:all",
+    "//:all",
+)
+
+register_toolchains("@dev_toolchain_repo//:all", dev_dependency = True)
+
+register_execution_platforms("@rules_testing//platforms:all")
+`, []string{
+		`:15: Repository "@dev_toolchain_repo" is only imported as a dev dependency, but it's used in register_toolchains without "dev_dependency = True".`,
+		`:22: Repository "@rules_testing" is only imported as a dev dependency, but it's used in register_execution_platforms without "dev_dependency = True".`,
+	}, scopeModule)
+}
+
+func TestUnsortedBazelDeps(t *testing.T) {
+	checkFindingsAndFix(t, "unsorted-bazel-deps", `
+module(name = "foo")
+
+# Regular dependencies
+bazel_dep(name = "rules_go", version = "0.41.0")
+bazel_dep(name = "bazel_skylib", version = "1.4.2")  # skylib
+bazel_dep(name = "rules_cc", version = "0.0.9")
+
+bazel_dep(name = "rules_testing", version = "0.5.0", dev_dependency = True)
+bazel_dep(name = "rules_pkg", version = "0.9.1", dev_dependency = True)
+bazel_dep(name = "abseil-cpp", version = "20240116.0", dev_dependency = True)
+single_version_override(
+    module_name = "abseil-cpp",
+    patches = ["//:absl.patch"],
+)
+
+bazel_dep(name = "platforms", version = "0.0.8")
+bazel_dep(name = "protobuf", version = "21.7")
+`, `
+module(name = "foo")
+
+# Regular dependencies
+bazel_dep(name = "bazel_skylib", version = "1.4.2")  # skylib
+bazel_dep(name = "rules_cc", version = "0.0.9")
+bazel_dep(name = "rules_go", version = "0.41.0")
+
+bazel_dep(name = "rules_pkg", version = "0.9.1", dev_dependency = True)
+bazel_dep(name = "rules_testing", version = "0.5.0", dev_dependency = True)
+bazel_dep(name = "abseil-cpp", version = "20240116.0", dev_dependency = True)
+single_version_override(
+    module_name = "abseil-cpp",
+    patches = ["//:absl.patch"],
+)
+
+bazel_dep(name = "platforms", version = "0.0.8")
+bazel_dep(name = "protobuf", version = "21.7")
+`, []string{
+		`:4: The bazel_dep calls are not sorted by module name, "bazel_skylib" should go before "rules_go".`,
+		`:8: The bazel_dep calls are not sorted by module name, "rules_pkg" should go before "rules_testing".`,
+	}, scopeModule)
+}
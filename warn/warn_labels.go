@@ -0,0 +1,198 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warn
+
+// Warnings about labels that don't refer to existing targets
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/labels"
+)
+
+// packageTargets are the targets of a package that can be resolved statically.
+type packageTargets struct {
+	// names of the rules, their output files and the exported files
+	names map[string]bool
+	// names of the macros loaded from .bzl files, which can declare targets
+	// whose names start with the name of the macro
+	macros map[string]bool
+	// complete is false if the package may declare targets whose names can't
+	// be known without evaluating the BUILD file.
+	complete bool
+}
+
+// newPackageTargets collects the targets declared on the top level of a BUILD file.
+func newPackageTargets(f *build.File) *packageTargets {
+	t := &packageTargets{names: make(map[string]bool), macros: make(map[string]bool), complete: true}
+	loaded := make(map[string]bool)
+	for _, stmt := range f.Stmt {
+		switch stmt := stmt.(type) {
+		case *build.ForStmt, *build.IfStmt:
+			// Rules can be declared in loops or conditionally
+			t.complete = false
+		case *build.LoadStmt:
+			for _, to := range stmt.To {
+				loaded[to.Name] = true
+			}
+		}
+	}
+
+	for _, r := range f.Rules("") {
+		switch r.Kind() {
+		case "package", "licenses":
+			continue
+		case "exports_files":
+			srcs := r.Attr("srcs")
+			if srcs == nil && len(r.Call.List) > 0 {
+				srcs = r.Call.List[0]
+			}
+			for _, str := range labelStrings(srcs) {
+				t.names[str.Value] = true
+			}
+			continue
+		}
+
+		name := r.ExplicitName()
+		if name == "" {
+			// Either a macro without a name or a name that's not a string literal
+			t.complete = false
+			continue
+		}
+		t.names[name] = true
+		if loaded[r.Kind()] {
+			t.macros[name] = true
+		}
+		for _, output := range implicitOutputs[r.Kind()] {
+			t.names[fmt.Sprintf(output, name)] = true
+		}
+		for _, attr := range []string{"out", "outs"} {
+			for _, str := range labelStrings(r.Attr(attr)) {
+				t.names[str.Value] = true
+			}
+		}
+		for _, arg := range r.Call.List {
+			if unary, ok := arg.(*build.UnaryExpr); ok && unary.Op == "**" {
+				// The outputs can be passed as keyword arguments
+				t.complete = false
+			}
+		}
+	}
+	return t
+}
+
+// implicitOutputs are the implicit output targets of the native rules by rule
+// kind, "%s" is replaced with the name of the rule.
+var implicitOutputs = map[string][]string{
+	"android_binary":  {"%s.apk", "%s_unsigned.apk", "%s_deploy.jar", "%s_proguard.jar", "%s_proguard.map"},
+	"android_library": {"lib%s.jar", "lib%s-src.jar", "%s.aar"},
+	"cc_binary":       {"%s.stripped", "%s.dwp"},
+	"cc_library":      {"lib%s.a", "lib%s.lo", "lib%s.pic.a", "lib%s.pic.lo", "lib%s.so", "lib%s.dylib", "%s.dll"},
+	"cc_test":         {"%s.stripped", "%s.dwp"},
+	"java_binary":     {"%s.jar", "%s-src.jar", "%s_deploy.jar", "%s_deploy-src.jar"},
+	"java_library":    {"lib%s.jar", "lib%s-src.jar"},
+	"java_test":       {"%s.jar", "%s-src.jar", "%s_deploy.jar", "%s_deploy-src.jar"},
+	"proto_library":   {"%s-descriptor-set.proto.bin"},
+	"py_binary":       {"%s.zip"},
+	"py_test":         {"%s.zip"},
+}
+
+// contains checks whether the package has a target with the given name or may have it.
+// A macro can declare targets whose names start with its name followed by "_", "-" or
+// ".", like the symbolic macros of Bazel.
+func (t *packageTargets) contains(name string) bool {
+	if !t.complete || t.names[name] {
+		return true
+	}
+	for i, c := range name {
+		if strings.ContainsRune("_-.", c) && t.macros[name[:i]] {
+			return true
+		}
+	}
+	return false
+}
+
+func danglingLabelWarning(f *build.File, fileReader *FileReader) []*LinterFinding {
+	var findings []*LinterFinding
+	if f.Type != build.TypeBuild || f.WorkspaceRoot == "" || fileReader == nil {
+		// The location of the file relative to the workspace root is needed
+		// to resolve the labels.
+		return findings
+	}
+
+	targets := map[string]*packageTargets{f.Pkg: newPackageTargets(f)}
+	getTargets := func(pkg string) *packageTargets {
+		if t, ok := targets[pkg]; ok {
+			return t
+		}
+		var t *packageTargets
+		if buildFile := readBuildFile(fileReader, pkg); buildFile != nil {
+			t = newPackageTargets(buildFile)
+		}
+		targets[pkg] = t
+		return t
+	}
+
+	for _, r := range f.Rules("") {
+		for _, attr := range r.Call.List {
+			as, ok := attr.(*build.AssignExpr)
+			if !ok {
+				continue
+			}
+			key, ok := as.LHS.(*build.Ident)
+			if !ok || !labelListAttrs[key.Name] {
+				continue
+			}
+			for _, str := range labelStrings(as.RHS) {
+				label := labels.ParseRelative(str.Value, f.Pkg)
+				if label.Repository != "" || label.Target == "" || strings.ContainsAny(str.Value, "$*") {
+					// External repositories aren't resolved
+					continue
+				}
+
+				t := getTargets(label.Package)
+				if t == nil {
+					if !hasBuildFile(fileReader, label.Package) {
+						findings = append(findings, makeLinterFinding(str,
+							fmt.Sprintf("Label %q refers to the package %q that doesn't exist.", str.Value, "//"+label.Package)))
+					}
+					// Otherwise the BUILD file can't be parsed
+					continue
+				}
+				if t.contains(label.Target) || fileReader.PathExists(path.Join(label.Package, label.Target)) {
+					continue
+				}
+				findings = append(findings, makeLinterFinding(str,
+					fmt.Sprintf("Label %q doesn't refer to an existing target or file.", str.Value)))
+			}
+		}
+	}
+	return findings
+}
+
+// hasBuildFile checks whether a directory in the workspace contains a BUILD file.
+func hasBuildFile(fileReader *FileReader, pkg string) bool {
+	for _, name := range buildFileNames {
+		if fileReader.PathExists(path.Join(pkg, name)) {
+			return true
+		}
+	}
+	return false
+}
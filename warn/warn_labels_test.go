@@ -0,0 +1,162 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warn
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bazelbuild/buildtools/build"
+)
+
+// checkDanglingLabels sets up a file reader for a workspace with the given files
+// and checks the findings of the dangling-label warning for a BUILD file in
+// testPackage.
+func checkDanglingLabels(t *testing.T, files map[string]string, input string, want []string) {
+	t.Helper()
+	defer setUpFileReader(files)()
+	testFileReader.SetPathChecker(func(path string) bool {
+		for name := range files {
+			if name == path || strings.HasPrefix(name, path+"/") {
+				return true
+			}
+		}
+		return false
+	})
+
+	f := getFileForTest(input, build.TypeBuild)
+	f.WorkspaceRoot = "/workspace"
+	var got []string
+	for _, finding := range FileWarnings(f, []string{"dangling-label"}, nil, ModeWarn, testFileReader) {
+		got = append(got, fmt.Sprintf("%d.%d: %s", finding.Start.Line, finding.Start.LineRune, finding.Message))
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestDanglingLabel(t *testing.T) {
+	checkDanglingLabels(t, map[string]string{
+		"lib/BUILD": `
+cc_library(name = "lib")
+
+java_binary(name = "app")
+
+genrule(
+    name = "gen",
+    outs = ["gen.h"],
+)
+
+exports_files(["exported.txt"])
+`,
+		"lib/file.txt":          "",
+		"lib/testdata/data.txt": "",
+		"broken/BUILD":          "cc_library(",
+		"test/package/foo.cc":   "",
+	}, `
+cc_library(
+    name = "a",
+    srcs = [
+        "foo.cc",
+        "missing.cc",
+        ":b",
+        ":c",
+    ],
+    hdrs = ["//lib:gen.h"],
+    data = [
+        "//lib:file.txt",
+        "//lib:exported.txt",
+        "//lib:testdata",
+        "//lib:missing.txt",
+    ],
+    deps = [
+        "//lib",
+        "//lib:lib",
+        "//lib:liblib.so",
+        "//lib:app_deploy.jar",
+        "//lib:lib_deploy.jar",
+        "//lib:lib_old",
+        "//lib:other",
+        "//missing:lib",
+        "//broken:lib",
+        "@ext//:lib",
+    ] + select({
+        "//conditions:default": ["//lib:selected"],
+    }),
+)
+
+cc_library(name = "b")
+`, []string{
+		"5.9: Label \"missing.cc\" doesn't refer to an existing target or file.",
+		"7.9: Label \":c\" doesn't refer to an existing target or file.",
+		"14.9: Label \"//lib:missing.txt\" doesn't refer to an existing target or file.",
+		"21.9: Label \"//lib:lib_deploy.jar\" doesn't refer to an existing target or file.",
+		"22.9: Label \"//lib:lib_old\" doesn't refer to an existing target or file.",
+		"23.9: Label \"//lib:other\" doesn't refer to an existing target or file.",
+		"24.9: Label \"//missing:lib\" refers to the package \"//missing\" that doesn't exist.",
+		"28.34: Label \"//lib:selected\" doesn't refer to an existing target or file.",
+	})
+}
+
+func TestDanglingLabelUnresolvedPackage(t *testing.T) {
+	checkDanglingLabels(t, map[string]string{
+		"macros/BUILD.bazel": `
+load(":defs.bzl", "generate")
+
+generate()
+`,
+		"loops/BUILD": `
+[cc_library(name = name) for name in ["a", "b"]]
+`,
+		"lib/BUILD": `
+cc_library(name = "lib")
+`,
+		"named/BUILD": `
+NAME = "lib"
+
+cc_library(name = NAME)
+`,
+		"loaded/BUILD": `
+load(":defs.bzl", "my_macro")
+
+my_macro(name = "gen")
+
+cc_library(name = "lib")
+`,
+	}, `
+cc_library(
+    name = "a",
+    deps = [
+        "//macros:lib",
+        "//named:lib",
+        "//loops:a",
+        "//loops:other",
+        "//lib:other",
+        "//loaded:gen",
+        "//loaded:gen_srcs",
+        "//loaded:gen.h",
+        "//loaded:general",
+        "//loaded:lib_srcs",
+    ],
+)
+`, []string{
+		"8.9: Label \"//lib:other\" doesn't refer to an existing target or file.",
+		"12.9: Label \"//loaded:general\" doesn't refer to an existing target or file.",
+		"13.9: Label \"//loaded:lib_srcs\" doesn't refer to an existing target or file.",
+	})
+}
@@ -476,3 +476,75 @@ for y in "foobar":
 		}
 	}
 }
+
+func TestApplyReplacements(t *testing.T) {
+	contents := []byte("abcdefgh")
+	finding := func(start, end int, content string) *Finding {
+		return &Finding{Replacement: &Replacement{Start: start, End: end, Content: content}}
+	}
+
+	findings := []*Finding{
+		finding(6, 7, "G"),
+		finding(1, 3, "BC"),
+		finding(2, 4, "xx"), // overlaps with the second one
+		finding(0, 0, "_"),
+		finding(0, 0, "-"), // inserts at the same position as the previous one
+		finding(8, 8, "!"),
+		{}, // no fix
+	}
+	result, conflicts := ApplyReplacements(contents, findings)
+	if want := "_aBCdefGh!"; string(result) != want {
+		t.Errorf("ApplyReplacements() = %q, want %q", result, want)
+	}
+
+	wantConflicts := []FixConflict{
+		{Applied: findings[1], Skipped: findings[2]},
+		{Applied: findings[3], Skipped: findings[4]},
+	}
+	if len(conflicts) != len(wantConflicts) {
+		t.Fatalf("got %d conflicts, want %d", len(conflicts), len(wantConflicts))
+	}
+	for i, c := range conflicts {
+		if c != wantConflicts[i] {
+			t.Errorf("conflict #%d: got %+v, want %+v", i, c, wantConflicts[i])
+		}
+	}
+}
+
+func TestFixWarningsIndependently(t *testing.T) {
+	contents := `cc_library(name = "a")
+
+cc_binary(
+    name = "b",
+    srcs = ["b.cc"],
+    copts = ["-DN=%d" % (5 / 2)],
+)
+`
+	f, err := build.ParseBuild("BUILD", []byte(contents))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	result, conflicts := FixWarningsIndependently(f, []string{"integer-division", "native-cc-binary", "native-cc-library"}, testFileReader)
+	want := `load("@rules_cc//cc:cc_library.bzl", "cc_library")
+
+cc_library(name = "a")
+
+cc_binary(
+    name = "b",
+    srcs = ["b.cc"],
+    copts = ["-DN=%d" % (5 // 2)],
+)
+`
+	if string(result) != want {
+		t.Errorf("FixWarningsIndependently() = %q, want %q", result, want)
+	}
+	if len(conflicts) != 1 || conflicts[0].Applied.Category != "native-cc-library" || conflicts[0].Skipped.Category != "native-cc-binary" {
+		t.Errorf("unexpected conflicts: %+v", conflicts)
+	}
+
+	// The file itself shouldn't be modified
+	if got := string(build.Format(f)); got != contents {
+		t.Errorf("the file was modified: %q", got)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Warnings about type annotations
+
+package warn
+
+import (
+	"fmt"
+
+	"github.com/bazelbuild/buildtools/build"
+)
+
+// typeMismatch returns the annotated and the detected types of a value as strings if they
+// don't match. None values aren't reported because optional values are often annotated
+// without `| None`.
+func typeMismatch(annotation, value build.Expr, types map[build.Expr]Type) (string, string, bool) {
+	annotated := annotationType(annotation)
+	detected, ok := types[value]
+	if annotated == Unknown || !ok || detected == Unknown || detected == None {
+		return "", "", false
+	}
+	if annotated == detected || (annotated == Float && detected == Int) {
+		return "", "", false
+	}
+	return build.FormatString(annotation), detected.String(), true
+}
+
+func typeAnnotationMismatchWarning(f *build.File) []*LinterFinding {
+	var findings []*LinterFinding
+	types := DetectTypes(f)
+
+	defs := make(map[string]*build.DefStmt)
+	for _, stmt := range f.Stmt {
+		if def, ok := stmt.(*build.DefStmt); ok {
+			defs[def.Name] = def
+		}
+	}
+
+	build.WalkStatements(f, func(expr build.Expr, stack []build.Expr) (err error) {
+		// Returned values
+		def, ok := expr.(*build.DefStmt)
+		if !ok || def.Type == nil {
+			return
+		}
+		build.Walk(def, func(expr build.Expr, stack []build.Expr) {
+			ret, ok := expr.(*build.ReturnStmt)
+			if !ok || ret.Result == nil {
+				return
+			}
+			if annotated, detected, ok := typeMismatch(def.Type, ret.Result, types); ok {
+				findings = append(findings, makeLinterFinding(ret.Result,
+					fmt.Sprintf("Function %q is annotated to return %s but returns a %s.", def.Name, annotated, detected)))
+			}
+		})
+		return
+	})
+
+	build.Walk(f, func(expr build.Expr, stack []build.Expr) {
+		switch expr := expr.(type) {
+		case *build.AssignExpr:
+			// Annotated variables, e.g. `x: list = depset()`, or parameters with default values
+			typed, ok := expr.LHS.(*build.TypedIdent)
+			if !ok {
+				return
+			}
+			annotated, detected, ok := typeMismatch(typed.Type, expr.RHS, types)
+			if !ok {
+				return
+			}
+			message := fmt.Sprintf("Variable %q is annotated as %s but is assigned a %s.", typed.Ident.Name, annotated, detected)
+			if len(stack) > 0 && isParam(stack[len(stack)-1], expr) {
+				message = fmt.Sprintf("Parameter %q is annotated as %s but its default value is a %s.", typed.Ident.Name, annotated, detected)
+			}
+			findings = append(findings, makeLinterFinding(expr.RHS, message))
+		case *build.CallExpr:
+			// Arguments of calls of functions defined in the file
+			ident, ok := expr.X.(*build.Ident)
+			if !ok {
+				return
+			}
+			def, ok := defs[ident.Name]
+			if !ok {
+				return
+			}
+			args := callArguments(def, expr)
+			for _, param := range def.Params {
+				arg, ok := args[param]
+				if !ok {
+					continue
+				}
+				annotation := paramAnnotation(param)
+				if annotated, detected, ok := typeMismatch(annotation, arg, types); ok {
+					name, _ := build.GetParamName(param)
+					findings = append(findings, makeLinterFinding(arg,
+						fmt.Sprintf("Parameter %q of %q is annotated as %s but the argument is a %s.", name, def.Name, annotated, detected)))
+				}
+			}
+		}
+	})
+	return findings
+}
+
+// isParam reports whether an expression is a parameter of a function definition.
+func isParam(node, expr build.Expr) bool {
+	def, ok := node.(*build.DefStmt)
+	if !ok {
+		return false
+	}
+	for _, param := range def.Params {
+		if param == expr {
+			return true
+		}
+	}
+	return false
+}
+
+// callArguments matches the arguments of a function call with the parameters of the function.
+// Returns a map from the parameters to the values of the arguments, arguments that can't be
+// matched (e.g. `*args`) are ignored.
+func callArguments(def *build.DefStmt, call *build.CallExpr) map[build.Expr]build.Expr {
+	params := make(map[string]build.Expr)
+	var positional []build.Expr
+	keywordOnly := false
+	for _, param := range def.Params {
+		name, op := build.GetParamName(param)
+		if op != "" || name == "" {
+			// `*`, `*args` or `**kwargs`, the following parameters are keyword-only
+			keywordOnly = true
+			continue
+		}
+		params[name] = param
+		if !keywordOnly {
+			positional = append(positional, param)
+		}
+	}
+
+	result := make(map[build.Expr]build.Expr)
+	for i, arg := range call.List {
+		switch arg := arg.(type) {
+		case *build.AssignExpr:
+			if ident, ok := arg.LHS.(*build.Ident); ok {
+				if param, ok := params[ident.Name]; ok {
+					result[param] = arg.RHS
+				}
+			}
+			continue
+		case *build.UnaryExpr:
+			// `*args` or `**kwargs`, the rest of the positional arguments can't be matched
+			positional = nil
+			continue
+		}
+		if i < len(positional) {
+			result[positional[i]] = arg
+		}
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warn
+
+import "testing"
+
+func TestTypeAnnotationMismatch(t *testing.T) {
+	checkFindings(t, "type-annotation-mismatch", `
+a: list[str] = depset()
+b: float = 1
+c: dict | None = []
+d: str = None
+e: list = foo()
+
+def f(x: list, y: int = "1", z: str = None, *args: int, w: Foo = [], **kwargs: dict) -> list[str]:
+    if x:
+        return depset(x)
+    return []
+
+f(depset(), 2)
+f([], y = {})
+f([], 1, "", 2, 3, w = {})
+f(*foo, 1)
+f(x = "")
+g(x = "")
+
+def g() -> depset:
+    return {}
+`,
+		[]string{
+			`:1: Variable "a" is annotated as list[str] but is assigned a depset.`,
+			`:7: Parameter "y" is annotated as int but its default value is a string.`,
+			`:9: Function "f" is annotated to return list[str] but returns a depset.`,
+			`:12: Parameter "x" of "f" is annotated as list but the argument is a depset.`,
+			`:13: Parameter "y" of "f" is annotated as int but the argument is a dict.`,
+			`:16: Parameter "x" of "f" is annotated as list but the argument is a string.`,
+			`:20: Function "g" is annotated to return depset but returns a dict.`,
+		},
+		scopeEverywhere)
+}
+
+func TestTypeAnnotationMismatchInferredTypes(t *testing.T) {
+	checkFindings(t, "type-annotation-mismatch", `
+def f(x: list) -> str:
+    return x
+
+def g(y: depset):
+    s = "foo"
+    f(y)
+    f(s)
+    n: int = f([])
+`,
+		[]string{
+			`:2: Function "f" is annotated to return str but returns a list.`,
+			`:6: Parameter "x" of "f" is annotated as list but the argument is a depset.`,
+			`:7: Parameter "x" of "f" is annotated as list but the argument is a string.`,
+			`:8: Variable "n" is annotated as int but is assigned a string.`,
+		},
+		scopeEverywhere)
+}
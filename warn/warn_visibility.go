@@ -14,12 +14,13 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Warnings about visibility of .bzl files
+// Warnings about visibility of .bzl files and targets
 
 package warn
 
 import (
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -110,3 +111,325 @@ func bzlVisibilityWarning(f *build.File, fileReader *FileReader) []*LinterFindin
 
 	return findings
 }
+
+// Warnings about visibility of targets
+
+// labelListAttrs are the attributes whose labels are checked by the
+// target-visibility and dangling-label warnings.
+var labelListAttrs = map[string]bool{
+	"data":                true,
+	"deps":                true,
+	"exported_plugins":    true,
+	"exports":             true,
+	"hdrs":                true,
+	"implementation_deps": true,
+	"plugins":             true,
+	"resources":           true,
+	"runtime_deps":        true,
+	"srcs":                true,
+	"textual_hdrs":        true,
+	"tools":               true,
+}
+
+// visibility is the result of a visibility check
+type visibility int
+
+const (
+	visibilityUnknown visibility = iota
+	visibilityAllowed
+	visibilityDenied
+)
+
+// targetVisibilityChecker resolves labels to their targets and checks whether
+// the targets are visible from a package.
+type targetVisibilityChecker struct {
+	workspaceRoot string
+	fileReader    *FileReader
+}
+
+// buildFile returns the BUILD file of a package in the main repository.
+func (c *targetVisibilityChecker) buildFile(pkg string) *build.File {
+	return readBuildFile(c.fileReader, pkg)
+}
+
+// readBuildFile returns the BUILD file of a package in the main repository or
+// nil if it doesn't exist or can't be parsed.
+func readBuildFile(fileReader *FileReader, pkg string) *build.File {
+	for _, name := range buildFileNames {
+		if f := fileReader.GetFile(pkg, name); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// buildFileNames are the names of BUILD files in the order of preference.
+var buildFileNames = []string{"BUILD.bazel", "BUILD"}
+
+// repoBuildFile returns the BUILD file of an external repository defined in the
+// WORKSPACE file with a build_file attribute.
+func (c *targetVisibilityChecker) repoBuildFile(repo string) *build.File {
+	path, ok := c.fileReader.repoBuildFiles(c.workspaceRoot)[repo]
+	if !ok {
+		return nil
+	}
+	rel, err := filepath.Rel(c.workspaceRoot, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	dir, name := filepath.Split(filepath.ToSlash(rel))
+	return c.fileReader.GetFile(strings.TrimSuffix(dir, "/"), name)
+}
+
+// findRule returns a rule with the given name declared on the top level of a file.
+func findRule(f *build.File, name string) *build.Rule {
+	for _, r := range f.Rules("") {
+		if r.Name() == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// packageDefaultVisibility returns the default_visibility of a package or nil if it's not set.
+func packageDefaultVisibility(f *build.File) build.Expr {
+	for _, r := range f.Rules("package") {
+		if v := r.Attr("default_visibility"); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// visibleFrom checks whether a list of visibility labels of a target in the
+// package targetPkg makes it visible from the package pkg. depth limits the
+// recursion when package groups are resolved.
+func (c *targetVisibilityChecker) visibleFrom(visibilityLabels build.Expr, targetPkg, pkg string, external bool, depth int) visibility {
+	list, ok := visibilityLabels.(*build.ListExpr)
+	if !ok || depth > 10 {
+		return visibilityUnknown
+	}
+	result := visibilityDenied
+	for _, item := range list.List {
+		str, ok := item.(*build.StringExpr)
+		if !ok {
+			return visibilityUnknown
+		}
+		label := labels.ParseRelative(str.Value, targetPkg)
+		if label.Repository != "" {
+			result = visibilityUnknown
+			continue
+		}
+		switch {
+		case label.Package == "visibility" && label.Target == "public":
+			return visibilityAllowed
+		case label.Package == "visibility" && label.Target == "private":
+			// Doesn't grant any additional visibility
+		case external:
+			// Visibility is granted to packages in the external repository
+			// or by package groups which aren't resolved.
+			if label.Target != "__pkg__" && label.Target != "__subpackages__" {
+				result = visibilityUnknown
+			}
+		case label.Target == "__pkg__":
+			if pkg == label.Package {
+				return visibilityAllowed
+			}
+		case label.Target == "__subpackages__":
+			if isSubpackage(pkg, label.Package) {
+				return visibilityAllowed
+			}
+		default:
+			switch c.packageGroupContains(label, pkg, depth+1) {
+			case visibilityAllowed:
+				return visibilityAllowed
+			case visibilityUnknown:
+				result = visibilityUnknown
+			}
+		}
+	}
+	return result
+}
+
+// isSubpackage checks whether pkg is the package parent or one of its subpackages.
+func isSubpackage(pkg, parent string) bool {
+	return parent == "" || pkg == parent || strings.HasPrefix(pkg, parent+"/")
+}
+
+// packageGroupContains checks whether a package group contains the package pkg.
+func (c *targetVisibilityChecker) packageGroupContains(group labels.Label, pkg string, depth int) visibility {
+	f := c.buildFile(group.Package)
+	if f == nil || depth > 10 {
+		return visibilityUnknown
+	}
+	rule := findRule(f, group.Target)
+	if rule == nil || rule.Kind() != "package_group" {
+		return visibilityUnknown
+	}
+
+	result := visibilityDenied
+	if packages := rule.Attr("packages"); packages != nil {
+		list, ok := packages.(*build.ListExpr)
+		if !ok {
+			return visibilityUnknown
+		}
+		for _, item := range list.List {
+			str, ok := item.(*build.StringExpr)
+			if !ok {
+				return visibilityUnknown
+			}
+			spec := str.Value
+			negative := strings.HasPrefix(spec, "-")
+			spec = strings.TrimPrefix(spec, "-")
+			var matches bool
+			switch {
+			case spec == "public":
+				matches = true
+			case spec == "private":
+				matches = false
+			case strings.HasPrefix(spec, "//"):
+				spec = strings.TrimPrefix(spec, "//")
+				if strings.HasSuffix(spec, "...") {
+					matches = isSubpackage(pkg, strings.TrimSuffix(strings.TrimSuffix(spec, "..."), "/"))
+				} else {
+					matches = pkg == spec
+				}
+			default:
+				// Specs with repository names
+				return visibilityUnknown
+			}
+			if matches {
+				if negative {
+					// Negative specs take precedence
+					return visibilityDenied
+				}
+				result = visibilityAllowed
+			}
+		}
+	}
+	if result == visibilityAllowed {
+		return result
+	}
+
+	if includes := rule.Attr("includes"); includes != nil {
+		list, ok := includes.(*build.ListExpr)
+		if !ok {
+			return visibilityUnknown
+		}
+		for _, item := range list.List {
+			str, ok := item.(*build.StringExpr)
+			if !ok {
+				return visibilityUnknown
+			}
+			label := labels.ParseRelative(str.Value, group.Package)
+			if label.Repository != "" {
+				result = visibilityUnknown
+				continue
+			}
+			switch c.packageGroupContains(label, pkg, depth+1) {
+			case visibilityAllowed:
+				return visibilityAllowed
+			case visibilityUnknown:
+				result = visibilityUnknown
+			}
+		}
+	}
+	return result
+}
+
+// labelStrings returns the string literals of an attribute value that are
+// labels: list items, including the values of select() expressions.
+func labelStrings(expr build.Expr) []*build.StringExpr {
+	switch expr := expr.(type) {
+	case *build.StringExpr:
+		return []*build.StringExpr{expr}
+	case *build.ListExpr:
+		var result []*build.StringExpr
+		for _, item := range expr.List {
+			result = append(result, labelStrings(item)...)
+		}
+		return result
+	case *build.BinaryExpr:
+		if expr.Op == "+" {
+			return append(labelStrings(expr.X), labelStrings(expr.Y)...)
+		}
+	case *build.CallExpr:
+		if ident, ok := expr.X.(*build.Ident); ok && ident.Name == "select" && len(expr.List) > 0 {
+			if dict, ok := expr.List[0].(*build.DictExpr); ok {
+				var result []*build.StringExpr
+				for _, kv := range dict.List {
+					result = append(result, labelStrings(kv.Value)...)
+				}
+				return result
+			}
+		}
+	}
+	return nil
+}
+
+func targetVisibilityWarning(f *build.File, fileReader *FileReader) []*LinterFinding {
+	var findings []*LinterFinding
+	if f.Type != build.TypeBuild || f.WorkspaceRoot == "" || fileReader == nil {
+		// The location of the file relative to the workspace root is needed
+		// to resolve the labels.
+		return findings
+	}
+	c := &targetVisibilityChecker{workspaceRoot: f.WorkspaceRoot, fileReader: fileReader}
+
+	for _, r := range f.Rules("") {
+		for _, attr := range r.Call.List {
+			as, ok := attr.(*build.AssignExpr)
+			if !ok {
+				continue
+			}
+			key, ok := as.LHS.(*build.Ident)
+			if !ok || !labelListAttrs[key.Name] {
+				continue
+			}
+			for _, str := range labelStrings(as.RHS) {
+				if !strings.HasPrefix(str.Value, "//") && !strings.HasPrefix(str.Value, "@") {
+					// Same package
+					continue
+				}
+				label := labels.ParseRelative(str.Value, f.Pkg)
+				external := label.Repository != ""
+				if !external && label.Package == f.Pkg {
+					continue
+				}
+
+				var targetFile *build.File
+				if external {
+					if label.Package != "" {
+						continue
+					}
+					targetFile = c.repoBuildFile(label.Repository)
+				} else {
+					targetFile = c.buildFile(label.Package)
+				}
+				if targetFile == nil {
+					continue
+				}
+				target := findRule(targetFile, label.Target)
+				if target == nil {
+					// Not a rule, e.g. a source file or a target created by a macro
+					continue
+				}
+
+				visibilityLabels := target.Attr("visibility")
+				if visibilityLabels == nil {
+					visibilityLabels = packageDefaultVisibility(targetFile)
+				}
+				if visibilityLabels == nil {
+					// Private by default
+					visibilityLabels = &build.ListExpr{}
+				}
+				if c.visibleFrom(visibilityLabels, label.Package, f.Pkg, external, 0) != visibilityDenied {
+					continue
+				}
+				findings = append(findings, makeLinterFinding(str,
+					fmt.Sprintf("Target %q is not visible from package %q.", str.Value, "//"+f.Pkg)))
+			}
+		}
+	}
+	return findings
+}
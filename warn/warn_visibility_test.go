@@ -16,7 +16,13 @@ limitations under the License.
 
 package warn
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bazelbuild/buildtools/build"
+)
 
 func TestBzlVisibility(t *testing.T) {
 	checkFindings(t, "bzl-visibility", `
@@ -145,3 +151,157 @@ load("//foo/private:bar.bzl", "abc")
 		},
 		scopeEverywhere)
 }
+
+func TestTargetVisibility(t *testing.T) {
+	defer setUpFileReader(map[string]string{
+		"private/BUILD": `
+cc_library(name = "lib")
+`,
+		"public/BUILD.bazel": `
+cc_library(
+    name = "lib",
+    visibility = ["//visibility:public"],
+)
+`,
+		"default/BUILD": `
+package(default_visibility = ["//test:__subpackages__"])
+
+cc_library(name = "lib")
+
+cc_library(
+    name = "other",
+    visibility = ["//other:__pkg__"],
+)
+`,
+		"groups/BUILD": `
+package_group(
+    name = "friends",
+    packages = ["//test/package/..."],
+)
+
+package_group(
+    name = "enemies",
+    packages = [
+        "//test/...",
+        "-//test/package",
+    ],
+)
+
+package_group(
+    name = "included",
+    includes = [":friends"],
+)
+
+cc_library(
+    name = "friends_lib",
+    visibility = [":friends"],
+)
+
+cc_library(
+    name = "enemies_lib",
+    visibility = [":enemies"],
+)
+
+cc_library(
+    name = "included_lib",
+    visibility = [":included"],
+)
+
+cc_library(
+    name = "unknown_lib",
+    visibility = ["//unknown:group"],
+)
+`,
+	})()
+
+	checkFindings(t, "target-visibility", `
+cc_library(
+    name = "a",
+    deps = [
+        ":local",
+        "//private:lib",
+        "//public:lib",
+        "//default:lib",
+        "//default:other",
+        "//groups:friends_lib",
+        "//groups:enemies_lib",
+        "//groups:included_lib",
+        "//groups:unknown_lib",
+        "//missing:lib",
+        "//private:file.txt",
+    ] + select({
+        "//private:lib": ["//private:lib"],
+        "//conditions:default": [],
+    }),
+    visibility = ["//private:lib"],
+)
+
+cc_test(
+    name = "b",
+    data = ["//test/package:lib"],
+)
+`,
+		[]string{
+			`:5: Target "//private:lib" is not visible from package "//test/package".`,
+			`:8: Target "//default:other" is not visible from package "//test/package".`,
+			`:10: Target "//groups:enemies_lib" is not visible from package "//test/package".`,
+			`:16: Target "//private:lib" is not visible from package "//test/package".`,
+		},
+		scopeBuild)
+}
+
+func TestTargetVisibilityExternalRepository(t *testing.T) {
+	root := t.TempDir()
+	workspace := `
+new_local_repository(
+    name = "ext",
+    build_file = "//third_party:ext.BUILD",
+    path = "/tmp/ext",
+)
+`
+	if err := os.WriteFile(filepath.Join(root, "WORKSPACE"), []byte(workspace), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer setUpFileReader(map[string]string{
+		"third_party/ext.BUILD": `
+cc_library(name = "private")
+
+cc_library(
+    name = "public",
+    visibility = ["//visibility:public"],
+)
+`,
+	})()
+
+	f := getFileForTest(`
+cc_library(
+    name = "a",
+    deps = [
+        "@ext//:private",
+        "@ext//:public",
+        "@other//:lib",
+    ],
+)
+`, build.TypeBuild)
+	f.WorkspaceRoot = root
+
+	findings := FileWarnings(f, []string{"target-visibility"}, nil, ModeWarn, testFileReader)
+	if len(findings) != 1 || findings[0].Start.Line != 4 || findings[0].Message != `Target "@ext//:private" is not visible from package "//test/package".` {
+		for _, f := range findings {
+			t.Errorf("got: %d: %s", f.Start.Line, f.Message)
+		}
+		t.Errorf("want: 4: Target \"@ext//:private\" is not visible from package \"//test/package\".")
+	}
+	// The BUILD files of the external repositories are cached by the file reader,
+	// a new reader sees the changes of the WORKSPACE file.
+	if err := os.WriteFile(filepath.Join(root, "WORKSPACE"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if findings := FileWarnings(f, []string{"target-visibility"}, nil, ModeWarn, testFileReader); len(findings) != 1 {
+		t.Errorf("got %d findings with the same file reader, want 1", len(findings))
+	}
+	setUpFileReader(nil)
+	if findings := FileWarnings(f, []string{"target-visibility"}, nil, ModeWarn, testFileReader); len(findings) != 0 {
+		t.Errorf("got %d findings with a new file reader, want 0", len(findings))
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The workspace_to_module binary converts a WORKSPACE file to a draft MODULE.bazel file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/bazelbuild/buildtools/edit/bzlmod"
+	"github.com/bazelbuild/buildtools/tables"
+)
+
+var (
+	buildVersion     = "redacted"
+	buildScmRevision = "redacted"
+
+	version       = flag.Bool("version", false, "Print the version of workspace_to_module")
+	output        = flag.String("output", "", "path to write the MODULE.bazel file to, the default is stdout")
+	tablesPath    = flag.String("tables", "", "path to JSON file with custom table definitions which will replace the built-in tables")
+	addTablesPath = flag.String("add_tables", "", "path to JSON file with custom table definitions which will be merged with the built-in tables")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `usage: workspace_to_module [flags] [WORKSPACE]
+
+Converts a WORKSPACE file (./WORKSPACE by default) to a draft MODULE.bazel
+file. The statements that couldn't be converted are reported on stderr.
+
+`)
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *version {
+		fmt.Printf("workspace_to_module version: %s \n", buildVersion)
+		fmt.Printf("workspace_to_module scm revision: %s \n", buildScmRevision)
+		os.Exit(0)
+	}
+
+	if *tablesPath != "" {
+		if err := tables.ParseAndUpdateJSONDefinitions(*tablesPath, false); err != nil {
+			fmt.Fprintf(os.Stderr, "workspace_to_module: failed to parse %s for -tables: %s\n", *tablesPath, err)
+			os.Exit(2)
+		}
+	}
+
+	if *addTablesPath != "" {
+		if err := tables.ParseAndUpdateJSONDefinitions(*addTablesPath, true); err != nil {
+			fmt.Fprintf(os.Stderr, "workspace_to_module: failed to parse %s for -add_tables: %s\n", *addTablesPath, err)
+			os.Exit(2)
+		}
+	}
+
+	if flag.NArg() > 1 {
+		usage()
+		os.Exit(2)
+	}
+	workspacePath := "WORKSPACE"
+	if flag.NArg() == 1 {
+		workspacePath = flag.Arg(0)
+	}
+
+	data, err := os.ReadFile(workspacePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "workspace_to_module: %v\n", err)
+		os.Exit(2)
+	}
+	f, err := build.ParseWorkspace(workspacePath, data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "workspace_to_module: %v\n", err)
+		os.Exit(1)
+	}
+
+	module, notes := bzlmod.MigrateWorkspace(f)
+	for _, note := range notes {
+		fmt.Fprintf(os.Stderr, "%s:%d: %s\n", workspacePath, note.Line, note.Message)
+	}
+
+	content := build.Format(module)
+	if *output == "" {
+		os.Stdout.Write(content)
+		return
+	}
+	if err := os.WriteFile(*output, content, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "workspace_to_module: %v\n", err)
+		os.Exit(2)
+	}
+}
@@ -149,6 +149,48 @@ func FindRepoBuildFiles(root string) (map[string]string, error) {
 	return files, nil
 }
 
+// FindStarlarkFiles returns the paths of the BUILD and .bzl files of the
+// repository rooted at root, relative to root and with forward slashes.
+// Hidden directories and nested repositories are skipped.
+func FindStarlarkFiles(root string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if path == root {
+				return nil
+			}
+			if strings.HasPrefix(fi.Name(), ".") {
+				return filepath.SkipDir
+			}
+			for name, fiFunc := range repoRootFiles {
+				if fi, err := os.Stat(filepath.Join(path, name)); err == nil && fiFunc(fi) {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !isFile(fi) {
+			return nil
+		}
+		if _, ok := packageRootFiles[fi.Name()]; !ok && !strings.HasSuffix(fi.Name(), ".bzl") {
+			return nil
+		}
+		rel, err := relPath(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
 // relPath returns a path for `target` relative to `base`, but an empty string
 // instead of "." if the directories are equivalent, and with forward slashes.
 func relPath(base, target string) (string, error) {
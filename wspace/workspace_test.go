@@ -114,6 +114,39 @@ new_http_archive(
 	}
 }
 
+func TestFindStarlarkFiles(t *testing.T) {
+	tmp := t.TempDir()
+	for _, name := range []string{
+		"MODULE.bazel",
+		"BUILD.bazel",
+		"defs.bzl",
+		"a/BUILD",
+		"a/b/BUILD.bazel",
+		"a/b/rules.bzl",
+		"a/b/data.txt",
+		"c/README.md",
+		".git/BUILD",
+		"nested/MODULE.bazel",
+		"nested/BUILD",
+	} {
+		path := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := FindStarlarkFiles(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"BUILD.bazel", "a/BUILD", "a/b/BUILD.bazel", "a/b/rules.bzl", "defs.bzl"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("FindStarlarkFiles() = %q; want %q", files, expected)
+	}
+}
+
 func checkSplitFilePathOutput(t *testing.T, name, filename, expectedWorkspaceRoot, expectedPkg, expectedLabel string) {
 	workspaceRoot, pkg, label := SplitFilePath(filename)
 	if workspaceRoot != expectedWorkspaceRoot {